@@ -0,0 +1,89 @@
+package config
+
+// Config contains the configuration of the APIs exposing the indexed data
+type Config struct {
+	GraphQL *GraphQLConfig `yaml:"graphql,omitempty"`
+	REST    *RESTConfig    `yaml:"rest,omitempty"`
+
+	// Stream is served by the start command alongside the workers, and is disabled when not set
+	Stream *StreamConfig `yaml:"stream,omitempty"`
+}
+
+// NewAPIConfig allows to build a new Config instance
+func NewAPIConfig(graphQL *GraphQLConfig, rest *RESTConfig, stream *StreamConfig) Config {
+	return Config{
+		GraphQL: graphQL,
+		REST:    rest,
+		Stream:  stream,
+	}
+}
+
+// DefaultAPIConfig returns the default instance of Config
+func DefaultAPIConfig() Config {
+	return NewAPIConfig(DefaultGraphQLConfig(), DefaultRESTConfig(), nil)
+}
+
+// DefaultGraphQLMaxDepth is the maximum depth of the GraphQL queries used when it is not configured
+const DefaultGraphQLMaxDepth = 12
+
+// GraphQLConfig contains the configuration of the GraphQL server
+type GraphQLConfig struct {
+	Address     string `yaml:"address"`
+	MaxPageSize int    `yaml:"max_page_size"`
+
+	// MaxDepth is the maximum number of nested fields of a query, so that a single request
+	// cannot walk the nested relations without bounds
+	MaxDepth int `yaml:"max_depth"`
+}
+
+// NewGraphQLConfig allows to build a new GraphQLConfig instance
+func NewGraphQLConfig(address string, maxPageSize int, maxDepth int) *GraphQLConfig {
+	return &GraphQLConfig{
+		Address:     address,
+		MaxPageSize: maxPageSize,
+		MaxDepth:    maxDepth,
+	}
+}
+
+// DefaultGraphQLConfig returns the default instance of GraphQLConfig
+func DefaultGraphQLConfig() *GraphQLConfig {
+	return NewGraphQLConfig("127.0.0.1:8080", 100, DefaultGraphQLMaxDepth)
+}
+
+// RESTConfig contains the configuration of the REST server
+type RESTConfig struct {
+	Address     string `yaml:"address"`
+	MaxPageSize int    `yaml:"max_page_size"`
+}
+
+// NewRESTConfig allows to build a new RESTConfig instance
+func NewRESTConfig(address string, maxPageSize int) *RESTConfig {
+	return &RESTConfig{
+		Address:     address,
+		MaxPageSize: maxPageSize,
+	}
+}
+
+// DefaultRESTConfig returns the default instance of RESTConfig
+func DefaultRESTConfig() *RESTConfig {
+	return NewRESTConfig("127.0.0.1:8081", 100)
+}
+
+// StreamConfig contains the configuration of the real-time event stream server
+type StreamConfig struct {
+	Address    string `yaml:"address"`
+	BufferSize int    `yaml:"buffer_size"`
+}
+
+// NewStreamConfig allows to build a new StreamConfig instance
+func NewStreamConfig(address string, bufferSize int) *StreamConfig {
+	return &StreamConfig{
+		Address:    address,
+		BufferSize: bufferSize,
+	}
+}
+
+// DefaultStreamConfig returns the default instance of StreamConfig
+func DefaultStreamConfig() *StreamConfig {
+	return NewStreamConfig("127.0.0.1:8082", 256)
+}
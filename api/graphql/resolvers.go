@@ -0,0 +1,839 @@
+package graphql
+
+import (
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/nuclearblock/archgregator/database"
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+)
+
+// Resolver represents the root GraphQL resolver
+type Resolver struct {
+	db          database.Reader
+	maxPageSize int
+}
+
+// NewResolver allows to build a new Resolver instance
+func NewResolver(db database.Reader, maxPageSize int) *Resolver {
+	return &Resolver{
+		db:          db,
+		maxPageSize: maxPageSize,
+	}
+}
+
+// forChain returns a Resolver that only reads the data of the chain having the given id,
+// or this Resolver if the id is empty
+func (r *Resolver) forChain(chainID string) *Resolver {
+	if chainID == "" {
+		return r
+	}
+	return &Resolver{
+		db:          database.ReaderForChain(r.db, chainID),
+		maxPageSize: r.maxPageSize,
+	}
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+
+type blocksFilterInput struct {
+	FromHeight *Int64
+	ToHeight   *Int64
+}
+
+type codesFilterInput struct {
+	FromHeight *Int64
+	ToHeight   *Int64
+	Creator    *string
+}
+
+type contractsFilterInput struct {
+	FromHeight *Int64
+	ToHeight   *Int64
+	CodeID     *Int64
+	Creator    *string
+	Admin      *string
+	Label      *string
+}
+
+type executionsFilterInput struct {
+	FromHeight      *Int64
+	ToHeight        *Int64
+	ContractAddress *string
+	Sender          *string
+	TxHash          *string
+}
+
+type metadataFilterInput struct {
+	FromHeight       *Int64
+	ToHeight         *Int64
+	ContractAddress  *string
+	RewardAddress    *string
+	DeveloperAddress *string
+}
+
+type rewardsFilterInput struct {
+	FromHeight       *Int64
+	ToHeight         *Int64
+	ContractAddress  *string
+	RewardAddress    *string
+	DeveloperAddress *string
+}
+
+func (f *blocksFilterInput) toFilter() dbtypes.BlocksFilter {
+	if f == nil {
+		return dbtypes.BlocksFilter{}
+	}
+	return dbtypes.BlocksFilter{HeightRange: heightRange(f.FromHeight, f.ToHeight)}
+}
+
+func (f *codesFilterInput) toFilter() dbtypes.WasmCodesFilter {
+	if f == nil {
+		return dbtypes.WasmCodesFilter{}
+	}
+	return dbtypes.WasmCodesFilter{
+		HeightRange: heightRange(f.FromHeight, f.ToHeight),
+		Creator:     stringValue(f.Creator),
+	}
+}
+
+func (f *contractsFilterInput) toFilter() dbtypes.WasmContractsFilter {
+	if f == nil {
+		return dbtypes.WasmContractsFilter{}
+	}
+	return dbtypes.WasmContractsFilter{
+		HeightRange: heightRange(f.FromHeight, f.ToHeight),
+		CodeID:      int64Value(f.CodeID),
+		Creator:     stringValue(f.Creator),
+		Admin:       stringValue(f.Admin),
+		Label:       stringValue(f.Label),
+	}
+}
+
+func (f *executionsFilterInput) toFilter() dbtypes.WasmExecuteContractsFilter {
+	if f == nil {
+		return dbtypes.WasmExecuteContractsFilter{}
+	}
+	return dbtypes.WasmExecuteContractsFilter{
+		HeightRange:     heightRange(f.FromHeight, f.ToHeight),
+		ContractAddress: stringValue(f.ContractAddress),
+		Sender:          stringValue(f.Sender),
+		TxHash:          stringValue(f.TxHash),
+	}
+}
+
+func (f *metadataFilterInput) toFilter() dbtypes.ContractMetadataFilter {
+	if f == nil {
+		return dbtypes.ContractMetadataFilter{}
+	}
+	return dbtypes.ContractMetadataFilter{
+		HeightRange:      heightRange(f.FromHeight, f.ToHeight),
+		ContractAddress:  stringValue(f.ContractAddress),
+		RewardAddress:    stringValue(f.RewardAddress),
+		DeveloperAddress: stringValue(f.DeveloperAddress),
+	}
+}
+
+func (f *rewardsFilterInput) toFilter() dbtypes.ContractRewardsFilter {
+	if f == nil {
+		return dbtypes.ContractRewardsFilter{}
+	}
+	return dbtypes.ContractRewardsFilter{
+		HeightRange:      heightRange(f.FromHeight, f.ToHeight),
+		ContractAddress:  stringValue(f.ContractAddress),
+		RewardAddress:    stringValue(f.RewardAddress),
+		DeveloperAddress: stringValue(f.DeveloperAddress),
+	}
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+
+type pageArgs struct {
+	First *int32
+	After *string
+}
+
+type blocksArgs struct {
+	ChainID *string
+	First   *int32
+	After   *string
+	Filter  *blocksFilterInput
+}
+
+type codesArgs struct {
+	ChainID *string
+	First   *int32
+	After   *string
+	Filter  *codesFilterInput
+}
+
+type contractsArgs struct {
+	ChainID *string
+	First   *int32
+	After   *string
+	Filter  *contractsFilterInput
+}
+
+type executionsArgs struct {
+	ChainID *string
+	First   *int32
+	After   *string
+	Filter  *executionsFilterInput
+}
+
+type metadataArgs struct {
+	ChainID *string
+	First   *int32
+	After   *string
+	Filter  *metadataFilterInput
+}
+
+type rewardsArgs struct {
+	ChainID *string
+	First   *int32
+	After   *string
+	Filter  *rewardsFilterInput
+}
+
+// Block resolves the Query.block field
+func (r *Resolver) Block(args struct {
+	ChainID *string
+	Height  Int64
+}) (*blockResolver, error) {
+	return r.forChain(stringValue(args.ChainID)).block(int64(args.Height))
+}
+
+// Blocks resolves the Query.blocks field
+func (r *Resolver) Blocks(args blocksArgs) (*blockConnectionResolver, error) {
+	return r.forChain(stringValue(args.ChainID)).blocks(args.First, args.After, args.Filter.toFilter())
+}
+
+// Code resolves the Query.code field
+func (r *Resolver) Code(args struct {
+	ChainID *string
+	CodeID  Int64
+}) (*codeResolver, error) {
+	return r.forChain(stringValue(args.ChainID)).code(int64(args.CodeID))
+}
+
+// Codes resolves the Query.codes field
+func (r *Resolver) Codes(args codesArgs) (*codeConnectionResolver, error) {
+	return r.forChain(stringValue(args.ChainID)).codes(args.First, args.After, args.Filter.toFilter())
+}
+
+// Contract resolves the Query.contract field
+func (r *Resolver) Contract(args struct {
+	ChainID *string
+	Address string
+}) (*contractResolver, error) {
+	return r.forChain(stringValue(args.ChainID)).contract(args.Address)
+}
+
+// Contracts resolves the Query.contracts field
+func (r *Resolver) Contracts(args contractsArgs) (*contractConnectionResolver, error) {
+	return r.forChain(stringValue(args.ChainID)).contracts(args.First, args.After, args.Filter.toFilter())
+}
+
+// Executions resolves the Query.executions field
+func (r *Resolver) Executions(args executionsArgs) (*executionConnectionResolver, error) {
+	return r.forChain(stringValue(args.ChainID)).executions(args.First, args.After, args.Filter.toFilter())
+}
+
+// Metadata resolves the Query.metadata field
+func (r *Resolver) Metadata(args metadataArgs) (*metadataConnectionResolver, error) {
+	return r.forChain(stringValue(args.ChainID)).metadata(args.First, args.After, args.Filter.toFilter())
+}
+
+// Rewards resolves the Query.rewards field
+func (r *Resolver) Rewards(args rewardsArgs) (*rewardConnectionResolver, error) {
+	return r.forChain(stringValue(args.ChainID)).rewards(args.First, args.After, args.Filter.toFilter())
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+
+func (r *Resolver) block(height int64) (*blockResolver, error) {
+	row, err := r.db.GetBlock(height)
+	if err != nil || row == nil {
+		return nil, err
+	}
+	return &blockResolver{root: r, row: *row}, nil
+}
+
+func (r *Resolver) blocks(first *int32, after *string, filter dbtypes.BlocksFilter) (*blockConnectionResolver, error) {
+	p, err := newPage(first, after, r.maxPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.GetBlocks(filter, p.pagination())
+	if err != nil {
+		return nil, err
+	}
+
+	info, count := p.pageInfo(len(rows), func(i int) dbtypes.Cursor { return rows[i].Cursor() })
+	nodes := make([]*blockResolver, count)
+	for i := range nodes {
+		nodes[i] = &blockResolver{root: r, row: rows[i]}
+	}
+
+	return &blockConnectionResolver{nodes: nodes, pageInfo: info}, nil
+}
+
+func (r *Resolver) code(codeID int64) (*codeResolver, error) {
+	row, err := r.db.GetWasmCode(codeID)
+	if err != nil || row == nil {
+		return nil, err
+	}
+	return &codeResolver{root: r, row: *row}, nil
+}
+
+func (r *Resolver) codes(first *int32, after *string, filter dbtypes.WasmCodesFilter) (*codeConnectionResolver, error) {
+	p, err := newPage(first, after, r.maxPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.GetWasmCodes(filter, p.pagination())
+	if err != nil {
+		return nil, err
+	}
+
+	info, count := p.pageInfo(len(rows), func(i int) dbtypes.Cursor { return rows[i].Cursor() })
+	nodes := make([]*codeResolver, count)
+	for i := range nodes {
+		nodes[i] = &codeResolver{root: r, row: rows[i]}
+	}
+
+	return &codeConnectionResolver{nodes: nodes, pageInfo: info}, nil
+}
+
+func (r *Resolver) contract(address string) (*contractResolver, error) {
+	row, err := r.db.GetWasmContract(address)
+	if err != nil || row == nil {
+		return nil, err
+	}
+	return &contractResolver{root: r, row: *row}, nil
+}
+
+func (r *Resolver) contracts(
+	first *int32, after *string, filter dbtypes.WasmContractsFilter,
+) (*contractConnectionResolver, error) {
+	p, err := newPage(first, after, r.maxPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.GetWasmContracts(filter, p.pagination())
+	if err != nil {
+		return nil, err
+	}
+
+	info, count := p.pageInfo(len(rows), func(i int) dbtypes.Cursor { return rows[i].Cursor() })
+	nodes := make([]*contractResolver, count)
+	for i := range nodes {
+		nodes[i] = &contractResolver{root: r, row: rows[i]}
+	}
+
+	return &contractConnectionResolver{nodes: nodes, pageInfo: info}, nil
+}
+
+func (r *Resolver) executions(
+	first *int32, after *string, filter dbtypes.WasmExecuteContractsFilter,
+) (*executionConnectionResolver, error) {
+	p, err := newPage(first, after, r.maxPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.GetWasmExecuteContracts(filter, p.pagination())
+	if err != nil {
+		return nil, err
+	}
+
+	info, count := p.pageInfo(len(rows), func(i int) dbtypes.Cursor { return rows[i].Cursor() })
+	nodes := make([]*executionResolver, count)
+	for i := range nodes {
+		nodes[i] = &executionResolver{root: r, row: rows[i]}
+	}
+
+	return &executionConnectionResolver{nodes: nodes, pageInfo: info}, nil
+}
+
+func (r *Resolver) metadata(
+	first *int32, after *string, filter dbtypes.ContractMetadataFilter,
+) (*metadataConnectionResolver, error) {
+	p, err := newPage(first, after, r.maxPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.GetContractMetadata(filter, p.pagination())
+	if err != nil {
+		return nil, err
+	}
+
+	info, count := p.pageInfo(len(rows), func(i int) dbtypes.Cursor { return rows[i].Cursor() })
+	nodes := make([]*metadataResolver, count)
+	for i := range nodes {
+		nodes[i] = &metadataResolver{root: r, row: rows[i]}
+	}
+
+	return &metadataConnectionResolver{nodes: nodes, pageInfo: info}, nil
+}
+
+func (r *Resolver) rewards(
+	first *int32, after *string, filter dbtypes.ContractRewardsFilter,
+) (*rewardConnectionResolver, error) {
+	p, err := newPage(first, after, r.maxPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.GetContractRewards(filter, p.pagination())
+	if err != nil {
+		return nil, err
+	}
+
+	info, count := p.pageInfo(len(rows), func(i int) dbtypes.Cursor { return rows[i].Cursor() })
+	nodes := make([]*rewardResolver, count)
+	for i := range nodes {
+		nodes[i] = &rewardResolver{root: r, row: rows[i]}
+	}
+
+	return &rewardConnectionResolver{nodes: nodes, pageInfo: info}, nil
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+
+// blockResolver resolves the Block GraphQL type
+type blockResolver struct {
+	root *Resolver
+	row  dbtypes.BlockRow
+}
+
+func (r *blockResolver) ChainID() string {
+	return r.row.ChainID
+}
+
+func (r *blockResolver) Height() Int64 {
+	return Int64(r.row.Height)
+}
+
+func (r *blockResolver) Hash() string {
+	return r.row.Hash
+}
+
+func (r *blockResolver) NumTxs() int32 {
+	return int32(r.row.TxNum)
+}
+
+func (r *blockResolver) TotalGas() Int64 {
+	return Int64(r.row.TotalGas)
+}
+
+func (r *blockResolver) Timestamp() graphql.Time {
+	return graphql.Time{Time: r.row.Timestamp}
+}
+
+func (r *blockResolver) Executions(args pageArgs) (*executionConnectionResolver, error) {
+	return r.root.forChain(r.row.ChainID).executions(args.First, args.After, dbtypes.WasmExecuteContractsFilter{
+		HeightRange: dbtypes.HeightRange{FromHeight: r.row.Height, ToHeight: r.row.Height},
+	})
+}
+
+func (r *blockResolver) Rewards(args pageArgs) (*rewardConnectionResolver, error) {
+	return r.root.forChain(r.row.ChainID).rewards(args.First, args.After, dbtypes.ContractRewardsFilter{
+		HeightRange: dbtypes.HeightRange{FromHeight: r.row.Height, ToHeight: r.row.Height},
+	})
+}
+
+// blockConnectionResolver resolves the BlockConnection GraphQL type
+type blockConnectionResolver struct {
+	nodes    []*blockResolver
+	pageInfo *pageInfoResolver
+}
+
+func (r *blockConnectionResolver) Nodes() []*blockResolver {
+	return r.nodes
+}
+
+func (r *blockConnectionResolver) PageInfo() *pageInfoResolver {
+	return r.pageInfo
+}
+
+// codeResolver resolves the Code GraphQL type
+type codeResolver struct {
+	root *Resolver
+	row  dbtypes.WasmCodeRow
+}
+
+func (r *codeResolver) ChainID() string {
+	return r.row.ChainID
+}
+
+func (r *codeResolver) CodeID() Int64 {
+	return Int64(r.row.CodeID)
+}
+
+func (r *codeResolver) Creator() string {
+	return r.row.Creator
+}
+
+func (r *codeResolver) CodeHash() string {
+	return r.row.CodeHash
+}
+
+func (r *codeResolver) Size() int32 {
+	return int32(r.row.Size)
+}
+
+func (r *codeResolver) TxHash() string {
+	return r.row.TxHash
+}
+
+func (r *codeResolver) SavedAt() graphql.Time {
+	return graphql.Time{Time: r.row.SavedAt}
+}
+
+func (r *codeResolver) Height() Int64 {
+	return Int64(r.row.Height)
+}
+
+func (r *codeResolver) Contracts(args pageArgs) (*contractConnectionResolver, error) {
+	return r.root.forChain(r.row.ChainID).contracts(args.First, args.After, dbtypes.WasmContractsFilter{CodeID: r.row.CodeID})
+}
+
+// codeConnectionResolver resolves the CodeConnection GraphQL type
+type codeConnectionResolver struct {
+	nodes    []*codeResolver
+	pageInfo *pageInfoResolver
+}
+
+func (r *codeConnectionResolver) Nodes() []*codeResolver {
+	return r.nodes
+}
+
+func (r *codeConnectionResolver) PageInfo() *pageInfoResolver {
+	return r.pageInfo
+}
+
+// contractResolver resolves the Contract GraphQL type
+type contractResolver struct {
+	root *Resolver
+	row  dbtypes.WasmContractRow
+}
+
+func (r *contractResolver) ChainID() string {
+	return r.row.ChainID
+}
+
+func (r *contractResolver) Address() string {
+	return r.row.ContractAddress
+}
+
+func (r *contractResolver) Sender() string {
+	return r.row.Sender
+}
+
+func (r *contractResolver) Creator() string {
+	return r.row.Creator
+}
+
+func (r *contractResolver) Admin() string {
+	return r.row.Admin
+}
+
+func (r *contractResolver) CodeID() Int64 {
+	return Int64(r.row.CodeID)
+}
+
+func (r *contractResolver) Label() string {
+	return r.row.Label
+}
+
+func (r *contractResolver) RawContractMessage() string {
+	return string(r.row.RawContractMessage)
+}
+
+func (r *contractResolver) Funds() []*coinResolver {
+	return newCoinResolvers(r.row.Funds)
+}
+
+func (r *contractResolver) TxHash() string {
+	return r.row.TxHash
+}
+
+func (r *contractResolver) InstantiatedAt() graphql.Time {
+	return graphql.Time{Time: r.row.InstantiatedAt}
+}
+
+func (r *contractResolver) Height() Int64 {
+	return Int64(r.row.Height)
+}
+
+func (r *contractResolver) Code() (*codeResolver, error) {
+	return r.root.forChain(r.row.ChainID).code(r.row.CodeID)
+}
+
+func (r *contractResolver) Executions(args executionsArgs) (*executionConnectionResolver, error) {
+	filter := args.Filter.toFilter()
+	filter.ContractAddress = r.row.ContractAddress
+	return r.root.forChain(r.row.ChainID).executions(args.First, args.After, filter)
+}
+
+func (r *contractResolver) Metadata(args pageArgs) (*metadataConnectionResolver, error) {
+	return r.root.forChain(r.row.ChainID).metadata(args.First, args.After, dbtypes.ContractMetadataFilter{
+		ContractAddress: r.row.ContractAddress,
+	})
+}
+
+func (r *contractResolver) Rewards(args rewardsArgs) (*rewardConnectionResolver, error) {
+	filter := args.Filter.toFilter()
+	filter.ContractAddress = r.row.ContractAddress
+	return r.root.forChain(r.row.ChainID).rewards(args.First, args.After, filter)
+}
+
+// contractConnectionResolver resolves the ContractConnection GraphQL type
+type contractConnectionResolver struct {
+	nodes    []*contractResolver
+	pageInfo *pageInfoResolver
+}
+
+func (r *contractConnectionResolver) Nodes() []*contractResolver {
+	return r.nodes
+}
+
+func (r *contractConnectionResolver) PageInfo() *pageInfoResolver {
+	return r.pageInfo
+}
+
+// executionResolver resolves the Execution GraphQL type
+type executionResolver struct {
+	root *Resolver
+	row  dbtypes.WasmExecuteContractRow
+}
+
+func (r *executionResolver) ChainID() string {
+	return r.row.ChainID
+}
+
+func (r *executionResolver) Sender() string {
+	return r.row.Sender
+}
+
+func (r *executionResolver) ContractAddress() string {
+	return r.row.ContractAddress
+}
+
+func (r *executionResolver) RawContractMessage() string {
+	return string(r.row.RawContractMessage)
+}
+
+func (r *executionResolver) Funds() []*coinResolver {
+	return newCoinResolvers(r.row.Funds)
+}
+
+func (r *executionResolver) GasUsed() Int64 {
+	return Int64(r.row.GasUsed)
+}
+
+func (r *executionResolver) FeesDenom() string {
+	return r.row.FeesDenom
+}
+
+func (r *executionResolver) FeesAmount() float64 {
+	return r.row.FeesAmount
+}
+
+func (r *executionResolver) TxHash() string {
+	return r.row.TxHash
+}
+
+func (r *executionResolver) ExecutedAt() graphql.Time {
+	return graphql.Time{Time: r.row.ExecutedAt}
+}
+
+func (r *executionResolver) Height() Int64 {
+	return Int64(r.row.Height)
+}
+
+func (r *executionResolver) Contract() (*contractResolver, error) {
+	return r.root.forChain(r.row.ChainID).contract(r.row.ContractAddress)
+}
+
+func (r *executionResolver) Block() (*blockResolver, error) {
+	return r.root.forChain(r.row.ChainID).block(r.row.Height)
+}
+
+// Rewards returns the rewards that the executed contract has received for the gas consumed inside the same block
+func (r *executionResolver) Rewards(args pageArgs) (*rewardConnectionResolver, error) {
+	return r.root.forChain(r.row.ChainID).rewards(args.First, args.After, dbtypes.ContractRewardsFilter{
+		HeightRange:     dbtypes.HeightRange{FromHeight: r.row.Height, ToHeight: r.row.Height},
+		ContractAddress: r.row.ContractAddress,
+	})
+}
+
+// executionConnectionResolver resolves the ExecutionConnection GraphQL type
+type executionConnectionResolver struct {
+	nodes    []*executionResolver
+	pageInfo *pageInfoResolver
+}
+
+func (r *executionConnectionResolver) Nodes() []*executionResolver {
+	return r.nodes
+}
+
+func (r *executionConnectionResolver) PageInfo() *pageInfoResolver {
+	return r.pageInfo
+}
+
+// metadataResolver resolves the Metadata GraphQL type
+type metadataResolver struct {
+	root *Resolver
+	row  dbtypes.ContractMetadataRow
+}
+
+func (r *metadataResolver) ChainID() string {
+	return r.row.ChainID
+}
+
+func (r *metadataResolver) ContractAddress() string {
+	return r.row.ContractAddress
+}
+
+func (r *metadataResolver) RewardAddress() string {
+	return r.row.RewardAddress
+}
+
+func (r *metadataResolver) DeveloperAddress() string {
+	return r.row.DeveloperAddress
+}
+
+func (r *metadataResolver) CollectPremium() bool {
+	return r.row.CollectPremium
+}
+
+func (r *metadataResolver) GasRebateToUser() bool {
+	return r.row.GasRebateToUser
+}
+
+func (r *metadataResolver) PremiumPercentageCharged() Int64 {
+	return Int64(r.row.PremiumPercentageCharged)
+}
+
+func (r *metadataResolver) TxHash() string {
+	return r.row.TxHash
+}
+
+func (r *metadataResolver) SavedAt() graphql.Time {
+	return graphql.Time{Time: r.row.SavedAt}
+}
+
+func (r *metadataResolver) Height() Int64 {
+	return Int64(r.row.Height)
+}
+
+func (r *metadataResolver) Contract() (*contractResolver, error) {
+	return r.root.forChain(r.row.ChainID).contract(r.row.ContractAddress)
+}
+
+// metadataConnectionResolver resolves the MetadataConnection GraphQL type
+type metadataConnectionResolver struct {
+	nodes    []*metadataResolver
+	pageInfo *pageInfoResolver
+}
+
+func (r *metadataConnectionResolver) Nodes() []*metadataResolver {
+	return r.nodes
+}
+
+func (r *metadataConnectionResolver) PageInfo() *pageInfoResolver {
+	return r.pageInfo
+}
+
+// rewardResolver resolves the Reward GraphQL type
+type rewardResolver struct {
+	root *Resolver
+	row  dbtypes.ContractRewardRow
+}
+
+func (r *rewardResolver) ChainID() string {
+	return r.row.ChainID
+}
+
+func (r *rewardResolver) ContractAddress() string {
+	return r.row.ContractAddress
+}
+
+func (r *rewardResolver) RewardAddress() string {
+	return r.row.RewardAddress
+}
+
+func (r *rewardResolver) DeveloperAddress() string {
+	return r.row.DeveloperAddress
+}
+
+func (r *rewardResolver) GasConsumed() string {
+	return r.row.GasConsumed
+}
+
+func (r *rewardResolver) ContractRewardsDenom() string {
+	return r.row.ContractRewardsDenom
+}
+
+func (r *rewardResolver) ContractRewardsAmount() float64 {
+	return r.row.ContractRewardsAmount
+}
+
+func (r *rewardResolver) InflationRewardsAmount() float64 {
+	return r.row.InflationRewardsAmount
+}
+
+func (r *rewardResolver) DistributedRewardsAmount() float64 {
+	return r.row.DistributedRewardsAmount
+}
+
+func (r *rewardResolver) LeftoverRewardsAmount() float64 {
+	return r.row.LeftoverRewardsAmount
+}
+
+func (r *rewardResolver) GasRebateToUser() bool {
+	return r.row.GasRebateToUser
+}
+
+func (r *rewardResolver) CollectPremium() bool {
+	return r.row.CollectPremium
+}
+
+func (r *rewardResolver) PremiumPercentageCharged() Int64 {
+	return Int64(r.row.PremiumPercentageCharged)
+}
+
+func (r *rewardResolver) RewardDate() graphql.Time {
+	return graphql.Time{Time: r.row.RewardDate}
+}
+
+func (r *rewardResolver) Height() Int64 {
+	return Int64(r.row.Height)
+}
+
+func (r *rewardResolver) Contract() (*contractResolver, error) {
+	return r.root.forChain(r.row.ChainID).contract(r.row.ContractAddress)
+}
+
+func (r *rewardResolver) Block() (*blockResolver, error) {
+	return r.root.forChain(r.row.ChainID).block(r.row.Height)
+}
+
+// rewardConnectionResolver resolves the RewardConnection GraphQL type
+type rewardConnectionResolver struct {
+	nodes    []*rewardResolver
+	pageInfo *pageInfoResolver
+}
+
+func (r *rewardConnectionResolver) Nodes() []*rewardResolver {
+	return r.nodes
+}
+
+func (r *rewardConnectionResolver) PageInfo() *pageInfoResolver {
+	return r.pageInfo
+}
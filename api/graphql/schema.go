@@ -0,0 +1,205 @@
+package graphql
+
+// schema contains the GraphQL schema exposed by the server
+const schema = `
+schema {
+	query: Query
+}
+
+scalar Time
+
+# Int64 represents a 64-bit signed integer, such as block heights and gas amounts
+scalar Int64
+
+type Query {
+	block(chainId: String, height: Int64!): Block
+	blocks(chainId: String, first: Int, after: String, filter: BlocksFilter): BlockConnection!
+
+	code(chainId: String, codeId: Int64!): Code
+	codes(chainId: String, first: Int, after: String, filter: CodesFilter): CodeConnection!
+
+	contract(chainId: String, address: String!): Contract
+	contracts(chainId: String, first: Int, after: String, filter: ContractsFilter): ContractConnection!
+
+	executions(chainId: String, first: Int, after: String, filter: ExecutionsFilter): ExecutionConnection!
+	metadata(chainId: String, first: Int, after: String, filter: MetadataFilter): MetadataConnection!
+	rewards(chainId: String, first: Int, after: String, filter: RewardsFilter): RewardConnection!
+}
+
+input BlocksFilter {
+	fromHeight: Int64
+	toHeight: Int64
+}
+
+input CodesFilter {
+	fromHeight: Int64
+	toHeight: Int64
+	creator: String
+}
+
+input ContractsFilter {
+	fromHeight: Int64
+	toHeight: Int64
+	codeId: Int64
+	creator: String
+	admin: String
+	label: String
+}
+
+input ExecutionsFilter {
+	fromHeight: Int64
+	toHeight: Int64
+	contractAddress: String
+	sender: String
+	txHash: String
+}
+
+input MetadataFilter {
+	fromHeight: Int64
+	toHeight: Int64
+	contractAddress: String
+	rewardAddress: String
+	developerAddress: String
+}
+
+input RewardsFilter {
+	fromHeight: Int64
+	toHeight: Int64
+	contractAddress: String
+	rewardAddress: String
+	developerAddress: String
+}
+
+type PageInfo {
+	hasNextPage: Boolean!
+	endCursor: String
+}
+
+type Coin {
+	denom: String!
+	amount: String!
+}
+
+type Block {
+	chainId: String!
+	height: Int64!
+	hash: String!
+	numTxs: Int!
+	totalGas: Int64!
+	timestamp: Time!
+	executions(first: Int, after: String): ExecutionConnection!
+	rewards(first: Int, after: String): RewardConnection!
+}
+
+type BlockConnection {
+	nodes: [Block!]!
+	pageInfo: PageInfo!
+}
+
+type Code {
+	chainId: String!
+	codeId: Int64!
+	creator: String!
+	codeHash: String!
+	size: Int!
+	txHash: String!
+	savedAt: Time!
+	height: Int64!
+	contracts(first: Int, after: String): ContractConnection!
+}
+
+type CodeConnection {
+	nodes: [Code!]!
+	pageInfo: PageInfo!
+}
+
+type Contract {
+	chainId: String!
+	address: String!
+	sender: String!
+	creator: String!
+	admin: String!
+	codeId: Int64!
+	label: String!
+	rawContractMessage: String!
+	funds: [Coin!]!
+	txHash: String!
+	instantiatedAt: Time!
+	height: Int64!
+	code: Code
+	executions(first: Int, after: String, filter: ExecutionsFilter): ExecutionConnection!
+	metadata(first: Int, after: String): MetadataConnection!
+	rewards(first: Int, after: String, filter: RewardsFilter): RewardConnection!
+}
+
+type ContractConnection {
+	nodes: [Contract!]!
+	pageInfo: PageInfo!
+}
+
+type Execution {
+	chainId: String!
+	sender: String!
+	contractAddress: String!
+	rawContractMessage: String!
+	funds: [Coin!]!
+	gasUsed: Int64!
+	feesDenom: String!
+	feesAmount: Float!
+	txHash: String!
+	executedAt: Time!
+	height: Int64!
+	contract: Contract
+	block: Block
+	rewards(first: Int, after: String): RewardConnection!
+}
+
+type ExecutionConnection {
+	nodes: [Execution!]!
+	pageInfo: PageInfo!
+}
+
+type Metadata {
+	chainId: String!
+	contractAddress: String!
+	rewardAddress: String!
+	developerAddress: String!
+	collectPremium: Boolean!
+	gasRebateToUser: Boolean!
+	premiumPercentageCharged: Int64!
+	txHash: String!
+	savedAt: Time!
+	height: Int64!
+	contract: Contract
+}
+
+type MetadataConnection {
+	nodes: [Metadata!]!
+	pageInfo: PageInfo!
+}
+
+type Reward {
+	chainId: String!
+	contractAddress: String!
+	rewardAddress: String!
+	developerAddress: String!
+	gasConsumed: String!
+	contractRewardsDenom: String!
+	contractRewardsAmount: Float!
+	inflationRewardsAmount: Float!
+	distributedRewardsAmount: Float!
+	leftoverRewardsAmount: Float!
+	gasRebateToUser: Boolean!
+	collectPremium: Boolean!
+	premiumPercentageCharged: Int64!
+	rewardDate: Time!
+	height: Int64!
+	contract: Contract
+	block: Block
+}
+
+type RewardConnection {
+	nodes: [Reward!]!
+	pageInfo: PageInfo!
+}
+`
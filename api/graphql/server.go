@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	apiconfig "github.com/nuclearblock/archgregator/api/config"
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/logging"
+)
+
+const (
+	// Path represents the path at which the GraphQL endpoint is exposed
+	Path = "/graphql"
+
+	// maxParallelism is the maximum number of resolvers of a single request that run in parallel
+	maxParallelism = 10
+)
+
+// Server represents the HTTP server exposing the indexed data through GraphQL
+type Server struct {
+	server *http.Server
+	logger logging.Logger
+}
+
+// NewServer allows to build a new Server instance that reads the data from the given database
+func NewServer(cfg *apiconfig.GraphQLConfig, db database.Reader, logger logging.Logger) (*Server, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("graphql config cannot be null")
+	}
+
+	maxDepth := cfg.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = apiconfig.DefaultGraphQLMaxDepth
+	}
+
+	parsedSchema, err := graphql.ParseSchema(schema, NewResolver(db, cfg.MaxPageSize),
+		graphql.MaxDepth(maxDepth),
+		graphql.MaxParallelism(maxParallelism),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing graphql schema: %s", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(Path, &relay.Handler{Schema: parsedSchema})
+
+	return &Server{
+		server: &http.Server{
+			Addr:              cfg.Address,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		logger: logger,
+	}, nil
+}
+
+// Start starts serving the GraphQL requests. This method blocks until the server is stopped
+func (s *Server) Start() error {
+	s.logger.Info("starting graphql server", "address", s.server.Addr, "path", Path)
+	err := s.server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("error while serving graphql requests: %s", err)
+	}
+	return nil
+}
+
+// Stop gracefully shuts down the server
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.server.Shutdown(ctx)
+	if err != nil {
+		s.logger.Error("error while stopping graphql server", "err", err)
+	}
+}
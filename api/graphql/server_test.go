@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apiconfig "github.com/nuclearblock/archgregator/api/config"
+	"github.com/nuclearblock/archgregator/database/memory"
+	"github.com/nuclearblock/archgregator/logging"
+)
+
+// response represents the body of a GraphQL response
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// serve sends the given query to a new server using the given config, returning its response
+func serve(t *testing.T, cfg *apiconfig.GraphQLConfig, query string) response {
+	server, err := NewServer(cfg, memory.NewDatabase(), logging.DefaultLogger())
+	require.NoError(t, err)
+
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	server.server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, Path, strings.NewReader(string(body))))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res response
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	return res
+}
+
+// nestedContractsQuery returns a query walking the relation between codes and contracts the given number of times.
+// Each walk adds three nested fields to the query.
+func nestedContractsQuery(walks int) string {
+	query := "height"
+	for i := 0; i < walks; i++ {
+		query = "code { contracts { nodes { " + query + " } } }"
+	}
+	return "{ contracts { nodes { " + query + " } } }"
+}
+
+func TestServerMaxDepth(t *testing.T) {
+	cfg := apiconfig.DefaultGraphQLConfig()
+
+	// The query has a depth of 12, which is allowed by default
+	res := serve(t, cfg, nestedContractsQuery(3))
+	require.Empty(t, res.Errors)
+
+	// The query has a depth of 15
+	res = serve(t, cfg, nestedContractsQuery(4))
+	require.NotEmpty(t, res.Errors)
+	require.Contains(t, res.Errors[0].Message, "exceeds max depth 12")
+	require.Empty(t, res.Data)
+
+	// The depth is configurable
+	cfg.MaxDepth = 20
+	res = serve(t, cfg, nestedContractsQuery(4))
+	require.Empty(t, res.Errors)
+}
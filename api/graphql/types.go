@@ -0,0 +1,158 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+)
+
+// Int64 represents the Int64 GraphQL scalar
+type Int64 int64
+
+// ImplementsGraphQLType implements graphql.Unmarshaler
+func (Int64) ImplementsGraphQLType(name string) bool {
+	return name == "Int64"
+}
+
+// UnmarshalGraphQL implements graphql.Unmarshaler
+func (i *Int64) UnmarshalGraphQL(input interface{}) error {
+	switch value := input.(type) {
+	case int32:
+		*i = Int64(value)
+	case int64:
+		*i = Int64(value)
+	case float64:
+		*i = Int64(value)
+	case string:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid Int64 value: %s", value)
+		}
+		*i = Int64(parsed)
+	default:
+		return fmt.Errorf("invalid Int64 value: %v", input)
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (i Int64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(i))
+}
+
+// int64Value returns the value of the given pointer, or 0 if it is nil
+func int64Value(value *Int64) int64 {
+	if value == nil {
+		return 0
+	}
+	return int64(*value)
+}
+
+// stringValue returns the value of the given pointer, or an empty string if it is nil
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// heightRange builds a new HeightRange from the given GraphQL values
+func heightRange(fromHeight, toHeight *Int64) dbtypes.HeightRange {
+	return dbtypes.HeightRange{
+		FromHeight: int64Value(fromHeight),
+		ToHeight:   int64Value(toHeight),
+	}
+}
+
+// page contains the pagination data of a single connection request
+type page struct {
+	limit int
+	after *dbtypes.Cursor
+}
+
+// newPage builds a new page from the given GraphQL arguments, making sure the page size
+// never exceeds maxPageSize
+func newPage(first *int32, after *string, maxPageSize int) (page, error) {
+	limit := maxPageSize
+	if first != nil {
+		if *first < 0 {
+			return page{}, fmt.Errorf("first must be a non-negative number")
+		}
+		if int(*first) < maxPageSize {
+			limit = int(*first)
+		}
+	}
+
+	var cursor *dbtypes.Cursor
+	if after != nil {
+		var err error
+		cursor, err = dbtypes.DecodeCursor(*after)
+		if err != nil {
+			return page{}, err
+		}
+	}
+
+	return page{limit: limit, after: cursor}, nil
+}
+
+// pagination returns the database pagination to be used. One more row than requested is
+// fetched so that we can tell whether there is a next page
+func (p page) pagination() dbtypes.Pagination {
+	return dbtypes.NewCursorPagination(p.limit+1, p.after)
+}
+
+// pageInfo returns the page info of a page containing the given number of fetched rows,
+// along with the number of rows that should be returned to the user.
+// The cursor function must return the cursor of the fetched row having the given index.
+func (p page) pageInfo(fetched int, cursor func(i int) dbtypes.Cursor) (*pageInfoResolver, int) {
+	count := fetched
+	if count > p.limit {
+		count = p.limit
+	}
+
+	info := &pageInfoResolver{hasNextPage: fetched > p.limit}
+	if count > 0 {
+		endCursor := cursor(count - 1).Encode()
+		info.endCursor = &endCursor
+	}
+
+	return info, count
+}
+
+// pageInfoResolver resolves the PageInfo GraphQL type
+type pageInfoResolver struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (r *pageInfoResolver) HasNextPage() bool {
+	return r.hasNextPage
+}
+
+func (r *pageInfoResolver) EndCursor() *string {
+	return r.endCursor
+}
+
+// coinResolver resolves the Coin GraphQL type
+type coinResolver struct {
+	coin *dbtypes.DbCoin
+}
+
+func (r *coinResolver) Denom() string {
+	return r.coin.Denom
+}
+
+func (r *coinResolver) Amount() string {
+	return r.coin.Amount
+}
+
+// newCoinResolvers returns the resolvers of the given coins
+func newCoinResolvers(coins dbtypes.DbCoins) []*coinResolver {
+	resolvers := make([]*coinResolver, len(coins))
+	for i, coin := range coins {
+		resolvers[i] = &coinResolver{coin: coin}
+	}
+	return resolvers
+}
@@ -0,0 +1,422 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/nuclearblock/archgregator/database"
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+)
+
+// errorResponse represents the body returned when a request fails
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// paginationResponse contains the pagination data of a list response
+type paginationResponse struct {
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// listResponse represents the body returned by the endpoints returning a list of items
+type listResponse struct {
+	Result     interface{}        `json:"result"`
+	Pagination paginationResponse `json:"pagination"`
+}
+
+// writeJSON writes the given value as the JSON body of the response
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes the given error as the JSON body of the response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// writeItem writes the given item, or a 404 error if it is nil
+func writeItem(w http.ResponseWriter, item interface{}, isNil bool, err error) {
+	switch {
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	case isNil:
+		writeError(w, http.StatusNotFound, fmt.Errorf("item not found"))
+	default:
+		writeJSON(w, http.StatusOK, item)
+	}
+}
+
+// writeList writes the given items using the list response format, along with the cursor of the next page if any
+func writeList(w http.ResponseWriter, pagination dbtypes.Pagination, items interface{}, next *dbtypes.Cursor, err error) {
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := paginationResponse{Limit: pagination.Limit - 1, Offset: pagination.Offset}
+	if next != nil {
+		nextCursor := next.Encode()
+		response.NextCursor = &nextCursor
+	}
+
+	writeJSON(w, http.StatusOK, listResponse{Result: items, Pagination: response})
+}
+
+// queryString returns the value of the query parameter having the given name
+func queryString(r *http.Request, name string) string {
+	return r.URL.Query().Get(name)
+}
+
+// parseInt parses the given value as an int64, returning 0 if it is empty
+func parseInt(name, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return parsed, nil
+}
+
+// pathInt returns the path parameter having the given name as an int64
+func pathInt(r *http.Request, name string) (int64, error) {
+	return parseInt(name, mux.Vars(r)[name])
+}
+
+// queryInt returns the query parameter having the given name as an int64
+func queryInt(r *http.Request, name string) (int64, error) {
+	return parseInt(name, queryString(r, name))
+}
+
+// heightRange returns the height range specified using the query parameters
+func heightRange(r *http.Request) (dbtypes.HeightRange, error) {
+	fromHeight, err := queryInt(r, paramFromHeight)
+	if err != nil {
+		return dbtypes.HeightRange{}, err
+	}
+
+	toHeight, err := queryInt(r, paramToHeight)
+	if err != nil {
+		return dbtypes.HeightRange{}, err
+	}
+
+	return dbtypes.HeightRange{FromHeight: fromHeight, ToHeight: toHeight}, nil
+}
+
+// pagination returns the pagination specified using the query parameters, making sure the limit never exceeds
+// the max page size. One more item than requested is fetched so that we can tell whether there is a next page.
+// When a cursor is given, the offset is ignored
+func (s *Server) pagination(r *http.Request) (dbtypes.Pagination, error) {
+	limit, err := queryInt(r, paramLimit)
+	if err != nil {
+		return dbtypes.Pagination{}, err
+	}
+	if limit == 0 || limit > int64(s.maxPageSize) {
+		limit = int64(s.maxPageSize)
+	}
+
+	cursor := queryString(r, paramCursor)
+	if cursor != "" {
+		after, err := dbtypes.DecodeCursor(cursor)
+		if err != nil {
+			return dbtypes.Pagination{}, err
+		}
+		return dbtypes.NewCursorPagination(int(limit)+1, after), nil
+	}
+
+	offset, err := queryInt(r, paramOffset)
+	if err != nil {
+		return dbtypes.Pagination{}, err
+	}
+
+	return dbtypes.NewPagination(int(limit)+1, int(offset)), nil
+}
+
+// listParams returns the height range and pagination specified using the query parameters
+func (s *Server) listParams(r *http.Request) (dbtypes.HeightRange, dbtypes.Pagination, error) {
+	heights, err := heightRange(r)
+	if err != nil {
+		return dbtypes.HeightRange{}, dbtypes.Pagination{}, err
+	}
+
+	pagination, err := s.pagination(r)
+	return heights, pagination, err
+}
+
+// reader returns the reader of the data of the chain specified using the query parameters,
+// or of all the chains if no chain is specified
+func (s *Server) reader(r *http.Request) database.Reader {
+	return database.ReaderForChain(s.db, queryString(r, paramChainID))
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+
+func (s *Server) handleBlocks(w http.ResponseWriter, r *http.Request) {
+	heights, pagination, err := s.listParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows, err := s.reader(r).GetBlocks(dbtypes.BlocksFilter{HeightRange: heights}, pagination)
+	items, next := trimBlocks(rows, pagination)
+	writeList(w, pagination, items, next, err)
+}
+
+func (s *Server) handleBlock(w http.ResponseWriter, r *http.Request) {
+	height, err := pathInt(r, paramHeight)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	row, err := s.reader(r).GetBlock(height)
+	writeItem(w, row, row == nil, err)
+}
+
+func (s *Server) handleBlockExecutions(w http.ResponseWriter, r *http.Request) {
+	height, err := pathInt(r, paramHeight)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	pagination, err := s.pagination(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows, err := s.reader(r).GetWasmExecuteContracts(dbtypes.WasmExecuteContractsFilter{
+		HeightRange: dbtypes.HeightRange{FromHeight: height, ToHeight: height},
+	}, pagination)
+	items, next := trimExecutions(rows, pagination)
+	writeList(w, pagination, items, next, err)
+}
+
+func (s *Server) handleCodes(w http.ResponseWriter, r *http.Request) {
+	heights, pagination, err := s.listParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows, err := s.reader(r).GetWasmCodes(dbtypes.WasmCodesFilter{
+		HeightRange: heights,
+		Creator:     queryString(r, paramCreator),
+	}, pagination)
+	items, next := trimCodes(rows, pagination)
+	writeList(w, pagination, items, next, err)
+}
+
+func (s *Server) handleCode(w http.ResponseWriter, r *http.Request) {
+	codeID, err := pathInt(r, paramCodeID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	row, err := s.reader(r).GetWasmCode(codeID)
+	writeItem(w, row, row == nil, err)
+}
+
+func (s *Server) handleCodeContracts(w http.ResponseWriter, r *http.Request) {
+	codeID, err := pathInt(r, paramCodeID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	heights, pagination, err := s.listParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows, err := s.reader(r).GetWasmContracts(dbtypes.WasmContractsFilter{
+		HeightRange: heights,
+		CodeID:      codeID,
+	}, pagination)
+	items, next := trimContracts(rows, pagination)
+	writeList(w, pagination, items, next, err)
+}
+
+func (s *Server) handleContracts(w http.ResponseWriter, r *http.Request) {
+	heights, pagination, err := s.listParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	codeID, err := queryInt(r, paramCodeID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows, err := s.reader(r).GetWasmContracts(dbtypes.WasmContractsFilter{
+		HeightRange: heights,
+		CodeID:      codeID,
+		Creator:     queryString(r, paramCreator),
+		Admin:       queryString(r, paramAdmin),
+		Label:       queryString(r, paramLabel),
+	}, pagination)
+	items, next := trimContracts(rows, pagination)
+	writeList(w, pagination, items, next, err)
+}
+
+func (s *Server) handleContract(w http.ResponseWriter, r *http.Request) {
+	row, err := s.reader(r).GetWasmContract(mux.Vars(r)[paramAddress])
+	writeItem(w, row, row == nil, err)
+}
+
+func (s *Server) handleContractExecutions(w http.ResponseWriter, r *http.Request) {
+	heights, pagination, err := s.listParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows, err := s.reader(r).GetWasmExecuteContracts(dbtypes.WasmExecuteContractsFilter{
+		HeightRange:     heights,
+		ContractAddress: mux.Vars(r)[paramAddress],
+		Sender:          queryString(r, paramSender),
+		TxHash:          queryString(r, paramTxHash),
+	}, pagination)
+	items, next := trimExecutions(rows, pagination)
+	writeList(w, pagination, items, next, err)
+}
+
+func (s *Server) handleContractMetadata(w http.ResponseWriter, r *http.Request) {
+	heights, pagination, err := s.listParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows, err := s.reader(r).GetContractMetadata(dbtypes.ContractMetadataFilter{
+		HeightRange:     heights,
+		ContractAddress: mux.Vars(r)[paramAddress],
+	}, pagination)
+	items, next := trimMetadata(rows, pagination)
+	writeList(w, pagination, items, next, err)
+}
+
+func (s *Server) handleContractRewards(w http.ResponseWriter, r *http.Request) {
+	heights, pagination, err := s.listParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows, err := s.reader(r).GetContractRewards(dbtypes.ContractRewardsFilter{
+		HeightRange:     heights,
+		ContractAddress: mux.Vars(r)[paramAddress],
+	}, pagination)
+	items, next := trimRewards(rows, pagination)
+	writeList(w, pagination, items, next, err)
+}
+
+func (s *Server) handleExecutions(w http.ResponseWriter, r *http.Request) {
+	heights, pagination, err := s.listParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows, err := s.reader(r).GetWasmExecuteContracts(dbtypes.WasmExecuteContractsFilter{
+		HeightRange:     heights,
+		ContractAddress: queryString(r, paramContractAddress),
+		Sender:          queryString(r, paramSender),
+		TxHash:          queryString(r, paramTxHash),
+	}, pagination)
+	items, next := trimExecutions(rows, pagination)
+	writeList(w, pagination, items, next, err)
+}
+
+func (s *Server) handleRewards(w http.ResponseWriter, r *http.Request) {
+	heights, pagination, err := s.listParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rows, err := s.reader(r).GetContractRewards(dbtypes.ContractRewardsFilter{
+		HeightRange:      heights,
+		RewardAddress:    mux.Vars(r)[paramRewardAddress],
+		ContractAddress:  queryString(r, paramContractAddress),
+		DeveloperAddress: queryString(r, paramDeveloperAddress),
+	}, pagination)
+	items, next := trimRewards(rows, pagination)
+	writeList(w, pagination, items, next, err)
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+
+// pageSize returns the number of items that should be returned out of the given fetched ones
+func pageSize(fetched int, pagination dbtypes.Pagination) int {
+	if fetched > pagination.Limit-1 {
+		return pagination.Limit - 1
+	}
+	return fetched
+}
+
+// nextCursor returns the cursor of the next page, which follows the last returned item, or nil if there is no
+// next page. The cursor function must return the cursor of the fetched item having the given index.
+func nextCursor(fetched int, pagination dbtypes.Pagination, cursor func(i int) dbtypes.Cursor) *dbtypes.Cursor {
+	size := pageSize(fetched, pagination)
+	if fetched <= size || size == 0 {
+		return nil
+	}
+
+	next := cursor(size - 1)
+	return &next
+}
+
+func trimBlocks(rows []dbtypes.BlockRow, pagination dbtypes.Pagination) ([]dbtypes.BlockRow, *dbtypes.Cursor) {
+	next := nextCursor(len(rows), pagination, func(i int) dbtypes.Cursor { return rows[i].Cursor() })
+	return append([]dbtypes.BlockRow{}, rows[:pageSize(len(rows), pagination)]...), next
+}
+
+func trimCodes(rows []dbtypes.WasmCodeRow, pagination dbtypes.Pagination) ([]dbtypes.WasmCodeRow, *dbtypes.Cursor) {
+	next := nextCursor(len(rows), pagination, func(i int) dbtypes.Cursor { return rows[i].Cursor() })
+	return append([]dbtypes.WasmCodeRow{}, rows[:pageSize(len(rows), pagination)]...), next
+}
+
+func trimContracts(
+	rows []dbtypes.WasmContractRow, pagination dbtypes.Pagination,
+) ([]dbtypes.WasmContractRow, *dbtypes.Cursor) {
+	next := nextCursor(len(rows), pagination, func(i int) dbtypes.Cursor { return rows[i].Cursor() })
+	return append([]dbtypes.WasmContractRow{}, rows[:pageSize(len(rows), pagination)]...), next
+}
+
+func trimExecutions(
+	rows []dbtypes.WasmExecuteContractRow, pagination dbtypes.Pagination,
+) ([]dbtypes.WasmExecuteContractRow, *dbtypes.Cursor) {
+	next := nextCursor(len(rows), pagination, func(i int) dbtypes.Cursor { return rows[i].Cursor() })
+	return append([]dbtypes.WasmExecuteContractRow{}, rows[:pageSize(len(rows), pagination)]...), next
+}
+
+func trimMetadata(
+	rows []dbtypes.ContractMetadataRow, pagination dbtypes.Pagination,
+) ([]dbtypes.ContractMetadataRow, *dbtypes.Cursor) {
+	next := nextCursor(len(rows), pagination, func(i int) dbtypes.Cursor { return rows[i].Cursor() })
+	return append([]dbtypes.ContractMetadataRow{}, rows[:pageSize(len(rows), pagination)]...), next
+}
+
+func trimRewards(
+	rows []dbtypes.ContractRewardRow, pagination dbtypes.Pagination,
+) ([]dbtypes.ContractRewardRow, *dbtypes.Cursor) {
+	next := nextCursor(len(rows), pagination, func(i int) dbtypes.Cursor { return rows[i].Cursor() })
+	return append([]dbtypes.ContractRewardRow{}, rows[:pageSize(len(rows), pagination)]...), next
+}
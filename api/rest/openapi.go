@@ -0,0 +1,156 @@
+package rest
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	openAPIVersion = "3.0.3"
+	apiTitle       = "Archgregator REST API"
+	apiVersion     = "1.0.0"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaObject represents an OpenAPI schema object
+type schemaObject map[string]interface{}
+
+// BuildOpenAPIDocument returns the OpenAPI document describing all the endpoints exposed by the REST server.
+// The schemas of the responses are generated from the Go types returned by each endpoint.
+func BuildOpenAPIDocument() map[string]interface{} {
+	schemas := map[string]interface{}{
+		"Error": schemaObject{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"error": schemaObject{"type": "string"},
+			},
+		},
+		"Pagination": schemaObject{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"limit":       schemaObject{"type": "integer"},
+				"offset":      schemaObject{"type": "integer"},
+				"next_cursor": schemaObject{"type": "string", "nullable": true},
+			},
+		},
+	}
+
+	paths := map[string]interface{}{}
+	for _, r := range routes {
+		responseType := reflect.TypeOf(r.Response)
+		schemaName := strings.TrimSuffix(responseType.Name(), "Row")
+		schemas[schemaName] = typeSchema(responseType)
+
+		responseSchema := schemaRef(schemaName)
+		if r.List {
+			responseSchema = schemaObject{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"result":     schemaObject{"type": "array", "items": schemaRef(schemaName)},
+					"pagination": schemaRef("Pagination"),
+				},
+			}
+		}
+
+		parameters := make([]interface{}, len(r.Params))
+		for i, p := range r.Params {
+			parameters[i] = map[string]interface{}{
+				"name":        p.Name,
+				"in":          p.In,
+				"description": p.Description,
+				"required":    p.In == locationPath,
+				"schema":      schemaObject{"type": p.Kind},
+			}
+		}
+
+		paths[r.Path] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary":    r.Summary,
+				"parameters": parameters,
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Successful response", responseSchema),
+					"400": jsonResponse("Invalid parameters", schemaRef("Error")),
+					"404": jsonResponse("Item not found", schemaRef("Error")),
+					"500": jsonResponse("Internal error", schemaRef("Error")),
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":   apiTitle,
+			"version": apiVersion,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+// jsonResponse returns the OpenAPI response object having the given description and JSON schema
+func jsonResponse(description string, schema schemaObject) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": schema,
+			},
+		},
+	}
+}
+
+// schemaRef returns a reference to the schema having the given name
+func schemaRef(name string) schemaObject {
+	return schemaObject{"$ref": "#/components/schemas/" + name}
+}
+
+// typeSchema returns the OpenAPI schema of the given type, based on its JSON encoding
+func typeSchema(t reflect.Type) schemaObject {
+	if t.Kind() == reflect.Ptr {
+		return typeSchema(t.Elem())
+	}
+
+	switch {
+	case t == timeType:
+		return schemaObject{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return schemaObject{"type": "object"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return schemaObject{"type": "string"}
+	case reflect.Bool:
+		return schemaObject{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return schemaObject{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schemaObject{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return schemaObject{"type": "number", "format": "double"}
+	case reflect.Slice, reflect.Array:
+		return schemaObject{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			properties[name] = typeSchema(field.Type)
+		}
+		return schemaObject{"type": "object", "properties": properties}
+	default:
+		return schemaObject{}
+	}
+}
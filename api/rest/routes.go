@@ -0,0 +1,207 @@
+package rest
+
+import (
+	"net/http"
+
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+)
+
+const (
+	paramChainID          = "chain_id"
+	paramHeight           = "height"
+	paramCodeID           = "code_id"
+	paramAddress          = "address"
+	paramRewardAddress    = "reward_address"
+	paramLimit            = "limit"
+	paramOffset           = "offset"
+	paramCursor           = "cursor"
+	paramFromHeight       = "from_height"
+	paramToHeight         = "to_height"
+	paramCreator          = "creator"
+	paramAdmin            = "admin"
+	paramLabel            = "label"
+	paramSender           = "sender"
+	paramTxHash           = "tx_hash"
+	paramContractAddress  = "contract_address"
+	paramDeveloperAddress = "developer_address"
+)
+
+const (
+	locationPath  = "path"
+	locationQuery = "query"
+
+	kindString  = "string"
+	kindInteger = "integer"
+)
+
+// param describes a single parameter accepted by a route
+type param struct {
+	Name        string
+	In          string
+	Kind        string
+	Description string
+}
+
+// route describes a single REST endpoint along with the information used to build its OpenAPI definition
+type route struct {
+	Path     string
+	Summary  string
+	Params   []param
+	Response interface{}
+	List     bool
+	Handler  func(s *Server, w http.ResponseWriter, r *http.Request)
+}
+
+var (
+	heightParam  = param{paramHeight, locationPath, kindInteger, "Height of the block"}
+	codeIDParam  = param{paramCodeID, locationPath, kindInteger, "Id of the wasm code"}
+	addressParam = param{paramAddress, locationPath, kindString, "Address of the contract"}
+	chainIDParam = param{paramChainID, locationQuery, kindString, "Id of the chain to read the data of. If empty, the data of all the chains is read"}
+
+	paginationParams = []param{
+		{paramLimit, locationQuery, kindInteger, "Maximum number of items to return"},
+		{paramOffset, locationQuery, kindInteger, "Number of items to skip, ignored when cursor is set"},
+		{paramCursor, locationQuery, kindString, "Next cursor returned by the previous page"},
+	}
+
+	heightRangeParams = []param{
+		{paramFromHeight, locationQuery, kindInteger, "Minimum height (inclusive)"},
+		{paramToHeight, locationQuery, kindInteger, "Maximum height (inclusive)"},
+	}
+)
+
+// itemParams returns the given params along with the chain id one
+func itemParams(params ...param) []param {
+	return append(append([]param{}, params...), chainIDParam)
+}
+
+// listParams returns the given params along with the chain id, pagination and height range ones
+func listParams(params ...param) []param {
+	result := itemParams(params...)
+	result = append(result, heightRangeParams...)
+	return append(result, paginationParams...)
+}
+
+// routes contains all the endpoints exposed by the REST server
+var routes = []route{
+	{
+		Path:     "/blocks",
+		Summary:  "List the indexed blocks",
+		Params:   listParams(),
+		Response: dbtypes.BlockRow{},
+		List:     true,
+		Handler:  (*Server).handleBlocks,
+	},
+	{
+		Path:     "/blocks/{height}",
+		Summary:  "Get the block having the given height",
+		Params:   itemParams(heightParam),
+		Response: dbtypes.BlockRow{},
+		Handler:  (*Server).handleBlock,
+	},
+	{
+		Path:     "/blocks/{height}/executions",
+		Summary:  "List the contract executions included inside the block having the given height",
+		Params:   append(itemParams(heightParam), paginationParams...),
+		Response: dbtypes.WasmExecuteContractRow{},
+		List:     true,
+		Handler:  (*Server).handleBlockExecutions,
+	},
+	{
+		Path:    "/codes",
+		Summary: "List the stored wasm codes",
+		Params: listParams(
+			param{paramCreator, locationQuery, kindString, "Address of the code creator"},
+		),
+		Response: dbtypes.WasmCodeRow{},
+		List:     true,
+		Handler:  (*Server).handleCodes,
+	},
+	{
+		Path:     "/codes/{code_id}",
+		Summary:  "Get the wasm code having the given id",
+		Params:   itemParams(codeIDParam),
+		Response: dbtypes.WasmCodeRow{},
+		Handler:  (*Server).handleCode,
+	},
+	{
+		Path:     "/codes/{code_id}/contracts",
+		Summary:  "List the contracts instantiated from the wasm code having the given id",
+		Params:   listParams(codeIDParam),
+		Response: dbtypes.WasmContractRow{},
+		List:     true,
+		Handler:  (*Server).handleCodeContracts,
+	},
+	{
+		Path:    "/contracts",
+		Summary: "List the instantiated contracts",
+		Params: listParams(
+			param{paramCodeID, locationQuery, kindInteger, "Id of the code the contracts have been instantiated from"},
+			param{paramCreator, locationQuery, kindString, "Address of the contract creator"},
+			param{paramAdmin, locationQuery, kindString, "Address of the contract admin"},
+			param{paramLabel, locationQuery, kindString, "Label of the contract (case insensitive, supports % wildcards)"},
+		),
+		Response: dbtypes.WasmContractRow{},
+		List:     true,
+		Handler:  (*Server).handleContracts,
+	},
+	{
+		Path:     "/contracts/{address}",
+		Summary:  "Get the contract having the given address",
+		Params:   itemParams(addressParam),
+		Response: dbtypes.WasmContractRow{},
+		Handler:  (*Server).handleContract,
+	},
+	{
+		Path:    "/contracts/{address}/executions",
+		Summary: "List the executions of the contract having the given address",
+		Params: listParams(
+			addressParam,
+			param{paramSender, locationQuery, kindString, "Address of the execution sender"},
+			param{paramTxHash, locationQuery, kindString, "Hash of the transaction containing the execution"},
+		),
+		Response: dbtypes.WasmExecuteContractRow{},
+		List:     true,
+		Handler:  (*Server).handleContractExecutions,
+	},
+	{
+		Path:     "/contracts/{address}/metadata",
+		Summary:  "List the gastracker metadata set for the contract having the given address",
+		Params:   listParams(addressParam),
+		Response: dbtypes.ContractMetadataRow{},
+		List:     true,
+		Handler:  (*Server).handleContractMetadata,
+	},
+	{
+		Path:     "/contracts/{address}/rewards",
+		Summary:  "List the gastracker rewards of the contract having the given address",
+		Params:   listParams(addressParam),
+		Response: dbtypes.ContractRewardRow{},
+		List:     true,
+		Handler:  (*Server).handleContractRewards,
+	},
+	{
+		Path:    "/executions",
+		Summary: "List the contract executions",
+		Params: listParams(
+			param{paramContractAddress, locationQuery, kindString, "Address of the executed contract"},
+			param{paramSender, locationQuery, kindString, "Address of the execution sender"},
+			param{paramTxHash, locationQuery, kindString, "Hash of the transaction containing the execution"},
+		),
+		Response: dbtypes.WasmExecuteContractRow{},
+		List:     true,
+		Handler:  (*Server).handleExecutions,
+	},
+	{
+		Path:    "/rewards/{reward_address}",
+		Summary: "List the gastracker rewards sent to the given reward address",
+		Params: listParams(
+			param{paramRewardAddress, locationPath, kindString, "Address receiving the rewards"},
+			param{paramContractAddress, locationQuery, kindString, "Address of the rewarded contract"},
+			param{paramDeveloperAddress, locationQuery, kindString, "Address of the contract developer"},
+		),
+		Response: dbtypes.ContractRewardRow{},
+		List:     true,
+		Handler:  (*Server).handleRewards,
+	},
+}
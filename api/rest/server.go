@@ -0,0 +1,99 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	apiconfig "github.com/nuclearblock/archgregator/api/config"
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/logging"
+)
+
+const (
+	// OpenAPIPath represents the path at which the OpenAPI document is served
+	OpenAPIPath = "/openapi.json"
+)
+
+// Server represents the HTTP server exposing the indexed data through a REST API
+type Server struct {
+	db          database.Reader
+	maxPageSize int
+	openAPI     []byte
+
+	server *http.Server
+	logger logging.Logger
+}
+
+// NewServer allows to build a new Server instance that reads the data from the given database
+func NewServer(cfg *apiconfig.RESTConfig, db database.Reader, logger logging.Logger) (*Server, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("rest config cannot be null")
+	}
+
+	openAPI, err := json.Marshal(BuildOpenAPIDocument())
+	if err != nil {
+		return nil, fmt.Errorf("error while building the OpenAPI document: %s", err)
+	}
+
+	s := &Server{
+		db:          db,
+		maxPageSize: cfg.MaxPageSize,
+		openAPI:     openAPI,
+		logger:      logger,
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc(OpenAPIPath, s.handleOpenAPI).Methods(http.MethodGet)
+	for _, r := range routes {
+		handler := r.Handler
+		router.HandleFunc(r.Path, func(w http.ResponseWriter, req *http.Request) {
+			handler(s, w, req)
+		}).Methods(http.MethodGet)
+	}
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("endpoint not found"))
+	})
+
+	s.server = &http.Server{
+		Addr:              cfg.Address,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s, nil
+}
+
+// Start starts serving the REST requests. This method blocks until the server is stopped
+func (s *Server) Start() error {
+	s.logger.Info("starting rest server", "address", s.server.Addr)
+	err := s.server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("error while serving rest requests: %s", err)
+	}
+	return nil
+}
+
+// Stop gracefully shuts down the server
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.server.Shutdown(ctx)
+	if err != nil {
+		s.logger.Error("error while stopping rest server", "err", err)
+	}
+}
+
+// handleOpenAPI serves the OpenAPI document describing all the available endpoints
+func (s *Server) handleOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, err := w.Write(s.openAPI)
+	if err != nil {
+		s.logger.Error("error while writing OpenAPI document", "err", err)
+	}
+}
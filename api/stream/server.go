@@ -0,0 +1,270 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	apiconfig "github.com/nuclearblock/archgregator/api/config"
+	"github.com/nuclearblock/archgregator/broker"
+	"github.com/nuclearblock/archgregator/logging"
+)
+
+const (
+	// WebSocketPath represents the path at which the events are streamed using WebSocket
+	WebSocketPath = "/ws"
+
+	// SSEPath represents the path at which the events are streamed using Server-Sent Events
+	SSEPath = "/events"
+
+	paramChains    = "chains"
+	paramTypes     = "types"
+	paramContracts = "contracts"
+
+	pingInterval = 30 * time.Second
+	writeTimeout = 10 * time.Second
+)
+
+// Server represents the HTTP server streaming the newly indexed data in real time
+type Server struct {
+	broker     *broker.Broker
+	bufferSize int
+	upgrader   websocket.Upgrader
+	done       chan struct{}
+
+	server *http.Server
+	logger logging.Logger
+}
+
+// NewServer allows to build a new Server instance that streams the events published to the given broker
+func NewServer(cfg *apiconfig.StreamConfig, broker *broker.Broker, logger logging.Logger) (*Server, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("stream config cannot be null")
+	}
+
+	s := &Server{
+		broker:     broker,
+		bufferSize: cfg.BufferSize,
+		upgrader: websocket.Upgrader{
+			// Dashboards are usually served from a different origin
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+		done:   make(chan struct{}),
+		logger: logger,
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc(WebSocketPath, s.handleWebSocket).Methods(http.MethodGet)
+	router.HandleFunc(SSEPath, s.handleSSE).Methods(http.MethodGet)
+
+	s.server = &http.Server{
+		Addr:              cfg.Address,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s, nil
+}
+
+// Start starts serving the stream requests. This method blocks until the server is stopped
+func (s *Server) Start() error {
+	s.logger.Info("starting stream server", "address", s.server.Addr)
+	err := s.server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("error while serving stream requests: %s", err)
+	}
+	return nil
+}
+
+// Stop closes all the open streams and gracefully shuts down the server
+func (s *Server) Stop() {
+	close(s.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.server.Shutdown(ctx)
+	if err != nil {
+		s.logger.Error("error while stopping stream server", "err", err)
+	}
+}
+
+// parseFilter builds the broker filter using the query parameters of the given request.
+// The chain ids, the event types and the contract addresses can be given as comma separated lists.
+func parseFilter(r *http.Request) (broker.Filter, error) {
+	query := r.URL.Query()
+
+	eventTypes := splitValues(query[paramTypes])
+	for _, eventType := range eventTypes {
+		if !isValidEventType(eventType) {
+			return broker.Filter{}, fmt.Errorf("invalid event type %s, must be one of %s",
+				eventType, strings.Join(broker.EventTypes, ", "))
+		}
+	}
+
+	return broker.NewFilter(splitValues(query[paramChains]), eventTypes, splitValues(query[paramContracts])), nil
+}
+
+// splitValues returns all the non empty comma separated items contained inside the given values
+func splitValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+// isValidEventType tells whether the given event type can be published by the broker
+func isValidEventType(eventType string) bool {
+	for _, t := range broker.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError writes the given error as a JSON response having the given status
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// handleWebSocket streams the matching events as JSON messages over a WebSocket connection
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader already replied to the client
+		s.logger.Debug("error while upgrading websocket connection", "err", err)
+		return
+	}
+	defer conn.Close()
+
+	sub := s.broker.Subscribe(filter, s.bufferSize)
+	defer sub.Close()
+
+	// Read the incoming messages so that control frames are handled and closed connections are detected
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+				time.Now().Add(writeTimeout))
+			return
+
+		case <-closed:
+			return
+
+		case <-ticker.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout))
+			if err != nil {
+				return
+			}
+
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+
+			_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			err = conn.WriteJSON(event)
+			if err != nil {
+				s.logger.Debug("error while writing websocket event", "err", err)
+				return
+			}
+		}
+	}
+}
+
+// handleSSE streams the matching events using Server-Sent Events
+func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	sub := s.broker.Subscribe(filter, s.bufferSize)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+
+		case <-r.Context().Done():
+			return
+
+		case <-ticker.C:
+			// Comments are ignored by the clients but keep the connection alive through proxies
+			_, err = fmt.Fprint(w, ": ping\n\n")
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+
+			bz, err := json.Marshal(event)
+			if err != nil {
+				s.logger.Error("error while serializing stream event", "type", event.Type, "err", err)
+				continue
+			}
+
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, bz)
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
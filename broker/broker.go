@@ -0,0 +1,169 @@
+package broker
+
+import (
+	"sync"
+
+	"github.com/nuclearblock/archgregator/logging"
+)
+
+const (
+	EventTypeBlock                      = "block"
+	EventTypeWasmCode                   = "wasm_code"
+	EventTypeWasmContract               = "wasm_contract"
+	EventTypeWasmExecuteContract        = "wasm_execute_contract"
+	EventTypeContractMetadata           = "contract_metadata"
+	EventTypeContractRewardCalculation  = "contract_reward_calculation"
+	EventTypeContractRewardDistribution = "contract_reward_distribution"
+)
+
+// EventTypes contains all the event types that can be published by the broker
+var EventTypes = []string{
+	EventTypeBlock,
+	EventTypeWasmCode,
+	EventTypeWasmContract,
+	EventTypeWasmExecuteContract,
+	EventTypeContractMetadata,
+	EventTypeContractRewardCalculation,
+	EventTypeContractRewardDistribution,
+}
+
+// Event represents a single record that has been committed to the database
+type Event struct {
+	ChainID         string      `json:"chain_id,omitempty"`
+	Type            string      `json:"type"`
+	Height          int64       `json:"height"`
+	ContractAddress string      `json:"contract_address,omitempty"`
+	Data            interface{} `json:"data"`
+}
+
+// NewEvent allows to build a new Event instance
+func NewEvent(eventType string, height int64, contractAddress string, data interface{}) Event {
+	return Event{
+		Type:            eventType,
+		Height:          height,
+		ContractAddress: contractAddress,
+		Data:            data,
+	}
+}
+
+// Filter allows to select the events a subscription is interested in.
+// Empty lists match everything.
+type Filter struct {
+	ChainIDs          []string
+	EventTypes        []string
+	ContractAddresses []string
+}
+
+// NewFilter allows to build a new Filter instance
+func NewFilter(chainIDs []string, eventTypes []string, contractAddresses []string) Filter {
+	return Filter{
+		ChainIDs:          chainIDs,
+		EventTypes:        eventTypes,
+		ContractAddresses: contractAddresses,
+	}
+}
+
+// Matches tells whether the given event satisfies the filter.
+// When filtering by contract, events that are not related to any contract never match.
+func (f Filter) Matches(event Event) bool {
+	return contains(f.ChainIDs, event.ChainID) &&
+		contains(f.EventTypes, event.Type) &&
+		contains(f.ContractAddresses, event.ContractAddress)
+}
+
+// contains tells whether the given value is inside the given list, or the list is empty
+func contains(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Broker dispatches the published events to all the matching subscriptions
+type Broker struct {
+	mu            sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+}
+
+// NewBroker returns a new Broker instance
+func NewBroker() *Broker {
+	return &Broker{
+		subscriptions: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish sends the given events to all the subscriptions that match them.
+// This method never blocks on slow subscribers.
+func (b *Broker) Publish(events ...Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, event := range events {
+		for sub := range b.subscriptions {
+			if !sub.filter.Matches(event) {
+				continue
+			}
+
+			select {
+			case sub.events <- event:
+			default:
+				logging.StreamDroppedEvents.Inc()
+			}
+		}
+	}
+}
+
+// Subscribe registers a new subscription receiving all the events matching the given filter.
+// The subscription buffers up to bufferSize events, after which the events are dropped until it catches up.
+func (b *Broker) Subscribe(filter Filter, bufferSize int) *Subscription {
+	sub := &Subscription{
+		broker: b,
+		filter: filter,
+		events: make(chan Event, bufferSize),
+	}
+
+	b.mu.Lock()
+	b.subscriptions[sub] = struct{}{}
+	b.mu.Unlock()
+
+	logging.StreamSubscriptions.Inc()
+	return sub
+}
+
+// unsubscribe removes the given subscription and closes its events channel
+func (b *Broker) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscriptions[sub]; !ok {
+		return
+	}
+
+	delete(b.subscriptions, sub)
+	close(sub.events)
+	logging.StreamSubscriptions.Dec()
+}
+
+// Subscription represents a single consumer of the broker events
+type Subscription struct {
+	broker *Broker
+	filter Filter
+	events chan Event
+}
+
+// Events returns the channel on which the matching events are delivered.
+// The channel is closed once the subscription is closed.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close stops the delivery of the events to this subscription
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s)
+}
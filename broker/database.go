@@ -0,0 +1,174 @@
+package broker
+
+import (
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// type check to ensure interface is properly implemented
+var (
+	_ database.Database = &Database{}
+	_ database.Batcher  = &Database{}
+)
+
+// Database wraps a database.Database and publishes to a Broker every record saved through it,
+// once the block containing it has been written. Since the pending records are not related to
+// any particular height, a Database instance must only be used by one worker at a time.
+type Database struct {
+	database.Database
+
+	broker  *Broker
+	chainID string
+	pending []Event
+}
+
+// NewDatabase returns a new Database instance that saves the data inside db and publishes it to broker
+func NewDatabase(db database.Database, broker *Broker) *Database {
+	return &Database{
+		Database: db,
+		broker:   broker,
+	}
+}
+
+// ForChain implements database.Database.
+// The events published by the returned Database refer to the chain having the given id.
+func (db *Database) ForChain(chainID string) database.Database {
+	return &Database{
+		Database: db.Database.ForChain(chainID),
+		broker:   db.broker,
+		chainID:  chainID,
+	}
+}
+
+// SaveBlock implements database.Database.
+// Since this is the first method called for each block, any event left by a previous failed block is discarded.
+func (db *Database) SaveBlock(block *types.Block) error {
+	db.pending = nil
+
+	err := db.Database.SaveBlock(block)
+	if err != nil {
+		return err
+	}
+
+	db.pending = append(db.pending, NewEvent(EventTypeBlock, block.Height, "", block))
+	return nil
+}
+
+// SaveWasmCode implements database.Database
+func (db *Database) SaveWasmCode(wasmCode types.WasmCode) error {
+	err := db.Database.SaveWasmCode(wasmCode)
+	if err != nil {
+		return err
+	}
+
+	db.pending = append(db.pending, NewEvent(EventTypeWasmCode, wasmCode.Height, "", wasmCode))
+	return nil
+}
+
+// SaveWasmContract implements database.Database
+func (db *Database) SaveWasmContract(wasmContract types.WasmContract) error {
+	err := db.Database.SaveWasmContract(wasmContract)
+	if err != nil {
+		return err
+	}
+
+	db.pending = append(db.pending, NewEvent(
+		EventTypeWasmContract, wasmContract.Height, wasmContract.ContractAddress, wasmContract,
+	))
+	return nil
+}
+
+// SaveWasmExecuteContract implements database.Database
+func (db *Database) SaveWasmExecuteContract(executeContract types.WasmExecuteContract) error {
+	err := db.Database.SaveWasmExecuteContract(executeContract)
+	if err != nil {
+		return err
+	}
+
+	db.pending = append(db.pending, NewEvent(
+		EventTypeWasmExecuteContract, executeContract.Height, executeContract.ContractAddress, executeContract,
+	))
+	return nil
+}
+
+// SaveContractRewardCalculation implements database.Database
+func (db *Database) SaveContractRewardCalculation(contractRewardCalculation types.ContractRewardCalculation) error {
+	err := db.Database.SaveContractRewardCalculation(contractRewardCalculation)
+	if err != nil {
+		return err
+	}
+
+	db.pending = append(db.pending, NewEvent(
+		EventTypeContractRewardCalculation,
+		contractRewardCalculation.Height,
+		contractRewardCalculation.ContractAddress,
+		contractRewardCalculation,
+	))
+	return nil
+}
+
+// SaveContractRewardDistribution implements database.Database
+func (db *Database) SaveContractRewardDistribution(contractRewardDistribution types.ContractRewardDistribution) error {
+	err := db.Database.SaveContractRewardDistribution(contractRewardDistribution)
+	if err != nil {
+		return err
+	}
+
+	db.pending = append(db.pending, NewEvent(
+		EventTypeContractRewardDistribution, contractRewardDistribution.Height, "", contractRewardDistribution,
+	))
+	return nil
+}
+
+// SaveGasTrackerContractMetadata implements database.Database
+func (db *Database) SaveGasTrackerContractMetadata(gastrackerContractMetadata types.GasTrackerContractMetadata) error {
+	err := db.Database.SaveGasTrackerContractMetadata(gastrackerContractMetadata)
+	if err != nil {
+		return err
+	}
+
+	db.pending = append(db.pending, NewEvent(
+		EventTypeContractMetadata,
+		gastrackerContractMetadata.Height,
+		gastrackerContractMetadata.ContractAddress,
+		gastrackerContractMetadata,
+	))
+	return nil
+}
+
+// CommitBlock implements database.Database.
+// All the events collected since the block has been saved are published once the underlying database has written
+// its records, which might happen later on when it buffers them.
+func (db *Database) CommitBlock(block *types.Block) error {
+	return db.CommitBlockAsync(block, func(error) {})
+}
+
+// CommitBlockAsync implements database.Batcher.
+// The events are only published when the records of the block have been written successfully.
+func (db *Database) CommitBlockAsync(block *types.Block, done func(err error)) error {
+	events := db.pending
+	db.pending = nil
+	for i := range events {
+		events[i].ChainID = db.chainID
+	}
+
+	published := func(err error) {
+		if err == nil {
+			db.broker.Publish(events...)
+		}
+		done(err)
+	}
+
+	batcher, ok := db.Database.(database.Batcher)
+	if ok {
+		return batcher.CommitBlockAsync(block, published)
+	}
+
+	err := db.Database.CommitBlock(block)
+	if err != nil {
+		return err
+	}
+
+	published(nil)
+	return nil
+}
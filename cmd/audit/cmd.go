@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"github.com/spf13/cobra"
+
+	parsecmdtypes "github.com/nuclearblock/archgregator/cmd/parse/types"
+)
+
+// NewAuditCmd returns the Cobra command allowing to check the consistency of the indexed data
+func NewAuditCmd(parseCfg *parsecmdtypes.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "audit",
+		Short:             "Check the consistency of the indexed data",
+		PersistentPreRunE: runPersistentPreRuns(parsecmdtypes.ReadConfigPreRunE(parseCfg)),
+	}
+
+	cmd.AddCommand(
+		newRewardsCmd(parseCfg),
+	)
+
+	return cmd
+}
+
+func runPersistentPreRuns(preRun func(_ *cobra.Command, _ []string) error) func(_ *cobra.Command, _ []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if root := cmd.Root(); root != nil {
+			if root.PersistentPreRunE != nil {
+				err := root.PersistentPreRunE(root, args)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return preRun(cmd, args)
+	}
+}
@@ -0,0 +1,291 @@
+package audit
+
+import (
+	"fmt"
+	"math"
+
+	gastrackertypes "github.com/archway-network/archway/x/gastracker/types"
+	"github.com/gogo/protobuf/proto"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	parsecmdtypes "github.com/nuclearblock/archgregator/cmd/parse/types"
+	"github.com/nuclearblock/archgregator/database"
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+	"github.com/nuclearblock/archgregator/parser"
+	"github.com/nuclearblock/archgregator/types/config"
+)
+
+const (
+	flagFrom      = "from"
+	flagTo        = "to"
+	flagChainID   = "chain-id"
+	flagTolerance = "tolerance"
+	flagRepair    = "repair"
+)
+
+const (
+	discrepancyMissingDistribution      = "missing distribution"
+	discrepancyInconsistentDistribution = "inconsistent distribution"
+	discrepancyUnbalanced               = "unbalanced"
+)
+
+// rewardDiscrepancy represents the rewards of a reward address at a given height that do not add up
+type rewardDiscrepancy struct {
+	Kind             string
+	RewardAddress    string
+	Height           int64
+	Calculated       float64
+	PreviousLeftover float64
+	Distributed      float64
+	Leftover         float64
+}
+
+// newRewardsCmd returns a Cobra command that allows to reconcile the gastracker rewards of a range of heights
+func newRewardsCmd(parseConfig *parsecmdtypes.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rewards",
+		Short: "Check that the gastracker rewards calculated, distributed and left over add up",
+		Long: fmt.Sprintf(`Cross-checks the gastracker rewards stored for each reward address at each height between the %[1]s and
+%[2]s heights (both included). At each height, the rewards calculated for all the contracts of a reward address,
+plus the rewards left over at its previous height, must be equal to the rewards distributed plus the ones left over.
+All the rewards that do not add up are reported.
+If %[2]s is not set, the rewards are checked up to the latest stored height.
+When %[3]s is set, the BeginBlock events of the blocks containing the distributions of the reported rewards are
+fetched again from the node, and the distributions are stored again.
+Once repaired, the rewards leaderboards of the affected days should be rebuilt with the db rebuild-aggregates command.
+`, flagFrom, flagTo, flagRepair),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, _ := cmd.Flags().GetInt64(flagFrom)
+			to, _ := cmd.Flags().GetInt64(flagTo)
+			chainID, _ := cmd.Flags().GetString(flagChainID)
+			tolerance, _ := cmd.Flags().GetFloat64(flagTolerance)
+			repair, _ := cmd.Flags().GetBool(flagRepair)
+
+			parseCtx, err := parsecmdtypes.GetChainParserContext(config.Cfg, parseConfig, chainID)
+			if err != nil {
+				return err
+			}
+			defer parseCtx.Database.Close()
+
+			db := parseCtx.Database.ForChain(parseCtx.ChainID)
+			reader, ok := db.(rewardsReader)
+			if !ok {
+				return fmt.Errorf("reading the rewards is not supported by the %s database", config.Cfg.Database.Type)
+			}
+
+			if to == 0 {
+				blocks, err := reader.GetBlocks(dbtypes.BlocksFilter{}, dbtypes.NewPagination(1, 0))
+				if err != nil {
+					return err
+				}
+				if len(blocks) == 0 {
+					return fmt.Errorf("no block stored for chain %s", parseCtx.ChainID)
+				}
+				to = blocks[0].Height
+			}
+
+			log.Info().Int64("from", from).Int64("to", to).Msg("auditing rewards")
+			discrepancies, err := auditRewards(reader, from, to, tolerance)
+			if err != nil {
+				return err
+			}
+
+			for _, d := range discrepancies {
+				log.Warn().Str("kind", d.Kind).Str("reward address", d.RewardAddress).Int64("height", d.Height).
+					Float64("calculated", d.Calculated).Float64("previous leftover", d.PreviousLeftover).
+					Float64("distributed", d.Distributed).Float64("leftover", d.Leftover).
+					Msg("rewards discrepancy")
+			}
+			log.Info().Int("discrepancies", len(discrepancies)).Msg("rewards audited")
+
+			if !repair || len(discrepancies) == 0 {
+				return nil
+			}
+
+			err = repairRewards(parseCtx, db, discrepancies)
+			if err != nil {
+				return err
+			}
+
+			discrepancies, err = auditRewards(reader, from, to, tolerance)
+			if err != nil {
+				return err
+			}
+			log.Info().Int("discrepancies", len(discrepancies)).Msg("rewards audited after repair")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Int64(flagFrom, 1, "First height whose rewards should be checked")
+	cmd.Flags().Int64(flagTo, 0, "Last height whose rewards should be checked. If 0, the latest stored height will be used instead")
+	cmd.Flags().String(flagChainID, "", "Id of the chain whose rewards should be checked when multiple chains are configured. If empty, the first chain will be used")
+	cmd.Flags().Float64(flagTolerance, 0.001, "Maximum difference between the calculated and distributed rewards that is not reported")
+	cmd.Flags().Bool(flagRepair, false, "Fetch again the distributions of the reported rewards from the node and store them")
+
+	return cmd
+}
+
+// rewardsReader represents a database whose rewards can be audited
+type rewardsReader interface {
+	database.Reader
+	database.RewardsStreamer
+}
+
+// auditRewards checks the rewards stored between the given heights, and returns the ones that do not add up.
+// The rewards are streamed sorted by reward address and height, so that only the rows of a single reward address
+// at a single height are kept in memory while being checked.
+// The differences smaller than the given tolerance are ignored, since the amounts are stored as floating numbers
+func auditRewards(db rewardsReader, from, to int64, tolerance float64) ([]rewardDiscrepancy, error) {
+	var discrepancies []rewardDiscrepancy
+	var group []dbtypes.ContractRewardRow
+	var leftover float64
+
+	// check checks the rewards of the current group, and carries over its leftover to the following height
+	check := func() {
+		if len(group) == 0 {
+			return
+		}
+
+		d := checkRewards(group[0].RewardAddress, group[0].Height, group, leftover, tolerance)
+		if d != nil {
+			discrepancies = append(discrepancies, *d)
+		}
+
+		// The stored leftover is carried over even when wrong, so a missing distribution is usually reported
+		// at the following height too
+		leftover = group[0].LeftoverRewardsAmount
+		group = group[:0]
+	}
+
+	filter := dbtypes.ContractRewardsFilter{HeightRange: dbtypes.HeightRange{FromHeight: from, ToHeight: to}}
+	err := db.StreamContractRewards(filter, func(row dbtypes.ContractRewardRow) error {
+		if len(group) > 0 && group[0].RewardAddress == row.RewardAddress && group[0].Height == row.Height {
+			group = append(group, row)
+			return nil
+		}
+
+		newAddress := len(group) == 0 || group[0].RewardAddress != row.RewardAddress
+		check()
+
+		if newAddress {
+			var err error
+			leftover, err = getPreviousLeftover(db, row.RewardAddress, from)
+			if err != nil {
+				return err
+			}
+		}
+
+		group = append(group, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	check()
+
+	return discrepancies, nil
+}
+
+// getPreviousLeftover returns the rewards left over to the given reward address at the latest height
+// lower than the given one, or 0 if no rewards are stored for such address before that height
+func getPreviousLeftover(db database.Reader, rewardAddress string, height int64) (float64, error) {
+	if height <= 1 {
+		return 0, nil
+	}
+
+	rows, err := db.GetContractRewards(dbtypes.ContractRewardsFilter{
+		HeightRange:   dbtypes.HeightRange{ToHeight: height - 1},
+		RewardAddress: rewardAddress,
+	}, dbtypes.NewPagination(1, 0))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return rows[0].LeftoverRewardsAmount, nil
+}
+
+// checkRewards checks that the given rewards of a reward address at a height add up, given the rewards left over
+// at its previous height, and returns the discrepancy found, if any.
+// The distribution of each height is stored inside all the rows of the reward address, so they must all match
+func checkRewards(
+	rewardAddress string, height int64, rows []dbtypes.ContractRewardRow, previousLeftover, tolerance float64,
+) *rewardDiscrepancy {
+	d := &rewardDiscrepancy{
+		RewardAddress:    rewardAddress,
+		Height:           height,
+		PreviousLeftover: previousLeftover,
+		Distributed:      rows[0].DistributedRewardsAmount,
+		Leftover:         rows[0].LeftoverRewardsAmount,
+	}
+
+	consistent := true
+	for _, row := range rows {
+		d.Calculated += row.ContractRewardsAmount
+		if row.DistributedRewardsAmount != d.Distributed || row.LeftoverRewardsAmount != d.Leftover {
+			consistent = false
+		}
+	}
+
+	total := d.Calculated + d.PreviousLeftover
+	switch {
+	case !consistent:
+		d.Kind = discrepancyInconsistentDistribution
+	case d.Distributed == 0 && d.Leftover == 0 && total > tolerance:
+		d.Kind = discrepancyMissingDistribution
+	case math.Abs(total-d.Distributed-d.Leftover) > tolerance:
+		d.Kind = discrepancyUnbalanced
+	default:
+		return nil
+	}
+
+	return d
+}
+
+// repairRewards fetches again from the node the BeginBlock events of the blocks containing the distributions
+// of the given discrepancies, and stores such distributions again.
+// The calculation events are skipped, since storing them again would duplicate the existing rewards rows
+func repairRewards(ctx *parser.Context, db database.Database, discrepancies []rewardDiscrepancy) error {
+	distributionEventType := proto.MessageName(&gastrackertypes.RewardDistributionEvent{})
+
+	repaired := map[int64]bool{}
+	for _, d := range discrepancies {
+		// The rewards of a height are distributed inside the BeginBlock of the following one
+		height := d.Height + 1
+		if repaired[height] {
+			continue
+		}
+
+		block, err := ctx.Node.Block(height)
+		if err != nil {
+			return fmt.Errorf("error while getting block %d from node: %s", height, err)
+		}
+
+		results, err := ctx.Node.BlockResults(height)
+		if err != nil {
+			return fmt.Errorf("error while getting block results %d from node: %s", height, err)
+		}
+
+		var distributions int
+		for _, event := range results.BeginBlockEvents {
+			if event.Type != distributionEventType {
+				continue
+			}
+
+			err = parser.HandleGasTrackerRewards(&event, height, block.Block.Time, db)
+			if err != nil {
+				return fmt.Errorf("error while repairing rewards distributed at height %d: %s", height, err)
+			}
+			distributions++
+		}
+
+		log.Info().Int64("height", height).Int("distributions", distributions).Msg("repaired rewards distributions")
+		repaired[height] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	parsecmdtypes "github.com/nuclearblock/archgregator/cmd/parse/types"
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/types/config"
+)
+
+const (
+	flagFrom = "from"
+	flagTo   = "to"
+
+	// dayLayout is the layout of the days accepted by the flagFrom and flagTo flags
+	dayLayout = "2006-01-02"
+)
+
+// newRebuildAggregatesCmd returns a Cobra command that allows to compute again the aggregates of a range of days
+func newRebuildAggregatesCmd(parseConfig *parsecmdtypes.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rebuild-aggregates",
+		Short: "Compute again the aggregate tables of a range of days starting from the stored records",
+		Long: fmt.Sprintf(`Removes the aggregates of all the days between the %[1]s day (included) and the %[2]s day (excluded), 
+and computes them again starting from the records stored inside the database. Days must be given as YYYY-MM-DD. 
+If %[2]s is not set, the aggregates are rebuilt up to the current day included. 
+Only the days whose records have not been pruned should be rebuilt. 
+`, flagFrom, flagTo),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fromValue, _ := cmd.Flags().GetString(flagFrom)
+			toValue, _ := cmd.Flags().GetString(flagTo)
+			chainID, _ := cmd.Flags().GetString(flagChainID)
+
+			from, err := time.Parse(dayLayout, fromValue)
+			if err != nil {
+				return fmt.Errorf("invalid %s day: %s", flagFrom, err)
+			}
+
+			to := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+			if toValue != "" {
+				to, err = time.Parse(dayLayout, toValue)
+				if err != nil {
+					return fmt.Errorf("invalid %s day: %s", flagTo, err)
+				}
+			}
+
+			db, err := parsecmdtypes.GetDatabase(config.Cfg, parseConfig)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			aggregator, ok := db.ForChain(chainID).(database.Aggregator)
+			if !ok {
+				return fmt.Errorf("aggregates are not supported by the %s database", config.Cfg.Database.Type)
+			}
+
+			log.Info().Str("from", from.Format(dayLayout)).Str("to", to.Format(dayLayout)).Msg("rebuilding aggregates")
+			return aggregator.RebuildAggregates(from, to)
+		},
+	}
+
+	cmd.Flags().String(flagFrom, "", "First day whose aggregates should be rebuilt")
+	cmd.Flags().String(flagTo, "", "Day following the last one whose aggregates should be rebuilt. If empty, the day following the current one will be used")
+	cmd.Flags().String(flagChainID, "", "Id of the chain whose aggregates should be rebuilt. If empty, the aggregates of all the chains will be rebuilt")
+
+	return cmd
+}
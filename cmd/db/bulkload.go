@@ -0,0 +1,221 @@
+package db
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	parsecmdtypes "github.com/nuclearblock/archgregator/cmd/parse/types"
+	"github.com/nuclearblock/archgregator/database"
+	databaseconfig "github.com/nuclearblock/archgregator/database/config"
+	"github.com/nuclearblock/archgregator/parser"
+	"github.com/nuclearblock/archgregator/types/config"
+)
+
+const (
+	flagStart     = "start"
+	flagEnd       = "end"
+	flagChainID   = "chain-id"
+	flagBatchSize = "batch-size"
+
+	// progressInterval is the interval at which the loading progress is reported
+	progressInterval = 10 * time.Second
+)
+
+// newBulkLoadCmd returns a Cobra command that allows to load the whole history of a chain from scratch
+func newBulkLoadCmd(parseConfig *parsecmdtypes.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bulk-load",
+		Short: "Load the history of a chain from scratch, deferring the creation of the secondary indexes",
+		Long: fmt.Sprintf(`Parses all the blocks in the specified range as fast as possible, and stores them inside the database.
+The secondary indexes of the database are dropped before starting and rebuilt concurrently once all the blocks
+have been stored, while the records are written using COPY in batches of %s blocks.
+You can specify a custom blocks range by using the %s and %s flags.
+Blocks already present inside the database are skipped, so an interrupted loading can be resumed by running
+this command again. Only the postgresql database is supported.
+`, flagBatchSize, flagStart, flagEnd),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			chainID, _ := cmd.Flags().GetString(flagChainID)
+			start, _ := cmd.Flags().GetInt64(flagStart)
+			end, _ := cmd.Flags().GetInt64(flagEnd)
+			batchSize, _ := cmd.Flags().GetInt(flagBatchSize)
+
+			// Write the records in batches using COPY, regardless of the configuration
+			cfg := config.Cfg
+			cfg.Database.Batch = databaseconfig.NewBatchConfig(
+				batchSize, databaseconfig.DefaultBatchConfig().FlushInterval, true,
+			)
+
+			parseCtx, err := parsecmdtypes.GetChainParserContext(cfg, parseConfig, chainID)
+			if err != nil {
+				return err
+			}
+			defer parseCtx.Database.Close()
+
+			loader, ok := parseCtx.Database.(database.BulkLoader)
+			if !ok {
+				return fmt.Errorf("bulk loading is not supported by the %s database", cfg.Database.Type)
+			}
+
+			// Get the start height, default to the config's height; use flagStart if set
+			startHeight := parseCtx.Config.StartHeight
+			if start > 0 {
+				startHeight = start
+			}
+
+			// Get the end height, default to the node latest height; use flagEnd if set
+			endHeight, err := parseCtx.Node.LatestHeight()
+			if err != nil {
+				return fmt.Errorf("error while getting chain latest block height: %s", err)
+			}
+			if end > 0 {
+				endHeight = end
+			}
+
+			// The batches are written while the following blocks are being parsed, so the first block that fails
+			// to be written is reported on its own
+			writeErrs := make(chan error, 1)
+			parseCtx.WriteFailed = func(height int64, err error) {
+				select {
+				case writeErrs <- fmt.Errorf("error while writing block %d: %s", height, err):
+				default:
+				}
+			}
+
+			deferred, err := loader.DeferIndexes()
+			if err != nil {
+				return err
+			}
+			log.Info().Strs("indexes", deferred).Msg("deferred secondary indexes")
+
+			log.Info().Int64("start height", startHeight).Int64("end height", endHeight).
+				Msg("loading blocks")
+			err = loadBlocks(parseCtx, startHeight, endHeight, writeErrs)
+			if err != nil {
+				return fmt.Errorf("%s. Run this command again to resume the loading", err)
+			}
+
+			log.Info().Msg("rebuilding secondary indexes")
+			err = loader.RebuildIndexes(func(index string, done, total int) {
+				log.Info().Str("index", index).Int("done", done).Int("total", total).Msg("rebuilt index")
+			})
+			if err != nil {
+				return fmt.Errorf("%s. Run this command again to resume the loading", err)
+			}
+
+			// A batch might have failed to be written after all the blocks have been parsed
+			select {
+			case err = <-writeErrs:
+				return fmt.Errorf("%s. Run this command again to resume the loading", err)
+			default:
+				return nil
+			}
+		},
+	}
+
+	cmd.Flags().Int64(flagStart, 0, "Height from which to start loading blocks. If 0, the start height inside the config will be used instead")
+	cmd.Flags().Int64(flagEnd, 0, "Height at which to finish loading blocks. If 0, the latest height available inside the node will be used instead")
+	cmd.Flags().String(flagChainID, "", "Id of the chain to load the blocks of when multiple chains are configured. If empty, the first chain will be used")
+	cmd.Flags().Int(flagBatchSize, 1000, "Number of blocks whose records are written together")
+
+	return cmd
+}
+
+// loadBlocks parses all the blocks between the given heights using the workers of the given context,
+// reporting the progress periodically. The first error, either returned by a worker or received from writeErrs
+// once a block has failed to be written, stops the loading.
+func loadBlocks(ctx *parser.Context, startHeight, endHeight int64, writeErrs <-chan error) error {
+	total := endHeight - startHeight + 1
+	if total <= 0 {
+		return nil
+	}
+
+	workers := int(ctx.Config.Workers)
+	if workers < 1 {
+		workers = 1
+	}
+
+	heights := make(chan int64)
+	stop := make(chan struct{})
+	errs := make(chan error, workers)
+
+	var loaded int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		worker := parser.NewWorker(ctx, nil, i)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for height := range heights {
+				err := worker.ProcessIfNotExists(height)
+				if err != nil {
+					errs <- fmt.Errorf("error while loading block %d: %s", height, err)
+					return
+				}
+				atomic.AddInt64(&loaded, 1)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(heights)
+		for height := startHeight; height <= endHeight; height++ {
+			select {
+			case heights <- height:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	started := time.Now()
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-errs:
+			close(stop)
+			<-done
+			return err
+
+		case err := <-writeErrs:
+			close(stop)
+			<-done
+			return err
+
+		case <-done:
+			close(stop)
+			select {
+			case err := <-errs:
+				return err
+			case err := <-writeErrs:
+				return err
+			default:
+			}
+
+			log.Info().Int64("loaded", total).Dur("elapsed", time.Since(started)).Msg("loaded all blocks")
+			return nil
+
+		case <-ticker.C:
+			count := atomic.LoadInt64(&loaded)
+			log.Info().
+				Int64("loaded", count).
+				Int64("total", total).
+				Float64("percent", float64(count)*100/float64(total)).
+				Float64("blocks per second", float64(count)/time.Since(started).Seconds()).
+				Msg("loading blocks")
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"github.com/spf13/cobra"
+
+	parsecmdtypes "github.com/nuclearblock/archgregator/cmd/parse/types"
+)
+
+// NewDbCmd returns the Cobra command allowing to manage the database the data is indexed into
+func NewDbCmd(parseCfg *parsecmdtypes.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "db",
+		Short:             "Manage the database the chain data is indexed into",
+		PersistentPreRunE: runPersistentPreRuns(parsecmdtypes.ReadConfigPreRunE(parseCfg)),
+	}
+
+	cmd.AddCommand(
+		newBulkLoadCmd(parseCfg),
+		newPruneCmd(parseCfg),
+		newRebuildAggregatesCmd(parseCfg),
+	)
+
+	return cmd
+}
+
+func runPersistentPreRuns(preRun func(_ *cobra.Command, _ []string) error) func(_ *cobra.Command, _ []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if root := cmd.Root(); root != nil {
+			if root.PersistentPreRunE != nil {
+				err := root.PersistentPreRunE(root, args)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return preRun(cmd, args)
+	}
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	parsecmdtypes "github.com/nuclearblock/archgregator/cmd/parse/types"
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/types/config"
+)
+
+// newPruneCmd returns a Cobra command that allows to remove the rows exceeding the configured retention
+func newPruneCmd(parseConfig *parsecmdtypes.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Short: "Remove the rows exceeding the retention configured for their tables",
+		Long: `Removes, in batches, the rows of each table configured inside the database retention section that are older 
+than the configured maximum age, or that are more than the configured number of heights behind the latest indexed block.
+Rows that are still referenced by live contracts are never removed, and each pruning is recorded inside the prune_log table.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			retention := config.Cfg.Database.Retention
+			if retention == nil {
+				return fmt.Errorf("no retention is configured")
+			}
+
+			db, err := parsecmdtypes.GetDatabase(config.Cfg, parseConfig)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			pruner, ok := db.(database.Pruner)
+			if !ok {
+				return fmt.Errorf("pruning is not supported by the %s database", config.Cfg.Database.Type)
+			}
+
+			prunings, err := pruner.Prune(retention)
+			for _, pruning := range prunings {
+				log.Info().Str("chain_id", pruning.ChainID).Str("table", pruning.Table).Int64("rows", pruning.Rows).
+					Msg("pruned table")
+			}
+			return err
+		},
+	}
+}
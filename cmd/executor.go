@@ -7,9 +7,13 @@ import (
 
 	"github.com/nuclearblock/archgregator/types/config"
 
+	auditcmd "github.com/nuclearblock/archgregator/cmd/audit"
+	dbcmd "github.com/nuclearblock/archgregator/cmd/db"
 	initcmd "github.com/nuclearblock/archgregator/cmd/init"
 	parsecmd "github.com/nuclearblock/archgregator/cmd/parse"
+	servecmd "github.com/nuclearblock/archgregator/cmd/serve"
 	startcmd "github.com/nuclearblock/archgregator/cmd/start"
+	verifycmd "github.com/nuclearblock/archgregator/cmd/verify"
 
 	"github.com/nuclearblock/archgregator/types"
 
@@ -40,6 +44,10 @@ func BuildDefaultExecutor(config *Config) cli.Executor {
 		initcmd.NewInitCmd(config.GetInitConfig()),
 		parsecmd.NewParseCmd(config.GetParseConfig()),
 		startcmd.NewStartCmd(config.GetParseConfig()),
+		servecmd.NewServeCmd(config.GetParseConfig()),
+		dbcmd.NewDbCmd(config.GetParseConfig()),
+		auditcmd.NewAuditCmd(config.GetParseConfig()),
+		verifycmd.NewVerifyCmd(config.GetParseConfig()),
 	)
 
 	return PrepareRootCmd(config.GetName(), rootCmd)
@@ -14,9 +14,10 @@ import (
 )
 
 const (
-	flagForce = "force"
-	flagStart = "start"
-	flagEnd   = "end"
+	flagForce   = "force"
+	flagStart   = "start"
+	flagEnd     = "end"
+	flagChainID = "chain-id"
 )
 
 // newAllCmd returns a Cobra command that allows to fix missing blocks in database
@@ -31,13 +32,14 @@ You can override this behaviour using the %s flag. If this is set, even the bloc
 will be replaced with the data downloaded from the node.
 `, flagStart, flagEnd, flagForce),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			parseCtx, err := parsecmdtypes.GetParserContext(config.Cfg, parseConfig)
+			chainID, _ := cmd.Flags().GetString(flagChainID)
+			parseCtx, err := parsecmdtypes.GetChainParserContext(config.Cfg, parseConfig, chainID)
 			if err != nil {
 				return err
 			}
+			defer parseCtx.Database.Close()
 
-			workerCtx := parser.NewContext(parseCtx.EncodingConfig, parseCtx.Node, parseCtx.Database, parseCtx.Logger)
-			worker := parser.NewWorker(workerCtx, nil, 0)
+			worker := parser.NewWorker(parseCtx, nil, 0)
 
 			// Get the flag values
 			start, _ := cmd.Flags().GetInt64(flagStart)
@@ -45,7 +47,7 @@ will be replaced with the data downloaded from the node.
 			force, _ := cmd.Flags().GetBool(flagForce)
 
 			// Get the start height, default to the config's height; use flagStart if set
-			startHeight := config.Cfg.Parser.StartHeight
+			startHeight := parseCtx.Config.StartHeight
 			if start > 0 {
 				startHeight = start
 			}
@@ -80,6 +82,7 @@ will be replaced with the data downloaded from the node.
 	cmd.Flags().Bool(flagForce, false, "Whether or not to overwrite any existing ones in database (default false)")
 	cmd.Flags().Int64(flagStart, 0, "Height from which to start getting missing blocks. If 0, the start height inside the config will be used instead")
 	cmd.Flags().Int64(flagEnd, 0, "Height at which to finish getting missing. If 0, the latest height available inside the node will be used instead")
+	cmd.Flags().String(flagChainID, "", "Id of the chain to get the blocks of when multiple chains are configured. If empty, the first chain will be used")
 
 	return cmd
 }
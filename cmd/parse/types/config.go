@@ -39,6 +39,11 @@ func UpdatedGlobalCfg(cfg *Config) error {
 		return err
 	}
 
+	err = archgregatorCfg.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %s", err)
+	}
+
 	// Set the global configuration
 	config.Cfg = archgregatorCfg
 	return nil
@@ -11,9 +11,9 @@ import (
 type SdkConfigSetup func(config config.Config, sdkConfig *sdk.Config)
 
 // DefaultConfigSetup represents a handy implementation of SdkConfigSetup that simply setups the prefix
-// inside the configuration
+// inside the configuration. When multiple chains are configured, the prefix of the first one is used
 func DefaultConfigSetup(cfg config.Config, sdkConfig *sdk.Config) {
-	prefix := cfg.Chain.Bech32Prefix
+	prefix := cfg.GetChains()[0].Bech32Prefix
 	sdkConfig.SetBech32PrefixForAccount(
 		prefix,
 		prefix+sdk.PrefixPublic,
@@ -11,12 +11,67 @@ import (
 
 	"github.com/nuclearblock/archgregator/database"
 
+	"github.com/archway-network/archway/app/params"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
 )
 
-// GetParserContext setups all the things that can be used to later parse the chain state
+// GetParserContext setups all the things that can be used to later parse the chain state.
+// When multiple chains are configured, only the context of the first one is returned
 func GetParserContext(cfg config.Config, parseConfig *Config) (*parser.Context, error) {
+	return GetChainParserContext(cfg, parseConfig, "")
+}
+
+// GetChainParserContext setups all the things that can be used to later parse the state of the configured chain
+// having the given id. If the id is empty, the first configured chain is used
+func GetChainParserContext(cfg config.Config, parseConfig *Config, chainID string) (*parser.Context, error) {
+	chains := cfg.GetChains()
+	chainCfg := chains[0]
+	if chainID != "" {
+		found := false
+		for _, chain := range chains {
+			if chain.ChainID == chainID {
+				chainCfg, found = chain, true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("chain %s is not configured", chainID)
+		}
+	}
+
+	encodingConfig, db, err := setupParsing(cfg, parseConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return getChainParserContext(chainCfg, &encodingConfig, db, parseConfig)
+}
+
+// GetParserContexts setups all the things that can be used to later parse the state of each configured chain,
+// returning one context for each of them. All the contexts share the same database
+func GetParserContexts(cfg config.Config, parseConfig *Config) ([]*parser.Context, error) {
+	encodingConfig, db, err := setupParsing(cfg, parseConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []*parser.Context
+	for _, chainCfg := range cfg.GetChains() {
+		ctx, err := getChainParserContext(chainCfg, &encodingConfig, db, parseConfig)
+		if err != nil {
+			return nil, err
+		}
+		contexts = append(contexts, ctx)
+	}
+
+	return contexts, nil
+}
+
+// setupParsing setups the SDK configuration and the logging, and returns the codec and the database
+// that should be used while parsing
+func setupParsing(cfg config.Config, parseConfig *Config) (params.EncodingConfig, database.Database, error) {
 	// Build the codec
 	encodingConfig := parseConfig.GetEncodingConfigBuilder()()
 
@@ -31,17 +86,56 @@ func GetParserContext(cfg config.Config, parseConfig *Config) (*parser.Context,
 	databaseCtx := database.NewContext(cfg.Database, &encodingConfig, parseConfig.GetLogger())
 	db, err := parseConfig.GetDBBuilder()(databaseCtx)
 	if err != nil {
-		return nil, err
+		return encodingConfig, nil, err
 	}
 
+	// Setup the logging
+	err = parseConfig.GetLogger().SetLogFormat(cfg.Logging.LogFormat)
+	if err != nil {
+		return encodingConfig, nil, fmt.Errorf("error while setting logging format: %s", err)
+	}
+
+	err = parseConfig.GetLogger().SetLogLevel(cfg.Logging.LogLevel)
+	if err != nil {
+		return encodingConfig, nil, fmt.Errorf("error while setting logging level: %s", err)
+	}
+
+	return encodingConfig, db, nil
+}
+
+// getChainParserContext builds the node of the given chain and returns the context used to parse it.
+// If the chain id is not configured, the one of the node is used instead
+func getChainParserContext(
+	chainCfg config.IndexedChainConfig, encodingConfig *params.EncodingConfig, db database.Database, parseConfig *Config,
+) (*parser.Context, error) {
 	// Init the client
-	cp, err := nodebuilder.BuildNode(cfg.Node, &encodingConfig)
+	cp, err := nodebuilder.BuildNode(chainCfg.Node, encodingConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start client: %s", err)
 	}
 
+	chainID := chainCfg.ChainID
+	if chainID == "" {
+		genesis, err := cp.Genesis()
+		if err != nil {
+			return nil, fmt.Errorf("error while getting chain id from node: %s", err)
+		}
+		chainID = genesis.Genesis.ChainID
+	}
+
+	ctx := parser.NewContext(chainID, chainCfg.Parser, encodingConfig, cp, db, parseConfig.GetLogger())
+	ctx.ChainIDFromNode = chainCfg.ChainID == ""
+	return ctx, nil
+}
+
+// GetDatabase setups the logging and returns the database built using the given configuration.
+// This should be used by the commands that only need to access the stored data, without contacting the node
+func GetDatabase(cfg config.Config, parseConfig *Config) (database.Database, error) {
+	// Build the codec
+	encodingConfig := parseConfig.GetEncodingConfigBuilder()()
+
 	// Setup the logging
-	err = parseConfig.GetLogger().SetLogFormat(cfg.Logging.LogFormat)
+	err := parseConfig.GetLogger().SetLogFormat(cfg.Logging.LogFormat)
 	if err != nil {
 		return nil, fmt.Errorf("error while setting logging format: %s", err)
 	}
@@ -51,7 +145,9 @@ func GetParserContext(cfg config.Config, parseConfig *Config) (*parser.Context,
 		return nil, fmt.Errorf("error while setting logging level: %s", err)
 	}
 
-	return parser.NewContext(&encodingConfig, cp, db, parseConfig.GetLogger()), nil
+	// Get the db
+	databaseCtx := database.NewContext(cfg.Database, &encodingConfig, parseConfig.GetLogger())
+	return parseConfig.GetDBBuilder()(databaseCtx)
 }
 
 // getConfig returns the SDK Config instance as well as if it's sealed or not
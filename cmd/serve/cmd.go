@@ -0,0 +1,41 @@
+package serve
+
+import (
+	"github.com/spf13/cobra"
+
+	parsecmdtypes "github.com/nuclearblock/archgregator/cmd/parse/types"
+
+	servegraphql "github.com/nuclearblock/archgregator/cmd/serve/graphql"
+	serverest "github.com/nuclearblock/archgregator/cmd/serve/rest"
+)
+
+// NewServeCmd returns the Cobra command allowing to expose the indexed data through the available APIs
+func NewServeCmd(parseCfg *parsecmdtypes.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "serve",
+		Short:             "Expose the indexed data through one of the available APIs",
+		PersistentPreRunE: runPersistentPreRuns(parsecmdtypes.ReadConfigPreRunE(parseCfg)),
+	}
+
+	cmd.AddCommand(
+		servegraphql.NewGraphQLCmd(parseCfg),
+		serverest.NewRESTCmd(parseCfg),
+	)
+
+	return cmd
+}
+
+func runPersistentPreRuns(preRun func(_ *cobra.Command, _ []string) error) func(_ *cobra.Command, _ []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if root := cmd.Root(); root != nil {
+			if root.PersistentPreRunE != nil {
+				err := root.PersistentPreRunE(root, args)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return preRun(cmd, args)
+	}
+}
@@ -0,0 +1,57 @@
+package graphql
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	parsecmdtypes "github.com/nuclearblock/archgregator/cmd/parse/types"
+	servecmdtypes "github.com/nuclearblock/archgregator/cmd/serve/types"
+
+	"github.com/nuclearblock/archgregator/api/graphql"
+	"github.com/nuclearblock/archgregator/types/config"
+)
+
+const (
+	flagAddress = "address"
+)
+
+// NewGraphQLCmd returns the Cobra command allowing to serve the indexed data through GraphQL
+func NewGraphQLCmd(parseConfig *parsecmdtypes.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graphql",
+		Short: "Serve the indexed wasm and gastracker data through a GraphQL API",
+		Long: fmt.Sprintf(`Start an HTTP server exposing blocks, codes, contracts, executions, metadata and rewards 
+through a GraphQL API available at the %s path. 
+By default the server listens on the address set inside the config, which can be overridden using the %s flag.
+`, graphql.Path, flagAddress),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := config.Cfg.API.GraphQL
+			if cfg == nil {
+				return fmt.Errorf("missing graphql configuration inside the api section of the config file")
+			}
+
+			address, _ := cmd.Flags().GetString(flagAddress)
+			if address != "" {
+				cfg.Address = address
+			}
+
+			db, reader, err := servecmdtypes.GetDatabaseReader(parseConfig)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			server, err := graphql.NewServer(cfg, reader, parseConfig.GetLogger())
+			if err != nil {
+				return err
+			}
+
+			return servecmdtypes.StartServer(server)
+		},
+	}
+
+	cmd.Flags().String(flagAddress, "", "Address on which to listen. If empty, the address inside the config will be used instead")
+
+	return cmd
+}
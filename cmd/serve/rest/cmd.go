@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	parsecmdtypes "github.com/nuclearblock/archgregator/cmd/parse/types"
+	servecmdtypes "github.com/nuclearblock/archgregator/cmd/serve/types"
+
+	"github.com/nuclearblock/archgregator/api/rest"
+	"github.com/nuclearblock/archgregator/types/config"
+)
+
+const (
+	flagAddress     = "address"
+	flagPrintSchema = "print-openapi"
+)
+
+// NewRESTCmd returns the Cobra command allowing to serve the indexed data through a REST API
+func NewRESTCmd(parseConfig *parsecmdtypes.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rest",
+		Short: "Serve the indexed wasm and gastracker data through a REST API",
+		Long: fmt.Sprintf(`Start an HTTP server exposing blocks, codes, contracts, executions, metadata and rewards 
+through a REST API. The OpenAPI document describing all the endpoints is served at the %s path, 
+and can be printed without starting the server using the %s flag.
+By default the server listens on the address set inside the config, which can be overridden using the %s flag.
+`, rest.OpenAPIPath, flagPrintSchema, flagAddress),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printSchema, _ := cmd.Flags().GetBool(flagPrintSchema)
+			if printSchema {
+				bz, err := json.MarshalIndent(rest.BuildOpenAPIDocument(), "", "  ")
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(string(bz))
+				return nil
+			}
+
+			cfg := config.Cfg.API.REST
+			if cfg == nil {
+				return fmt.Errorf("missing rest configuration inside the api section of the config file")
+			}
+
+			address, _ := cmd.Flags().GetString(flagAddress)
+			if address != "" {
+				cfg.Address = address
+			}
+
+			db, reader, err := servecmdtypes.GetDatabaseReader(parseConfig)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			server, err := rest.NewServer(cfg, reader, parseConfig.GetLogger())
+			if err != nil {
+				return err
+			}
+
+			return servecmdtypes.StartServer(server)
+		},
+	}
+
+	cmd.Flags().String(flagAddress, "", "Address on which to listen. If empty, the address inside the config will be used instead")
+	cmd.Flags().Bool(flagPrintSchema, false, "Print the OpenAPI document and exit without starting the server")
+
+	return cmd
+}
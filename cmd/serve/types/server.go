@@ -0,0 +1,52 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	parsecmdtypes "github.com/nuclearblock/archgregator/cmd/parse/types"
+
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/types/config"
+)
+
+// Server represents a generic server exposing the indexed data
+type Server interface {
+	// Start starts serving the requests, blocking until the server is stopped
+	Start() error
+
+	// Stop gracefully shuts down the server
+	Stop()
+}
+
+// GetDatabaseReader returns the database that should be used to read the indexed data.
+// An error is returned if the configured database does not support reading the stored data
+func GetDatabaseReader(parseConfig *parsecmdtypes.Config) (database.Database, database.Reader, error) {
+	db, err := parsecmdtypes.GetDatabase(config.Cfg, parseConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, ok := db.(database.Reader)
+	if !ok {
+		db.Close()
+		return nil, nil, fmt.Errorf("the configured database does not support reading the stored data")
+	}
+
+	return db, reader, nil
+}
+
+// StartServer starts the given server, gracefully stopping it once an OS signal is trapped
+func StartServer(server Server) error {
+	go func() {
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+
+		<-sigCh
+		server.Stop()
+	}()
+
+	return server.Start()
+}
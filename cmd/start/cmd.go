@@ -8,7 +8,12 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/nuclearblock/archgregator/api/stream"
+	"github.com/nuclearblock/archgregator/broker"
 	parsecmdtypes "github.com/nuclearblock/archgregator/cmd/parse/types"
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/notifications"
+	"github.com/nuclearblock/archgregator/snapshots"
 
 	"github.com/nuclearblock/archgregator/logging"
 
@@ -19,32 +24,157 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const (
+	flagSkipChainCheck = "skip-chain-check"
+)
+
 var (
 	waitGroup sync.WaitGroup
 )
 
 // NewStartCmd returns the command that should be run when we want to start parsing a chain state.
 func NewStartCmd(cmdCfg *parsecmdtypes.Config) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:     "start",
 		Short:   "Start parsing the blockchain data",
 		PreRunE: parsecmdtypes.ReadConfigPreRunE(cmdCfg),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			context, err := parsecmdtypes.GetParserContext(config.Cfg, cmdCfg)
+			contexts, err := parsecmdtypes.GetParserContexts(config.Cfg, cmdCfg)
 			if err != nil {
 				return err
 			}
 
-			return StartParsing(context)
+			skipChainCheck, _ := cmd.Flags().GetBool(flagSkipChainCheck)
+			for _, context := range contexts {
+				if skipChainCheck {
+					context.Logger.Info("skipping chain identity check", "chain_id", context.ChainID)
+					continue
+				}
+
+				err = parser.CheckChainInfo(context)
+				if err != nil {
+					return fmt.Errorf("error while checking chain %s identity: %s", context.ChainID, err)
+				}
+			}
+
+			return StartParsing(contexts...)
 		},
 	}
+
+	cmd.Flags().Bool(flagSkipChainCheck, false, "Start parsing even if the node serves a different chain than the one stored inside the database")
+
+	return cmd
 }
 
-// StartParsing represents the function that should be called when the parse command is executed
-func StartParsing(ctx *parser.Context) error {
-	// Get the config
-	cfg := config.Cfg.Parser
-	logging.StartHeight.Add(float64(cfg.StartHeight))
+// StartParsing represents the function that should be called when the parse command is executed.
+// Each of the given contexts is parsed by its own pool of workers, and they must all share the same database
+func StartParsing(contexts ...*parser.Context) error {
+	if len(contexts) == 0 {
+		return fmt.Errorf("no chain to be parsed")
+	}
+	ctx := contexts[0]
+
+	// Publish the committed records if any consumer requires them
+	streamCfg, notificationsCfg := config.Cfg.API.Stream, config.Cfg.Notifications
+	if streamCfg != nil || notificationsCfg != nil {
+		eventsBroker := broker.NewBroker()
+		for _, chainCtx := range contexts {
+			chainCtx.Broker = eventsBroker
+		}
+	}
+
+	// Stream the committed records in real time if requested
+	if streamCfg != nil {
+		streamServer, err := stream.NewServer(streamCfg, ctx.Broker, ctx.Logger)
+		if err != nil {
+			return fmt.Errorf("error while creating stream server: %s", err)
+		}
+
+		go func() {
+			err := streamServer.Start()
+			if err != nil {
+				ctx.Logger.Error("error while running stream server", "err", err)
+			}
+		}()
+		defer streamServer.Stop()
+	}
+
+	// Send the webhook notifications if requested
+	if notificationsCfg != nil {
+		// The reader is only required by some rules, which will complain if it is missing
+		reader, _ := ctx.Database.(database.Reader)
+		engine, err := notifications.NewEngine(notificationsCfg, ctx.Broker, ctx.Database, reader, ctx.Logger)
+		if err != nil {
+			return fmt.Errorf("error while creating notifications engine: %s", err)
+		}
+
+		engine.Start()
+		defer engine.Stop()
+	}
+
+	// Store the state of the watched contracts periodically if requested
+	if config.Cfg.Snapshots != nil {
+		snapshotter, err := snapshots.NewSnapshotter(config.Cfg.Snapshots, contexts, ctx.Logger)
+		if err != nil {
+			return fmt.Errorf("error while creating snapshotter: %s", err)
+		}
+
+		snapshotter.Start()
+		defer snapshotter.Stop()
+	}
+
+	// Prune the rows exceeding the retention in background if requested
+	startPruning(ctx)
+
+	waitGroup.Add(1)
+
+	for _, chainCtx := range contexts {
+		startChainParsing(chainCtx)
+	}
+
+	// Listen for and trap any OS signal to gracefully shutdown and exit
+	trapSignal(contexts)
+
+	// Block main process (signal capture will call WaitGroup's Done)
+	waitGroup.Wait()
+	return nil
+}
+
+// startPruning periodically removes the rows exceeding the configured retention, if the pruning
+// is configured to run in background
+func startPruning(ctx *parser.Context) {
+	cfg := config.Cfg.Database.Retention
+	if cfg == nil || cfg.Interval <= 0 {
+		return
+	}
+
+	// Prune the rows of all the chains sharing the database
+	pruner, ok := ctx.Database.ForChain("").(database.Pruner)
+	if !ok {
+		ctx.Logger.Error("pruning is not supported by the database", "type", config.Cfg.Database.Type)
+		return
+	}
+
+	go func() {
+		for {
+			prunings, err := pruner.Prune(cfg)
+			for _, pruning := range prunings {
+				ctx.Logger.Info("pruned table", "chain_id", pruning.ChainID, "table", pruning.Table, "rows", pruning.Rows)
+			}
+			if err != nil {
+				ctx.Logger.Error("error while pruning", "err", err)
+			}
+
+			time.Sleep(cfg.Interval)
+		}
+	}()
+}
+
+// startChainParsing starts the workers parsing the chain of the given context,
+// and enqueues the heights to be parsed according to its configuration
+func startChainParsing(ctx *parser.Context) {
+	cfg := ctx.Config
+	logging.StartHeight.WithLabelValues(ctx.ChainID).Set(float64(cfg.StartHeight))
 
 	// Create a queue that will collect, aggregate, and export blocks and metadata
 	exportQueue := types.NewQueue(25)
@@ -55,18 +185,13 @@ func StartParsing(ctx *parser.Context) error {
 		workers[i] = parser.NewWorker(ctx, exportQueue, i)
 	}
 
-	waitGroup.Add(1)
-
 	// Start each blocking worker in a go-routine where the worker consumes jobs
 	// off of the export queue.
 	for i, w := range workers {
-		ctx.Logger.Debug("starting worker...", "number", i+1)
+		ctx.Logger.Debug("starting worker...", "chain_id", ctx.ChainID, "number", i+1)
 		go w.Start()
 	}
 
-	// Listen for and trap any OS signal to gracefully shutdown and exit
-	trapSignal(ctx)
-
 	if cfg.ParseGenesis {
 		// Add the genesis to the queue if requested
 		exportQueue <- 0
@@ -79,17 +204,13 @@ func StartParsing(ctx *parser.Context) error {
 	if cfg.ParseNewBlocks {
 		go enqueueNewBlocks(exportQueue, ctx)
 	}
-
-	// Block main process (signal capture will call WaitGroup's Done)
-	waitGroup.Wait()
-	return nil
 }
 
 // enqueueMissingBlocks enqueues jobs (block heights) for missed blocks starting
 // at the startHeight up until the latest known height.
 func enqueueMissingBlocks(exportQueue types.HeightQueue, ctx *parser.Context) {
 	// Get the config
-	cfg := config.Cfg.Parser
+	cfg := ctx.Config
 
 	// Get the latest height
 	latestBlockHeight, err := ctx.Node.LatestHeight()
@@ -98,9 +219,9 @@ func enqueueMissingBlocks(exportQueue types.HeightQueue, ctx *parser.Context) {
 	}
 
 	if cfg.FastSync {
-		ctx.Logger.Info("fast sync is enabled, ignoring all previous blocks", "latest_block_height", latestBlockHeight)
+		ctx.Logger.Info("fast sync is enabled, ignoring all previous blocks", "chain_id", ctx.ChainID, "latest_block_height", latestBlockHeight)
 	} else {
-		ctx.Logger.Info("syncing missing blocks...", "latest_block_height", latestBlockHeight)
+		ctx.Logger.Info("syncing missing blocks...", "chain_id", ctx.ChainID, "latest_block_height", latestBlockHeight)
 		for i := cfg.StartHeight; i <= latestBlockHeight; i++ {
 			ctx.Logger.Debug("enqueueing missing block", "height", i)
 			exportQueue <- i
@@ -127,13 +248,13 @@ func enqueueNewBlocks(exportQueue types.HeightQueue, ctx *parser.Context) {
 			ctx.Logger.Debug("enqueueing new block", "height", currHeight)
 			exportQueue <- currHeight
 		}
-		time.Sleep(config.Cfg.Parser.AvgBlockTime)
+		time.Sleep(ctx.Config.AvgBlockTime)
 	}
 }
 
 // trapSignal will listen for any OS signal and invoke Done on the main
 // WaitGroup allowing the main process to gracefully exit.
-func trapSignal(ctx *parser.Context) {
+func trapSignal(contexts []*parser.Context) {
 	var sigCh = make(chan os.Signal)
 
 	signal.Notify(sigCh, syscall.SIGTERM)
@@ -141,9 +262,12 @@ func trapSignal(ctx *parser.Context) {
 
 	go func() {
 		sig := <-sigCh
-		ctx.Logger.Info("caught signal; shutting down...", "signal", sig.String())
-		defer ctx.Node.Stop()
-		defer ctx.Database.Close()
+		contexts[0].Logger.Info("caught signal; shutting down...", "signal", sig.String())
+		for _, ctx := range contexts {
+			defer ctx.Node.Stop()
+		}
+		// All the contexts share the same database
+		defer contexts[0].Database.Close()
 		defer waitGroup.Done()
 	}()
 }
@@ -0,0 +1,283 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	parsecmdtypes "github.com/nuclearblock/archgregator/cmd/parse/types"
+	"github.com/nuclearblock/archgregator/database"
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+	"github.com/nuclearblock/archgregator/parser"
+	"github.com/nuclearblock/archgregator/types/config"
+)
+
+const (
+	flagFrom      = "from"
+	flagTo        = "to"
+	flagSample    = "sample"
+	flagChainID   = "chain-id"
+	flagReport    = "report"
+	flagReprocess = "reprocess"
+
+	// progressHeights is the number of verified heights after which the progress is reported
+	progressHeights = 1000
+)
+
+// NewVerifyCmd returns the Cobra command allowing to check that the database faithfully reflects the chain
+func NewVerifyCmd(parseConfig *parsecmdtypes.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "verify",
+		Short:   "Check that the stored data matches the one of the chain",
+		PreRunE: parsecmdtypes.ReadConfigPreRunE(parseConfig),
+		Long: fmt.Sprintf(`Compares the data stored for the heights between %[1]s and %[2]s (both included) with the one returned by the node.
+For each height, the hash, number of transactions and total gas of the stored block are checked, along with the
+code id, admin and label of the contracts instantiated and the hash of the codes stored at that height.
+By default all the heights are verified, while only a random sample of them is verified when %[3]s is set.
+All the mismatches found are reported, and written as JSON to the file given with %[4]s if set.
+When %[5]s is set, the records of each mismatched height are removed and its block is parsed again.
+`, flagFrom, flagTo, flagSample, flagReport, flagReprocess),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, _ := cmd.Flags().GetInt64(flagFrom)
+			to, _ := cmd.Flags().GetInt64(flagTo)
+			sample, _ := cmd.Flags().GetInt(flagSample)
+			chainID, _ := cmd.Flags().GetString(flagChainID)
+			reportPath, _ := cmd.Flags().GetString(flagReport)
+			reprocess, _ := cmd.Flags().GetBool(flagReprocess)
+
+			parseCtx, err := parsecmdtypes.GetChainParserContext(config.Cfg, parseConfig, chainID)
+			if err != nil {
+				return err
+			}
+			defer parseCtx.Database.Close()
+
+			db := parseCtx.Database.ForChain(parseCtx.ChainID)
+			reader, ok := db.(database.Reader)
+			if !ok {
+				return fmt.Errorf("reading the stored data is not supported by the %s database", config.Cfg.Database.Type)
+			}
+
+			// Get the start height, default to the config's height; use flagFrom if set
+			if from == 0 {
+				from = parseCtx.Config.StartHeight
+			}
+			if from < 1 {
+				from = 1
+			}
+
+			// Get the end height, default to the latest stored height; use flagTo if set
+			if to == 0 {
+				blocks, err := reader.GetBlocks(dbtypes.BlocksFilter{}, dbtypes.NewPagination(1, 0))
+				if err != nil {
+					return err
+				}
+				if len(blocks) == 0 {
+					return fmt.Errorf("no block stored for chain %s", parseCtx.ChainID)
+				}
+				to = blocks[0].Height
+			}
+
+			heights := getHeights(from, to, sample)
+			log.Info().Int64("from", from).Int64("to", to).Int("heights", len(heights)).Msg("verifying heights")
+
+			report := newReport(parseCtx.ChainID, from, to)
+			for i, height := range heights {
+				mismatches, err := verifyHeight(parseCtx, reader, height)
+				if err != nil {
+					log.Warn().Int64("height", height).Err(err).Msg("height could not be verified")
+					report.Unverified = append(report.Unverified, height)
+					continue
+				}
+
+				for _, m := range mismatches {
+					log.Warn().Int64("height", m.Height).Str("kind", m.Kind).Str("subject", m.Subject).
+						Str("field", m.Field).Str("stored", m.Stored).Str("node", m.Node).Msg("mismatch")
+				}
+				report.Mismatches = append(report.Mismatches, mismatches...)
+				report.Verified++
+
+				if (i+1)%progressHeights == 0 {
+					log.Info().Int("verified", i+1).Int("total", len(heights)).Msg("verifying heights")
+				}
+			}
+
+			mismatched := report.mismatchedHeights()
+			log.Info().Int("verified", report.Verified).Int("unverified", len(report.Unverified)).
+				Int("mismatches", len(report.Mismatches)).Int("mismatched heights", len(mismatched)).
+				Msg("heights verified")
+
+			if reportPath != "" {
+				bz, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("error while serializing report: %s", err)
+				}
+
+				err = ioutil.WriteFile(reportPath, bz, 0600)
+				if err != nil {
+					return fmt.Errorf("error while writing report: %s", err)
+				}
+			}
+
+			if !reprocess || len(mismatched) == 0 {
+				return nil
+			}
+
+			return reprocessHeights(parseCtx, db, mismatched)
+		},
+	}
+
+	cmd.Flags().Int64(flagFrom, 0, "Height from which to start verifying. If 0, the start height inside the config will be used instead")
+	cmd.Flags().Int64(flagTo, 0, "Height at which to finish verifying. If 0, the latest stored height will be used instead")
+	cmd.Flags().Int(flagSample, 0, "Number of random heights to verify. If 0, all the heights will be verified")
+	cmd.Flags().String(flagChainID, "", "Id of the chain to verify when multiple chains are configured. If empty, the first chain will be used")
+	cmd.Flags().String(flagReport, "", "Path of the file the JSON report should be written to")
+	cmd.Flags().Bool(flagReprocess, false, "Whether or not to parse again the mismatched heights (default false)")
+
+	return cmd
+}
+
+// getHeights returns the heights between from and to that should be verified, sorted in ascending order.
+// When sample is greater than 0, only that number of random heights is returned
+func getHeights(from, to int64, sample int) []int64 {
+	total := to - from + 1
+	if total <= 0 {
+		return nil
+	}
+
+	if sample <= 0 || int64(sample) >= total {
+		heights := make([]int64, 0, total)
+		for height := from; height <= to; height++ {
+			heights = append(heights, height)
+		}
+		return heights
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	picked := map[int64]bool{}
+	heights := make([]int64, 0, sample)
+	for len(heights) < sample {
+		height := from + random.Int63n(total)
+		if picked[height] {
+			continue
+		}
+		picked[height] = true
+		heights = append(heights, height)
+	}
+
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
+
+// verifyHeight compares the block, contracts and codes stored for the given height with the ones returned
+// by the node, and returns the mismatches found.
+// An error is returned if the data cannot be read from the database or the node
+func verifyHeight(ctx *parser.Context, reader database.Reader, height int64) ([]Mismatch, error) {
+	var mismatches []Mismatch
+
+	stored, err := reader.GetBlock(height)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := ctx.Node.Block(height)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting block from node: %s", err)
+	}
+
+	results, err := ctx.Node.BlockResults(height)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting block results from node: %s", err)
+	}
+
+	var totalGas int64
+	for _, result := range results.TxsResults {
+		totalGas += result.GasUsed
+	}
+
+	if stored == nil {
+		mismatches = append(mismatches, newMismatch(height, KindBlock, "", "presence", "missing", "present"))
+	} else {
+		mismatches = appendMismatch(mismatches, height, KindBlock, "", "hash", stored.Hash, block.Block.Hash().String())
+		mismatches = appendMismatch(mismatches, height, KindBlock, "", "num_txs",
+			strconv.Itoa(stored.TxNum), strconv.Itoa(len(block.Block.Txs)))
+		mismatches = appendMismatch(mismatches, height, KindBlock, "", "total_gas",
+			strconv.FormatInt(stored.TotalGas, 10), strconv.FormatInt(totalGas, 10))
+	}
+
+	codes, err := reader.GetWasmCodes(dbtypes.WasmCodesFilter{
+		HeightRange: dbtypes.HeightRange{FromHeight: height, ToHeight: height},
+	}, dbtypes.Pagination{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, code := range codes {
+		codeInfo, err := ctx.Node.GetCodeInfo(height, uint64(code.CodeID))
+		if err != nil {
+			return nil, err
+		}
+
+		mismatches = appendMismatch(mismatches, height, KindCode, strconv.FormatInt(code.CodeID, 10), "code_hash",
+			strings.ToUpper(code.CodeHash), codeInfo.DataHash.String())
+	}
+
+	contracts, err := reader.GetWasmContracts(dbtypes.WasmContractsFilter{
+		HeightRange: dbtypes.HeightRange{FromHeight: height, ToHeight: height},
+	}, dbtypes.Pagination{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, contract := range contracts {
+		contractInfo, err := ctx.Node.GetContractInfo(height, contract.ContractAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		mismatches = appendMismatch(mismatches, height, KindContract, contract.ContractAddress, "code_id",
+			strconv.FormatInt(contract.CodeID, 10), strconv.FormatUint(contractInfo.CodeID, 10))
+		mismatches = appendMismatch(mismatches, height, KindContract, contract.ContractAddress, "admin",
+			contract.Admin, contractInfo.Admin)
+		mismatches = appendMismatch(mismatches, height, KindContract, contract.ContractAddress, "label",
+			contract.Label, contractInfo.Label)
+	}
+
+	return mismatches, nil
+}
+
+// reprocessHeights removes the records stored for the given heights, and parses their blocks again
+func reprocessHeights(ctx *parser.Context, db database.Database, heights []int64) error {
+	remover, ok := db.(database.HeightRemover)
+	if !ok {
+		return fmt.Errorf("reprocessing heights is not supported by the %s database", config.Cfg.Database.Type)
+	}
+
+	worker := parser.NewWorker(ctx, nil, 0)
+	for _, height := range heights {
+		err := remover.RemoveHeight(height)
+		if err != nil {
+			return err
+		}
+
+		err = worker.Process(height)
+		if err != nil {
+			return fmt.Errorf("error while reprocessing block %d: %s", height, err)
+		}
+
+		log.Info().Int64("height", height).Msg("reprocessed height")
+	}
+
+	if config.Cfg.Database.Aggregates {
+		log.Info().Msg("the aggregates of the reprocessed heights should be rebuilt with the db rebuild-aggregates command")
+	}
+
+	return nil
+}
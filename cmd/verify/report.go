@@ -0,0 +1,81 @@
+package verify
+
+import (
+	"sort"
+	"time"
+)
+
+// Kinds of the records that are verified
+const (
+	KindBlock    = "block"
+	KindContract = "contract"
+	KindCode     = "code"
+)
+
+// Mismatch represents a value stored inside the database that differs from the one returned by the node
+type Mismatch struct {
+	Height  int64  `json:"height"`
+	Kind    string `json:"kind"`
+	Subject string `json:"subject,omitempty"`
+	Field   string `json:"field"`
+	Stored  string `json:"stored"`
+	Node    string `json:"node"`
+}
+
+// newMismatch allows to build a new Mismatch instance
+func newMismatch(height int64, kind, subject, field, stored, node string) Mismatch {
+	return Mismatch{
+		Height:  height,
+		Kind:    kind,
+		Subject: subject,
+		Field:   field,
+		Stored:  stored,
+		Node:    node,
+	}
+}
+
+// appendMismatch appends a new Mismatch to the given ones if the stored value differs from the node one
+func appendMismatch(mismatches []Mismatch, height int64, kind, subject, field, stored, node string) []Mismatch {
+	if stored == node {
+		return mismatches
+	}
+	return append(mismatches, newMismatch(height, kind, subject, field, stored, node))
+}
+
+// Report contains the results of the verification of a range of heights
+type Report struct {
+	ChainID    string     `json:"chain_id"`
+	From       int64      `json:"from"`
+	To         int64      `json:"to"`
+	Verified   int        `json:"verified"`
+	Unverified []int64    `json:"unverified"`
+	Mismatches []Mismatch `json:"mismatches"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// newReport allows to build a new empty Report instance
+func newReport(chainID string, from, to int64) *Report {
+	return &Report{
+		ChainID:    chainID,
+		From:       from,
+		To:         to,
+		Unverified: []int64{},
+		Mismatches: []Mismatch{},
+		CreatedAt:  time.Now().UTC(),
+	}
+}
+
+// mismatchedHeights returns the heights having at least one mismatch, sorted in ascending order
+func (r *Report) mismatchedHeights() []int64 {
+	found := map[int64]bool{}
+	var heights []int64
+	for _, m := range r.Mismatches {
+		if !found[m.Height] {
+			found[m.Height] = true
+			heights = append(heights, m.Height)
+		}
+	}
+
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
@@ -1,12 +1,26 @@
 package builder
 
 import (
+	"fmt"
+
 	"github.com/nuclearblock/archgregator/database"
+	databaseconfig "github.com/nuclearblock/archgregator/database/config"
+	"github.com/nuclearblock/archgregator/database/memory"
 	"github.com/nuclearblock/archgregator/database/postgresql"
+	"github.com/nuclearblock/archgregator/database/sqlite"
 )
 
 // Builder represents a generic Builder implementation that build the proper database
 // instance based on the configuration the user has specified
 func Builder(ctx *database.Context) (database.Database, error) {
-	return postgresql.Builder(ctx)
+	switch ctx.Cfg.Type {
+	case "", databaseconfig.TypePostgreSQL:
+		return postgresql.Builder(ctx)
+	case databaseconfig.TypeSQLite:
+		return sqlite.Builder(ctx)
+	case databaseconfig.TypeMemory:
+		return memory.Builder(ctx)
+	default:
+		return nil, fmt.Errorf("invalid database type: %s", ctx.Cfg.Type)
+	}
 }
@@ -1,6 +1,21 @@
 package config
 
+import "time"
+
+const (
+	// TypePostgreSQL identifies the PostgreSQL database, which is used when no type is specified
+	TypePostgreSQL = "postgresql"
+
+	// TypeSQLite identifies the SQLite database, stored inside the file at Path
+	TypeSQLite = "sqlite"
+
+	// TypeMemory identifies the in-memory database, whose data is lost once the process exits
+	TypeMemory = "memory"
+)
+
 type Config struct {
+	Type               string `yaml:"type,omitempty"`
+	Path               string `yaml:"path,omitempty"`
 	Name               string `yaml:"name"`
 	Host               string `yaml:"host"`
 	Port               int64  `yaml:"port"`
@@ -10,14 +25,32 @@ type Config struct {
 	Schema             string `yaml:"schema,omitempty"`
 	MaxOpenConnections int    `yaml:"max_open_connections"`
 	MaxIdleConnections int    `yaml:"max_idle_connections"`
+	Notify             bool   `yaml:"notify,omitempty"`
+
+	// Aggregates tells whether the aggregate tables of the postgresql database should be updated as each block is committed
+	Aggregates bool `yaml:"aggregates,omitempty"`
+
+	// Batch enables the batched writes of the postgresql database, which are disabled when not set
+	Batch *BatchConfig `yaml:"batch,omitempty"`
+
+	// Partitioning enables the partitioning of the postgresql execution and reward tables,
+	// which is disabled when not set
+	Partitioning *PartitioningConfig `yaml:"partitioning,omitempty"`
+
+	// Retention enables the pruning of the old rows, which is disabled when not set
+	Retention *RetentionConfig `yaml:"retention,omitempty"`
 }
 
 func NewDatabaseConfig(
+	dbType, path string,
 	name, host string, port int64, user string, password string,
 	sslMode string, schema string,
 	maxOpenConnections int, maxIdleConnections int,
+	notify bool, aggregates bool, batch *BatchConfig, partitioning *PartitioningConfig, retention *RetentionConfig,
 ) Config {
 	return Config{
+		Type:               dbType,
+		Path:               path,
 		Name:               name,
 		Host:               host,
 		Port:               port,
@@ -27,12 +60,19 @@ func NewDatabaseConfig(
 		Schema:             schema,
 		MaxOpenConnections: maxOpenConnections,
 		MaxIdleConnections: maxIdleConnections,
+		Notify:             notify,
+		Aggregates:         aggregates,
+		Batch:              batch,
+		Partitioning:       partitioning,
+		Retention:          retention,
 	}
 }
 
 // DefaultDatabaseConfig returns the default instance of Config
 func DefaultDatabaseConfig() Config {
 	return NewDatabaseConfig(
+		TypePostgreSQL,
+		"",
 		"archgregator",
 		"localhost",
 		5432,
@@ -42,5 +82,104 @@ func DefaultDatabaseConfig() Config {
 		"public",
 		1,
 		1,
+		false,
+		false,
+		nil,
+		nil,
+		nil,
 	)
 }
+
+// BatchConfig contains the configuration of the batched writes.
+// The rows of each committed block are buffered and written all together once Size blocks have been
+// committed, or FlushInterval has passed since the oldest buffered block has been committed.
+// When Copy is set, the rows of the tables having no unique constraint are written using COPY.
+type BatchConfig struct {
+	Size          int           `yaml:"size"`
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	Copy          bool          `yaml:"copy,omitempty"`
+}
+
+// NewBatchConfig allows to build a new BatchConfig instance
+func NewBatchConfig(size int, flushInterval time.Duration, copy bool) *BatchConfig {
+	return &BatchConfig{
+		Size:          size,
+		FlushInterval: flushInterval,
+		Copy:          copy,
+	}
+}
+
+// DefaultBatchConfig returns the default instance of BatchConfig
+func DefaultBatchConfig() *BatchConfig {
+	return NewBatchConfig(100, 5*time.Second, false)
+}
+
+const (
+	// PartitionByHeight splits the partitioned tables into ranges of Size heights
+	PartitionByHeight = "height"
+
+	// PartitionByMonth splits the partitioned tables by the month of their timestamp
+	PartitionByMonth = "month"
+)
+
+// PartitioningConfig contains the configuration of the tables partitioning.
+// The tables are partitioned By height or month, each partition containing Size heights when
+// partitioned by height, and Ahead partitions are created in advance of the ones being written.
+type PartitioningConfig struct {
+	By    string `yaml:"by"`
+	Size  int64  `yaml:"size,omitempty"`
+	Ahead int    `yaml:"ahead"`
+}
+
+// NewPartitioningConfig allows to build a new PartitioningConfig instance
+func NewPartitioningConfig(by string, size int64, ahead int) *PartitioningConfig {
+	return &PartitioningConfig{
+		By:    by,
+		Size:  size,
+		Ahead: ahead,
+	}
+}
+
+// DefaultPartitioningConfig returns the default instance of PartitioningConfig
+func DefaultPartitioningConfig() *PartitioningConfig {
+	return NewPartitioningConfig(PartitionByHeight, 1000000, 2)
+}
+
+// RetentionConfig contains the retention policy of each table, indexed by the table name.
+// The rows exceeding the retention are removed in batches of BatchSize rows, every Interval when
+// the pruning runs in background alongside the parsing.
+type RetentionConfig struct {
+	Tables    map[string]TableRetentionConfig `yaml:"tables"`
+	BatchSize int                             `yaml:"batch_size"`
+	Interval  time.Duration                   `yaml:"interval,omitempty"`
+}
+
+// NewRetentionConfig allows to build a new RetentionConfig instance
+func NewRetentionConfig(tables map[string]TableRetentionConfig, batchSize int, interval time.Duration) *RetentionConfig {
+	return &RetentionConfig{
+		Tables:    tables,
+		BatchSize: batchSize,
+		Interval:  interval,
+	}
+}
+
+// DefaultRetentionConfig returns the default instance of RetentionConfig
+func DefaultRetentionConfig() *RetentionConfig {
+	return NewRetentionConfig(nil, 10000, time.Hour)
+}
+
+// TableRetentionConfig contains the retention policy of a single table.
+// A row exceeds the retention once it is older than MaxAge, or more than MaxHeights heights
+// have been indexed after its own. Each limit is ignored when not set.
+type TableRetentionConfig struct {
+	MaxAge     time.Duration `yaml:"max_age,omitempty"`
+	MaxHeights int64         `yaml:"max_heights,omitempty"`
+}
+
+// NewTableRetentionConfig allows to build a new TableRetentionConfig instance
+func NewTableRetentionConfig(maxAge time.Duration, maxHeights int64) TableRetentionConfig {
+	return TableRetentionConfig{
+		MaxAge:     maxAge,
+		MaxHeights: maxHeights,
+	}
+}
@@ -1,12 +1,15 @@
 package database
 
 import (
+	"time"
+
 	//"github.com/cosmos/cosmos-sdk/simapp/params"
 	"github.com/archway-network/archway/app/params"
 
 	"github.com/nuclearblock/archgregator/logging"
 
 	databaseconfig "github.com/nuclearblock/archgregator/database/config"
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
 
 	"github.com/nuclearblock/archgregator/types"
 )
@@ -48,10 +51,161 @@ type Database interface {
 	// An error is returned if the operation fails.
 	SaveGasTrackerContractMetadata(gastrackerContractMetadata types.GasTrackerContractMetadata) error
 
+	// HasCw20Token tells whether or not the contract having the given address has been stored as a CW20 token.
+	// An error is returned if the operation fails.
+	HasCw20Token(contractAddress string) (bool, error)
+
+	// SaveCw20Token stores a contract detected to be a CW20 token, unless it has already been stored.
+	// An error is returned if the operation fails.
+	SaveCw20Token(token types.Cw20Token) error
+
+	// SaveCw20Transfer stores a single movement of CW20 tokens, updating the balances of the involved addresses.
+	// Transfers that have already been stored are skipped, without updating the balances again.
+	// An error is returned if the operation fails.
+	SaveCw20Transfer(transfer types.Cw20Transfer) error
+
+	// SaveNotificationDelivery stores a single attempt of delivering a webhook notification.
+	// An error is returned if the operation fails.
+	SaveNotificationDelivery(delivery types.NotificationDelivery) error
+
+	// SaveContractStateSnapshot stores the state of a contract at a given height.
+	// An error is returned if the operation fails.
+	SaveContractStateSnapshot(snapshot types.ContractStateSnapshot) error
+
+	// GetChainInfo returns the identity of the chain the stored data belongs to,
+	// or nil if it has not been stored yet.
+	// An error is returned if the operation fails.
+	GetChainInfo() (*types.ChainInfo, error)
+
+	// SaveChainInfo stores the identity of the chain the stored data belongs to.
+	// An error is returned if the operation fails.
+	SaveChainInfo(info types.ChainInfo) error
+
+	// CommitBlock will be called once all the data contained inside the given block has been saved.
+	// An error is returned if the operation fails.
+	CommitBlock(block *types.Block) error
+
+	// ForChain returns a Database sharing the same connection that stores all the data as belonging to the
+	// chain having the given id, and only reads back the data of that chain.
+	ForChain(chainID string) Database
+
 	// Close closes the connection to the database
 	Close()
 }
 
+// Reader represents a database that allows to read back the data that has been stored inside it.
+// Each method returning a list of rows sorts them from the most recent to the oldest one.
+type Reader interface {
+	// GetBlock returns the block having the given height, or nil if no such block exists.
+	// An error is returned if the operation fails.
+	GetBlock(height int64) (*dbtypes.BlockRow, error)
+
+	// GetBlocks returns the blocks matching the given filter.
+	// An error is returned if the operation fails.
+	GetBlocks(filter dbtypes.BlocksFilter, pagination dbtypes.Pagination) ([]dbtypes.BlockRow, error)
+
+	// GetWasmCode returns the wasm code having the given id, or nil if no such code exists.
+	// An error is returned if the operation fails.
+	GetWasmCode(codeID int64) (*dbtypes.WasmCodeRow, error)
+
+	// GetWasmCodes returns the wasm codes matching the given filter.
+	// An error is returned if the operation fails.
+	GetWasmCodes(filter dbtypes.WasmCodesFilter, pagination dbtypes.Pagination) ([]dbtypes.WasmCodeRow, error)
+
+	// GetWasmContract returns the wasm contract having the given address, or nil if no such contract exists.
+	// An error is returned if the operation fails.
+	GetWasmContract(address string) (*dbtypes.WasmContractRow, error)
+
+	// GetWasmContracts returns the wasm contracts matching the given filter.
+	// An error is returned if the operation fails.
+	GetWasmContracts(filter dbtypes.WasmContractsFilter, pagination dbtypes.Pagination) ([]dbtypes.WasmContractRow, error)
+
+	// GetWasmExecuteContracts returns the wasm contract executions matching the given filter.
+	// An error is returned if the operation fails.
+	GetWasmExecuteContracts(filter dbtypes.WasmExecuteContractsFilter, pagination dbtypes.Pagination) ([]dbtypes.WasmExecuteContractRow, error)
+
+	// GetContractMetadata returns the gastracker contract metadata matching the given filter.
+	// An error is returned if the operation fails.
+	GetContractMetadata(filter dbtypes.ContractMetadataFilter, pagination dbtypes.Pagination) ([]dbtypes.ContractMetadataRow, error)
+
+	// GetContractRewards returns the gastracker contract rewards matching the given filter.
+	// An error is returned if the operation fails.
+	GetContractRewards(filter dbtypes.ContractRewardsFilter, pagination dbtypes.Pagination) ([]dbtypes.ContractRewardRow, error)
+}
+
+// BulkLoader represents a database that can speed up the loading of the whole history of a chain
+// by deferring the maintenance of its secondary indexes until the loading has completed.
+type BulkLoader interface {
+	// DeferIndexes drops the secondary indexes that are not needed while loading, remembering them
+	// so that they can be rebuilt later, and returns their names.
+	// An error is returned if the operation fails.
+	DeferIndexes() ([]string, error)
+
+	// RebuildIndexes writes any buffered record and creates again all the deferred indexes, calling progress
+	// each time one of them has been rebuilt.
+	// An error is returned if the operation fails.
+	RebuildIndexes(progress func(index string, done, total int)) error
+}
+
+// Pruner represents a database that allows to remove the rows exceeding the retention of their tables
+type Pruner interface {
+	// Prune removes the rows of each chain exceeding the given retention, recording and returning
+	// the details of each pruning.
+	// An error is returned if the operation fails.
+	Prune(cfg *databaseconfig.RetentionConfig) ([]types.Pruning, error)
+}
+
+// Aggregator represents a database that maintains aggregate tables derived from the stored records
+type Aggregator interface {
+	// RebuildAggregates computes again all the aggregates of the period between from (included) and to (excluded)
+	// starting from the stored records.
+	// An error is returned if the operation fails.
+	RebuildAggregates(from, to time.Time) error
+}
+
+// HeightRemover represents a database that allows to remove all the records stored for a height,
+// so that its block can be parsed again from scratch
+type HeightRemover interface {
+	// RemoveHeight removes the block having the given height along with all the records it contained.
+	// An error is returned if the operation fails.
+	RemoveHeight(height int64) error
+}
+
+// RewardsStreamer represents a database that can read the gastracker contract rewards of each reward address in
+// order, without loading all of them at once
+type RewardsStreamer interface {
+	// StreamContractRewards calls fn with each gastracker contract reward matching the given filter, sorted by
+	// reward address, height and id. The rows are read in pages, and fn is only called once the page containing
+	// its row has been read, so that fn can query the database as well.
+	// The iteration stops at the first error returned by fn, which is returned.
+	StreamContractRewards(filter dbtypes.ContractRewardsFilter, fn func(row dbtypes.ContractRewardRow) error) error
+}
+
+// Batcher represents a database that might write the records of a committed block later on, together with the
+// ones of other blocks
+type Batcher interface {
+	// CommitBlockAsync behaves like CommitBlock, but calls done once the records of the block have been written,
+	// or with the error that prevented them from being written, in which case the block must be processed again.
+	// The done function must neither block nor use the database.
+	// When the records are written right away and the operation fails, the error is returned and done is not called.
+	CommitBlockAsync(block *types.Block, done func(err error)) error
+}
+
+// ReaderForChain returns a Reader that only reads the data of the chain having the given id.
+// If the id is empty, or the given reader does not support being scoped to a chain, the reader itself is returned.
+func ReaderForChain(reader Reader, chainID string) Reader {
+	db, ok := reader.(Database)
+	if chainID == "" || !ok {
+		return reader
+	}
+
+	scoped, ok := db.ForChain(chainID).(Reader)
+	if !ok {
+		return reader
+	}
+	return scoped
+}
+
 // Context contains the data that might be used to build a Database instance
 type Context struct {
 	Cfg            databaseconfig.Config
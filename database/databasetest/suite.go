@@ -0,0 +1,361 @@
+// Package databasetest contains the behavior tests shared by all the database.Database implementations
+package databasetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	gastrackertypes "github.com/archway-network/archway/x/gastracker/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nuclearblock/archgregator/database"
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// timestamp is the time of all the test records, truncated to the precision supported by all the databases
+var timestamp = time.Date(2022, 5, 10, 12, 30, 15, 0, time.UTC)
+
+// RunSuite runs all the behavior tests against the database returned by newDB, which must implement
+// database.Reader. Each test stores its records for a different chain, so that the same database can be
+// shared by all of them.
+func RunSuite(t *testing.T, newDB func(t *testing.T) database.Database) {
+	tests := map[string]func(t *testing.T, db database.Database, reader database.Reader){
+		"block round trip":          testBlockRoundTrip,
+		"wasm records round trip":   testWasmRoundTrip,
+		"gastracker round trip":     testGasTrackerRoundTrip,
+		"idempotent re-save":        testIdempotentResave,
+		"chain scoped reads":        testChainScopedReads,
+		"uncommitted block missing": testUncommittedBlock,
+		"rewards streamed in order": testRewardsStream,
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			db := newDB(t).ForChain(chainID(t))
+			reader, ok := db.(database.Reader)
+			require.True(t, ok, "database must implement database.Reader")
+
+			test(t, db, reader)
+		})
+	}
+}
+
+// chainID returns a chain id that is unique to the given test and run
+func chainID(t *testing.T) string {
+	return t.Name() + "-" + time.Now().Format("150405.000000000")
+}
+
+// block returns the test block having the given height
+func block(height int64) *types.Block {
+	return types.NewBlock(height, fmt.Sprintf("HASH%d", height), 2, 150000, timestamp)
+}
+
+// saveBlock saves and commits the given block along with the records stored by save
+func saveBlock(t *testing.T, db database.Database, b *types.Block, save func()) {
+	require.NoError(t, db.SaveBlock(b))
+	if save != nil {
+		save()
+	}
+	require.NoError(t, db.CommitBlock(b))
+}
+
+// coins returns the coins used by the test records
+func coins() sdk.Coins {
+	return sdk.NewCoins(sdk.NewInt64Coin("stake", 25), sdk.NewInt64Coin("utorii", 1000))
+}
+
+// wasmExecution returns a test contract execution at the given height
+func wasmExecution(height int64) types.WasmExecuteContract {
+	return types.WasmExecuteContract{
+		Sender:          "archway1sender",
+		ContractAddress: "archway1contract",
+		RawContractMsg:  json.RawMessage(`{"transfer":{"amount":"10","recipient":"archway1recipient"}}`),
+		Funds:           coins(),
+		GasUsed:         120000,
+		Fees:            sdk.NewCoins(sdk.NewInt64Coin("utorii", 3500)),
+		TxHash:          "TXHASH",
+		ExecutedAt:      timestamp,
+		Height:          height,
+	}
+}
+
+// rewardCalculation returns a test reward calculation of the given contract at the given height
+func rewardCalculation(contractAddress string, height int64) types.ContractRewardCalculation {
+	contractRewards := sdk.NewDecCoinFromDec("utorii", sdk.MustNewDecFromStr("12.5"))
+	inflationRewards := sdk.NewDecCoinFromDec("utorii", sdk.MustNewDecFromStr("3.25"))
+	return types.NewContractRewardCalculation(
+		contractAddress, "archway1reward", "archway1developer", 80000,
+		[]*sdk.DecCoin{&contractRewards}, &inflationRewards, true, false, 10, timestamp, height,
+	)
+}
+
+// contractMetadata returns a test metadata of the contract at the given height
+func contractMetadata(rewardAddress string, height int64) types.GasTrackerContractMetadata {
+	return types.GasTrackerContractMetadata{
+		Sender:          "archway1developer",
+		ContractAddress: "archway1contract",
+		Metadata: gastrackertypes.ContractInstanceMetadata{
+			DeveloperAddress:         "archway1developer",
+			RewardAddress:            rewardAddress,
+			GasRebateToUser:          true,
+			CollectPremium:           true,
+			PremiumPercentageCharged: 20,
+		},
+		TxHash:  "TXHASH",
+		SavedAt: timestamp,
+		Height:  height,
+	}
+}
+
+func testBlockRoundTrip(t *testing.T, db database.Database, reader database.Reader) {
+	found, err := db.HasBlock(10)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	b := block(10)
+	saveBlock(t, db, b, nil)
+
+	found, err = db.HasBlock(10)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	row, err := reader.GetBlock(10)
+	require.NoError(t, err)
+	require.NotNil(t, row)
+	require.Equal(t, b.Height, row.Height)
+	require.Equal(t, b.Hash, row.Hash)
+	require.Equal(t, b.TxNum, row.TxNum)
+	require.Equal(t, int64(b.TotalGas), row.TotalGas)
+	require.True(t, b.Timestamp.Equal(row.Timestamp), "expected %s, got %s", b.Timestamp, row.Timestamp)
+}
+
+func testWasmRoundTrip(t *testing.T, db database.Database, reader database.Reader) {
+	code := types.NewWasmCode(7, "archway1creator", 1024, "CODEHASH", "TXHASH", timestamp, 20)
+	contract := types.WasmContract{
+		Sender:          "archway1creator",
+		Creator:         "archway1creator",
+		Admin:           "archway1admin",
+		CodeID:          7,
+		Label:           "Test contract",
+		RawContractMsg:  json.RawMessage(`{"name":"Token","decimals":6,"initial_balances":[]}`),
+		Funds:           coins(),
+		ContractAddress: "archway1contract",
+		TxHash:          "TXHASH",
+		InstantiatedAt:  timestamp,
+		Height:          20,
+	}
+	execution := wasmExecution(20)
+
+	saveBlock(t, db, block(20), func() {
+		require.NoError(t, db.SaveWasmCode(code))
+		require.NoError(t, db.SaveWasmContract(contract))
+		require.NoError(t, db.SaveWasmExecuteContract(execution))
+	})
+
+	codeRow, err := reader.GetWasmCode(7)
+	require.NoError(t, err)
+	require.NotNil(t, codeRow)
+	require.Equal(t, code.Creator, codeRow.Creator)
+	require.Equal(t, code.CodeHash, codeRow.CodeHash)
+	require.Equal(t, code.Size, codeRow.Size)
+	require.Equal(t, code.Height, codeRow.Height)
+	require.True(t, code.SavedAt.Equal(codeRow.SavedAt))
+
+	contractRow, err := reader.GetWasmContract(contract.ContractAddress)
+	require.NoError(t, err)
+	require.NotNil(t, contractRow)
+	require.Equal(t, contract.Admin, contractRow.Admin)
+	require.Equal(t, int64(contract.CodeID), contractRow.CodeID)
+	require.Equal(t, contract.Label, contractRow.Label)
+	require.JSONEq(t, string(contract.RawContractMsg), string(contractRow.RawContractMessage))
+	require.True(t, dbtypes.NewDbCoins(contract.Funds).Equal(&contractRow.Funds), "unexpected funds %v", contractRow.Funds)
+	require.True(t, contract.InstantiatedAt.Equal(contractRow.InstantiatedAt))
+
+	executions, err := reader.GetWasmExecuteContracts(dbtypes.WasmExecuteContractsFilter{
+		ContractAddress: contract.ContractAddress,
+	}, dbtypes.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	require.Equal(t, execution.Sender, executions[0].Sender)
+	require.Equal(t, execution.GasUsed, executions[0].GasUsed)
+	require.Equal(t, "utorii", executions[0].FeesDenom)
+	require.Equal(t, float64(3500), executions[0].FeesAmount)
+	require.Equal(t, execution.TxHash, executions[0].TxHash)
+	require.JSONEq(t, string(execution.RawContractMsg), string(executions[0].RawContractMessage))
+	require.True(t, dbtypes.NewDbCoins(execution.Funds).Equal(&executions[0].Funds), "unexpected funds %v", executions[0].Funds)
+	require.True(t, execution.ExecutedAt.Equal(executions[0].ExecutedAt))
+}
+
+func testGasTrackerRoundTrip(t *testing.T, db database.Database, reader database.Reader) {
+	metadata := contractMetadata("archway1reward", 30)
+	reward := rewardCalculation("archway1contract", 30)
+	otherReward := rewardCalculation("archway1other", 30)
+	distributed := sdk.NewInt64Coin("utorii", 15)
+	leftover := sdk.NewDecCoinFromDec("utorii", sdk.MustNewDecFromStr("0.75"))
+
+	saveBlock(t, db, block(31), func() {
+		require.NoError(t, db.SaveGasTrackerContractMetadata(metadata))
+		require.NoError(t, db.SaveContractRewardCalculation(reward))
+		require.NoError(t, db.SaveContractRewardCalculation(otherReward))
+		require.NoError(t, db.SaveContractRewardDistribution(types.NewContractRewardDistribution(
+			"archway1reward", []*sdk.Coin{&distributed}, []*sdk.DecCoin{&leftover}, 30,
+		)))
+	})
+
+	metadataRows, err := reader.GetContractMetadata(dbtypes.ContractMetadataFilter{
+		ContractAddress: metadata.ContractAddress,
+	}, dbtypes.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, metadataRows, 1)
+	require.Equal(t, metadata.Metadata.RewardAddress, metadataRows[0].RewardAddress)
+	require.Equal(t, metadata.Metadata.DeveloperAddress, metadataRows[0].DeveloperAddress)
+	require.True(t, metadataRows[0].CollectPremium)
+	require.True(t, metadataRows[0].GasRebateToUser)
+	require.Equal(t, int64(20), metadataRows[0].PremiumPercentageCharged)
+	require.Equal(t, int64(30), metadataRows[0].Height)
+
+	rewardRows, err := reader.GetContractRewards(dbtypes.ContractRewardsFilter{
+		RewardAddress: "archway1reward",
+	}, dbtypes.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, rewardRows, 2)
+	for _, row := range rewardRows {
+		require.Equal(t, "80000", row.GasConsumed)
+		require.Equal(t, "utorii", row.ContractRewardsDenom)
+		require.Equal(t, 12.5, row.ContractRewardsAmount)
+		require.Equal(t, 3.25, row.InflationRewardsAmount)
+		require.Equal(t, float64(15), row.DistributedRewardsAmount)
+		require.Equal(t, 0.75, row.LeftoverRewardsAmount)
+		require.True(t, row.GasRebateToUser)
+		require.False(t, row.CollectPremium)
+		require.Equal(t, int64(10), row.PremiumPercentageCharged)
+		require.True(t, timestamp.Equal(row.RewardDate))
+	}
+}
+
+func testIdempotentResave(t *testing.T, db database.Database, reader database.Reader) {
+	save := func() {
+		require.NoError(t, db.SaveWasmCode(types.NewWasmCode(8, "archway1creator", 512, "CODEHASH", "TXHASH", timestamp, 40)))
+		require.NoError(t, db.SaveWasmExecuteContract(wasmExecution(40)))
+		require.NoError(t, db.SaveWasmExecuteContract(wasmExecution(40)))
+		require.NoError(t, db.SaveGasTrackerContractMetadata(contractMetadata("archway1reward", 40)))
+		require.NoError(t, db.SaveContractRewardCalculation(rewardCalculation("archway1contract", 39)))
+	}
+
+	// The same block is written twice, as it happens when it is parsed again
+	saveBlock(t, db, block(40), save)
+	saveBlock(t, db, block(40), save)
+
+	blocks, err := reader.GetBlocks(dbtypes.BlocksFilter{}, dbtypes.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+
+	codes, err := reader.GetWasmCodes(dbtypes.WasmCodesFilter{}, dbtypes.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, codes, 1)
+
+	// Identical executions of the same block are all kept
+	executions, err := reader.GetWasmExecuteContracts(dbtypes.WasmExecuteContractsFilter{}, dbtypes.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, executions, 2)
+
+	metadata, err := reader.GetContractMetadata(dbtypes.ContractMetadataFilter{}, dbtypes.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, metadata, 1)
+
+	rewards, err := reader.GetContractRewards(dbtypes.ContractRewardsFilter{}, dbtypes.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, rewards, 1)
+}
+
+func testChainScopedReads(t *testing.T, db database.Database, reader database.Reader) {
+	saveBlock(t, db, block(50), func() {
+		require.NoError(t, db.SaveWasmExecuteContract(wasmExecution(50)))
+	})
+
+	other := db.ForChain(chainID(t) + "-other")
+	saveBlock(t, other, block(50), func() {
+		require.NoError(t, other.SaveWasmExecuteContract(wasmExecution(50)))
+	})
+
+	found, err := other.HasBlock(50)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	blocks, err := reader.GetBlocks(dbtypes.BlocksFilter{}, dbtypes.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+
+	executions, err := reader.GetWasmExecuteContracts(dbtypes.WasmExecuteContractsFilter{}, dbtypes.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+}
+
+func testUncommittedBlock(t *testing.T, db database.Database, reader database.Reader) {
+	b := block(60)
+	require.NoError(t, db.SaveBlock(b))
+	require.NoError(t, db.SaveWasmExecuteContract(wasmExecution(60)))
+
+	// A block is only reported as stored once all its records are written
+	found, err := db.HasBlock(60)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, db.CommitBlock(b))
+
+	found, err = db.HasBlock(60)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	executions, err := reader.GetWasmExecuteContracts(dbtypes.WasmExecuteContractsFilter{}, dbtypes.Pagination{})
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+}
+
+func testRewardsStream(t *testing.T, db database.Database, _ database.Reader) {
+	streamer, ok := db.(database.RewardsStreamer)
+	if !ok {
+		t.Skip("database does not implement database.RewardsStreamer")
+	}
+
+	// Each block contains the rewards of the previous height, stored in a different order than the streamed one
+	for height := int64(30); height <= 32; height++ {
+		saveBlock(t, db, block(height+1), func() {
+			for _, rewardAddress := range []string{"archway1rewardb", "archway1rewarda"} {
+				for _, contractAddress := range []string{"archway1contractb", "archway1contracta"} {
+					calculation := rewardCalculation(contractAddress, height)
+					calculation.RewardAddress = rewardAddress
+					require.NoError(t, db.SaveContractRewardCalculation(calculation))
+				}
+			}
+		})
+	}
+
+	var streamed []string
+	filter := dbtypes.ContractRewardsFilter{HeightRange: dbtypes.HeightRange{FromHeight: 31, ToHeight: 32}}
+	err := streamer.StreamContractRewards(filter, func(row dbtypes.ContractRewardRow) error {
+		streamed = append(streamed, fmt.Sprintf("%s/%d/%s", row.RewardAddress, row.Height, row.ContractAddress))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"archway1rewarda/31/archway1contractb", "archway1rewarda/31/archway1contracta",
+		"archway1rewarda/32/archway1contractb", "archway1rewarda/32/archway1contracta",
+		"archway1rewardb/31/archway1contractb", "archway1rewardb/31/archway1contracta",
+		"archway1rewardb/32/archway1contractb", "archway1rewardb/32/archway1contracta",
+	}, streamed)
+
+	// The iteration stops at the first error
+	calls := 0
+	err = streamer.StreamContractRewards(filter, func(row dbtypes.ContractRewardRow) error {
+		calls++
+		return fmt.Errorf("stop")
+	})
+	require.EqualError(t, err, "stop")
+	require.Equal(t, 1, calls)
+}
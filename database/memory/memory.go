@@ -0,0 +1,855 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/nuclearblock/archgregator/database"
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// Builder returns a new empty in-memory database
+func Builder(_ *database.Context) (database.Database, error) {
+	return NewDatabase(), nil
+}
+
+// type check to ensure interface is properly implemented
+var (
+	_ database.Database        = &Database{}
+	_ database.Reader          = &Database{}
+	_ database.RewardsStreamer = &Database{}
+)
+
+// Database represents a database that keeps all the data in memory, mimicking the behavior of the SQL ones.
+// It is meant to be used while testing and developing, since the data is lost once the process exits.
+type Database struct {
+	*state
+
+	// chainID is the id of the chain all the data is stored for and read from.
+	// When empty, the data of all the chains is read
+	chainID string
+
+	// pending contains the records of the block being processed that are only stored once it is committed.
+	// It is nil when no block is being processed
+	pending *blockRows
+}
+
+// blockRows contains the records of a block that are stored when the block is committed
+type blockRows struct {
+	block         *types.Block
+	executions    []types.WasmExecuteContract
+	rewards       []types.ContractRewardCalculation
+	distributions []types.ContractRewardDistribution
+	metadata      []types.GasTrackerContractMetadata
+}
+
+// state contains the data shared by all the Database instances scoped to different chains
+type state struct {
+	mu sync.RWMutex
+
+	blocks     []dbtypes.BlockRow
+	codes      []dbtypes.WasmCodeRow
+	contracts  []dbtypes.WasmContractRow
+	executions []dbtypes.WasmExecuteContractRow
+	metadata   []dbtypes.ContractMetadataRow
+	rewards    []dbtypes.ContractRewardRow
+	deliveries []types.NotificationDelivery
+	snapshots  []types.ContractStateSnapshot
+	cw20Tokens []cw20TokenRow
+	transfers  []cw20TransferRow
+	balances   map[cw20BalanceKey]sdk.Int
+	committed  []int64
+	chainInfos []types.ChainInfo
+
+	// lastID is the id of the last row stored inside the tables having no unique key
+	lastID int64
+}
+
+// cw20TokenRow represents a CW20 token stored for a chain
+type cw20TokenRow struct {
+	chainID string
+	token   types.Cw20Token
+}
+
+// cw20TransferRow represents a CW20 transfer stored for a chain
+type cw20TransferRow struct {
+	chainID  string
+	transfer types.Cw20Transfer
+}
+
+// cw20BalanceKey identifies the balance of an address for a CW20 token of a chain
+type cw20BalanceKey struct {
+	chainID         string
+	contractAddress string
+	address         string
+}
+
+// NewDatabase returns a new empty Database instance
+func NewDatabase() *Database {
+	return &Database{state: &state{balances: map[cw20BalanceKey]sdk.Int{}}}
+}
+
+// ForChain implements database.Database
+func (db *Database) ForChain(chainID string) database.Database {
+	return &Database{state: db.state, chainID: chainID}
+}
+
+// inChain tells whether a row belonging to the given chain is visible from this database
+func (db *Database) inChain(chainID string) bool {
+	return db.chainID == "" || chainID == db.chainID
+}
+
+// parseAmount converts the given decimal amount to a float, just like the SQL databases do
+func parseAmount(amount string) float64 {
+	value, _ := strconv.ParseFloat(amount, 64)
+	return value
+}
+
+// rawMessage returns a copy of the given raw contract message, using an empty object if it is not set
+func rawMessage(msg json.RawMessage) json.RawMessage {
+	if len(msg) == 0 {
+		return json.RawMessage("{}")
+	}
+	return append(json.RawMessage{}, msg...)
+}
+
+// HasBlock implements database.Database
+func (db *Database) HasBlock(height int64) (bool, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, block := range db.blocks {
+		if block.ChainID == db.chainID && block.Height == height {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SaveBlock implements database.Database.
+// The block is only stored when it is committed, along with the records that have no unique key.
+func (db *Database) SaveBlock(block *types.Block) error {
+	db.pending = &blockRows{block: block}
+	return nil
+}
+
+// insertBlock stores the given block, unless it is stored already. The caller must hold the write lock.
+func (db *Database) insertBlock(block *types.Block) {
+	for _, row := range db.blocks {
+		if row.ChainID == db.chainID && (row.Height == block.Height || row.Hash == block.Hash) {
+			return
+		}
+	}
+
+	db.blocks = append(db.blocks, dbtypes.BlockRow{
+		ChainID:   db.chainID,
+		Height:    block.Height,
+		Hash:      block.Hash,
+		TxNum:     block.TxNum,
+		TotalGas:  int64(block.TotalGas),
+		Timestamp: block.Timestamp,
+	})
+}
+
+// SaveWasmCode implements database.Database
+func (db *Database) SaveWasmCode(wasmCode types.WasmCode) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, row := range db.codes {
+		if row.ChainID == db.chainID && row.CodeID == int64(wasmCode.CodeID) {
+			return nil
+		}
+	}
+
+	db.codes = append(db.codes, dbtypes.WasmCodeRow{
+		ChainID:  db.chainID,
+		Creator:  wasmCode.Creator,
+		CodeHash: wasmCode.CodeHash,
+		CodeID:   int64(wasmCode.CodeID),
+		Size:     wasmCode.Size,
+		TxHash:   wasmCode.TxHash,
+		SavedAt:  wasmCode.SavedAt,
+		Height:   wasmCode.Height,
+	})
+	return nil
+}
+
+// SaveWasmContract implements database.Database
+func (db *Database) SaveWasmContract(wasmContract types.WasmContract) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, row := range db.contracts {
+		if row.ChainID == db.chainID && row.ContractAddress == wasmContract.ContractAddress {
+			return nil
+		}
+	}
+
+	db.contracts = append(db.contracts, dbtypes.WasmContractRow{
+		ChainID:            db.chainID,
+		Sender:             wasmContract.Sender,
+		Creator:            wasmContract.Creator,
+		Admin:              wasmContract.Admin,
+		CodeID:             int64(wasmContract.CodeID),
+		Label:              wasmContract.Label,
+		RawContractMessage: rawMessage(wasmContract.RawContractMsg),
+		Funds:              dbtypes.NewDbCoins(wasmContract.Funds),
+		ContractAddress:    wasmContract.ContractAddress,
+		TxHash:             wasmContract.TxHash,
+		InstantiatedAt:     wasmContract.InstantiatedAt,
+		Height:             wasmContract.Height,
+	})
+	return nil
+}
+
+// SaveWasmExecuteContract implements database.Database
+func (db *Database) SaveWasmExecuteContract(executeContract types.WasmExecuteContract) error {
+	if db.pending != nil {
+		db.pending.executions = append(db.pending.executions, executeContract)
+		return nil
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.insertWasmExecuteContract(executeContract)
+	return nil
+}
+
+// insertWasmExecuteContract stores the given contract execution. The caller must hold the write lock.
+func (db *Database) insertWasmExecuteContract(executeContract types.WasmExecuteContract) {
+	denom := "utorii"
+	db.lastID++
+	db.executions = append(db.executions, dbtypes.WasmExecuteContractRow{
+		ID:                 db.lastID,
+		ChainID:            db.chainID,
+		Sender:             executeContract.Sender,
+		ContractAddress:    executeContract.ContractAddress,
+		RawContractMessage: rawMessage(executeContract.RawContractMsg),
+		Funds:              dbtypes.NewDbCoins(executeContract.Funds),
+		GasUsed:            executeContract.GasUsed,
+		FeesDenom:          denom,
+		FeesAmount:         parseAmount(executeContract.Fees.AmountOf(denom).String()),
+		TxHash:             executeContract.TxHash,
+		ExecutedAt:         executeContract.ExecutedAt,
+		Height:             executeContract.Height,
+	})
+}
+
+// SaveContractRewardCalculation implements database.Database
+func (db *Database) SaveContractRewardCalculation(contractRewardCalculation types.ContractRewardCalculation) error {
+	if db.pending != nil {
+		db.pending.rewards = append(db.pending.rewards, contractRewardCalculation)
+		return nil
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.insertContractRewardCalculation(contractRewardCalculation)
+	return nil
+}
+
+// insertContractRewardCalculation stores the given reward calculation. The caller must hold the write lock.
+func (db *Database) insertContractRewardCalculation(contractRewardCalculation types.ContractRewardCalculation) {
+	db.lastID++
+	db.rewards = append(db.rewards, dbtypes.ContractRewardRow{
+		ID:                       db.lastID,
+		ChainID:                  db.chainID,
+		ContractAddress:          contractRewardCalculation.ContractAddress,
+		RewardAddress:            contractRewardCalculation.RewardAddress,
+		DeveloperAddress:         contractRewardCalculation.DeveloperAddress,
+		GasConsumed:              strconv.FormatUint(contractRewardCalculation.GasConsumed, 10),
+		ContractRewardsDenom:     contractRewardCalculation.ContractRewards.Denom,
+		ContractRewardsAmount:    parseAmount(contractRewardCalculation.ContractRewards.Amount.String()),
+		InflationRewardsAmount:   parseAmount(contractRewardCalculation.InflationRewards.Amount.String()),
+		GasRebateToUser:          contractRewardCalculation.GasRebateToUser,
+		CollectPremium:           contractRewardCalculation.CollectPremium,
+		PremiumPercentageCharged: int64(contractRewardCalculation.PremiumPercentageCharged),
+		RewardDate:               contractRewardCalculation.RewardDate,
+		Height:                   contractRewardCalculation.Height,
+	})
+}
+
+// SaveContractRewardDistribution implements database.Database
+func (db *Database) SaveContractRewardDistribution(contractRewardDistribution types.ContractRewardDistribution) error {
+	if db.pending != nil {
+		db.pending.distributions = append(db.pending.distributions, contractRewardDistribution)
+		return nil
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.updateContractRewardDistribution(contractRewardDistribution)
+	return nil
+}
+
+// updateContractRewardDistribution sets the given distribution on the rewards it refers to.
+// The caller must hold the write lock.
+func (db *Database) updateContractRewardDistribution(contractRewardDistribution types.ContractRewardDistribution) {
+	for i, row := range db.rewards {
+		if row.ChainID == db.chainID &&
+			row.RewardAddress == contractRewardDistribution.RewardAddress &&
+			row.Height == contractRewardDistribution.Height {
+			db.rewards[i].DistributedRewardsAmount = parseAmount(contractRewardDistribution.DistributedRewards.Amount.String())
+			db.rewards[i].LeftoverRewardsAmount = parseAmount(contractRewardDistribution.LeftoverRewards.Amount.String())
+		}
+	}
+}
+
+// SaveGasTrackerContractMetadata implements database.Database
+func (db *Database) SaveGasTrackerContractMetadata(gastrackerContractMetadata types.GasTrackerContractMetadata) error {
+	if db.pending != nil {
+		db.pending.metadata = append(db.pending.metadata, gastrackerContractMetadata)
+		return nil
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.insertGasTrackerContractMetadata(gastrackerContractMetadata)
+	return nil
+}
+
+// insertGasTrackerContractMetadata stores the given contract metadata. The caller must hold the write lock.
+func (db *Database) insertGasTrackerContractMetadata(gastrackerContractMetadata types.GasTrackerContractMetadata) {
+	db.lastID++
+	db.metadata = append(db.metadata, dbtypes.ContractMetadataRow{
+		ID:                       db.lastID,
+		ChainID:                  db.chainID,
+		ContractAddress:          gastrackerContractMetadata.ContractAddress,
+		RewardAddress:            gastrackerContractMetadata.Metadata.RewardAddress,
+		DeveloperAddress:         gastrackerContractMetadata.Metadata.DeveloperAddress,
+		CollectPremium:           gastrackerContractMetadata.Metadata.CollectPremium,
+		GasRebateToUser:          gastrackerContractMetadata.Metadata.GasRebateToUser,
+		PremiumPercentageCharged: int64(gastrackerContractMetadata.Metadata.PremiumPercentageCharged),
+		TxHash:                   gastrackerContractMetadata.TxHash,
+		SavedAt:                  gastrackerContractMetadata.SavedAt,
+		Height:                   gastrackerContractMetadata.Height,
+	})
+}
+
+// SaveNotificationDelivery implements database.Database
+func (db *Database) SaveNotificationDelivery(delivery types.NotificationDelivery) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.deliveries = append(db.deliveries, delivery)
+	return nil
+}
+
+// SaveContractStateSnapshot implements database.Database
+func (db *Database) SaveContractStateSnapshot(snapshot types.ContractStateSnapshot) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.snapshots = append(db.snapshots, snapshot)
+	return nil
+}
+
+// HasCw20Token implements database.Database
+func (db *Database) HasCw20Token(contractAddress string) (bool, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, row := range db.cw20Tokens {
+		if row.chainID == db.chainID && row.token.ContractAddress == contractAddress {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SaveCw20Token implements database.Database
+func (db *Database) SaveCw20Token(token types.Cw20Token) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, row := range db.cw20Tokens {
+		if row.chainID == db.chainID && row.token.ContractAddress == token.ContractAddress {
+			return nil
+		}
+	}
+
+	db.cw20Tokens = append(db.cw20Tokens, cw20TokenRow{chainID: db.chainID, token: token})
+	return nil
+}
+
+// SaveCw20Transfer implements database.Database
+func (db *Database) SaveCw20Transfer(transfer types.Cw20Transfer) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, row := range db.transfers {
+		if row.chainID == db.chainID && row.transfer.TxHash == transfer.TxHash &&
+			row.transfer.MsgIndex == transfer.MsgIndex && row.transfer.EventIndex == transfer.EventIndex {
+			return nil
+		}
+	}
+
+	db.transfers = append(db.transfers, cw20TransferRow{chainID: db.chainID, transfer: transfer})
+	db.addCw20Balance(transfer.ContractAddress, transfer.To, transfer.Amount)
+	db.addCw20Balance(transfer.ContractAddress, transfer.From, transfer.Amount.Neg())
+	return nil
+}
+
+// addCw20Balance adds the given amount to the balance of the given address, if any
+func (db *Database) addCw20Balance(contractAddress, address string, amount sdk.Int) {
+	if address == "" {
+		return
+	}
+
+	key := cw20BalanceKey{chainID: db.chainID, contractAddress: contractAddress, address: address}
+	balance, ok := db.balances[key]
+	if !ok {
+		balance = sdk.ZeroInt()
+	}
+	db.balances[key] = balance.Add(amount)
+}
+
+// GetChainInfo implements database.Database.
+// When the database is not scoped to any chain, the identity of the first stored chain is returned.
+func (db *Database) GetChainInfo() (*types.ChainInfo, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, info := range db.chainInfos {
+		if db.inChain(info.ChainID) {
+			info := info
+			return &info, nil
+		}
+	}
+	return nil, nil
+}
+
+// SaveChainInfo implements database.Database
+func (db *Database) SaveChainInfo(info types.ChainInfo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, stored := range db.chainInfos {
+		if stored.ChainID == info.ChainID {
+			return fmt.Errorf("error while saving chain info: chain %s already stored", info.ChainID)
+		}
+	}
+	db.chainInfos = append(db.chainInfos, info)
+	return nil
+}
+
+// CommitBlock implements database.Database.
+// The block is stored along with its pending records. The records of the tables having no unique key are skipped
+// when rows at the same height are stored already, so that parsing a block again does not duplicate them.
+func (db *Database) CommitBlock(block *types.Block) error {
+	rows := db.pending
+	db.pending = nil
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if rows != nil {
+		// The stored heights are computed before writing any row, so that all the rows of this block are kept
+		executionHeights := db.storedHeights(len(db.executions), func(i int) (string, int64) {
+			return db.executions[i].ChainID, db.executions[i].Height
+		})
+		rewardHeights := db.storedHeights(len(db.rewards), func(i int) (string, int64) {
+			return db.rewards[i].ChainID, db.rewards[i].Height
+		})
+		metadataHeights := db.storedHeights(len(db.metadata), func(i int) (string, int64) {
+			return db.metadata[i].ChainID, db.metadata[i].Height
+		})
+
+		for _, executeContract := range rows.executions {
+			if !executionHeights[executeContract.Height] {
+				db.insertWasmExecuteContract(executeContract)
+			}
+		}
+
+		for _, reward := range rows.rewards {
+			if !rewardHeights[reward.Height] {
+				db.insertContractRewardCalculation(reward)
+			}
+		}
+
+		// Distributions are applied after all the rewards they refer to have been stored
+		for _, distribution := range rows.distributions {
+			db.updateContractRewardDistribution(distribution)
+		}
+
+		for _, metadata := range rows.metadata {
+			if !metadataHeights[metadata.Height] {
+				db.insertGasTrackerContractMetadata(metadata)
+			}
+		}
+
+		db.insertBlock(rows.block)
+	}
+
+	db.committed = append(db.committed, block.Height)
+	return nil
+}
+
+// storedHeights returns the heights of the n rows of a table that belong to the chain of this database,
+// reading the chain id and height of each row using the given function
+func (db *Database) storedHeights(n int, row func(i int) (string, int64)) map[int64]bool {
+	heights := map[int64]bool{}
+	for i := 0; i < n; i++ {
+		chainID, height := row(i)
+		if chainID == db.chainID {
+			heights[height] = true
+		}
+	}
+	return heights
+}
+
+// Close implements database.Database
+func (db *Database) Close() {}
+
+// NotificationDeliveries returns all the notification delivery attempts that have been saved
+func (db *Database) NotificationDeliveries() []types.NotificationDelivery {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return append([]types.NotificationDelivery{}, db.deliveries...)
+}
+
+// ContractStateSnapshots returns all the contract state snapshots that have been saved
+func (db *Database) ContractStateSnapshots() []types.ContractStateSnapshot {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return append([]types.ContractStateSnapshot{}, db.snapshots...)
+}
+
+// Cw20Balance returns the balance of the given address for the given CW20 token, resulting from the
+// transfers that have been saved
+func (db *Database) Cw20Balance(contractAddress, address string) sdk.Int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	balance, ok := db.balances[cw20BalanceKey{chainID: db.chainID, contractAddress: contractAddress, address: address}]
+	if !ok {
+		return sdk.ZeroInt()
+	}
+	return balance
+}
+
+// CommittedHeights returns the heights of all the blocks that have been committed, in commit order
+func (db *Database) CommittedHeights() []int64 {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return append([]int64{}, db.committed...)
+}
+
+// --------------------------------------------------------------------------------------------------------------------
+
+// inRange tells whether the given height is inside the given range
+func inRange(height int64, heightRange dbtypes.HeightRange) bool {
+	return (heightRange.FromHeight <= 0 || height >= heightRange.FromHeight) &&
+		(heightRange.ToHeight <= 0 || height <= heightRange.ToHeight)
+}
+
+// matches tells whether value equals filter, or filter is empty
+func matches(value, filter string) bool {
+	return filter == "" || value == filter
+}
+
+// likeMatches tells whether value matches the given case insensitive LIKE pattern, or the pattern is empty
+func likeMatches(value, pattern string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, "%", ".*")
+	expr = strings.ReplaceAll(expr, "_", ".")
+	matched, _ := regexp.MatchString("(?is)^"+expr+"$", value)
+	return matched
+}
+
+// cursorOrder tells whether the row identified by a comes before the one identified by b
+type cursorOrder func(a, b dbtypes.Cursor) bool
+
+func blocksOrder(a, b dbtypes.Cursor) bool {
+	if a.Height != b.Height {
+		return a.Height > b.Height
+	}
+	return a.ChainID < b.ChainID
+}
+
+func wasmCodesOrder(a, b dbtypes.Cursor) bool {
+	if a.ID != b.ID {
+		return a.ID > b.ID
+	}
+	return a.ChainID < b.ChainID
+}
+
+func wasmContractsOrder(a, b dbtypes.Cursor) bool {
+	if a.Height != b.Height {
+		return a.Height > b.Height
+	}
+	if a.ChainID != b.ChainID {
+		return a.ChainID < b.ChainID
+	}
+	return a.Key < b.Key
+}
+
+// rowsOrder sorts the rows of the tables having no unique key using their id
+func rowsOrder(a, b dbtypes.Cursor) bool {
+	if a.Height != b.Height {
+		return a.Height > b.Height
+	}
+	return a.ID > b.ID
+}
+
+// pageBounds returns the indexes of the first and the last (exclusive) items of the given page.
+// The items must be sorted using order, and cursor must return the cursor of the item having the given index.
+func pageBounds(
+	count int, cursor func(i int) dbtypes.Cursor, order cursorOrder, pagination dbtypes.Pagination,
+) (int, int) {
+	start := pagination.Offset
+	if pagination.After != nil {
+		start = sort.Search(count, func(i int) bool {
+			return order(*pagination.After, cursor(i))
+		})
+	}
+	if start > count {
+		start = count
+	}
+
+	end := count
+	if pagination.Limit > 0 && start+pagination.Limit < end {
+		end = start + pagination.Limit
+	}
+	return start, end
+}
+
+// GetBlock implements database.Reader
+func (db *Database) GetBlock(height int64) (*dbtypes.BlockRow, error) {
+	rows, err := db.GetBlocks(
+		dbtypes.BlocksFilter{HeightRange: dbtypes.HeightRange{FromHeight: height, ToHeight: height}},
+		dbtypes.NewPagination(1, 0),
+	)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+// GetBlocks implements database.Reader
+func (db *Database) GetBlocks(filter dbtypes.BlocksFilter, pagination dbtypes.Pagination) ([]dbtypes.BlockRow, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var result []dbtypes.BlockRow
+	for _, row := range db.blocks {
+		if db.inChain(row.ChainID) && inRange(row.Height, filter.HeightRange) {
+			result = append(result, row)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return blocksOrder(result[i].Cursor(), result[j].Cursor())
+	})
+
+	cursor := func(i int) dbtypes.Cursor { return result[i].Cursor() }
+	start, end := pageBounds(len(result), cursor, blocksOrder, pagination)
+	return result[start:end], nil
+}
+
+// GetWasmCode implements database.Reader
+func (db *Database) GetWasmCode(codeID int64) (*dbtypes.WasmCodeRow, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, row := range db.codes {
+		if db.inChain(row.ChainID) && row.CodeID == codeID {
+			code := row
+			return &code, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetWasmCodes implements database.Reader
+func (db *Database) GetWasmCodes(filter dbtypes.WasmCodesFilter, pagination dbtypes.Pagination) ([]dbtypes.WasmCodeRow, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var result []dbtypes.WasmCodeRow
+	for _, row := range db.codes {
+		if db.inChain(row.ChainID) && inRange(row.Height, filter.HeightRange) && matches(row.Creator, filter.Creator) {
+			result = append(result, row)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return wasmCodesOrder(result[i].Cursor(), result[j].Cursor())
+	})
+
+	cursor := func(i int) dbtypes.Cursor { return result[i].Cursor() }
+	start, end := pageBounds(len(result), cursor, wasmCodesOrder, pagination)
+	return result[start:end], nil
+}
+
+// GetWasmContract implements database.Reader
+func (db *Database) GetWasmContract(address string) (*dbtypes.WasmContractRow, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, row := range db.contracts {
+		if db.inChain(row.ChainID) && row.ContractAddress == address {
+			contract := row
+			return &contract, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetWasmContracts implements database.Reader
+func (db *Database) GetWasmContracts(filter dbtypes.WasmContractsFilter, pagination dbtypes.Pagination) ([]dbtypes.WasmContractRow, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var result []dbtypes.WasmContractRow
+	for _, row := range db.contracts {
+		if db.inChain(row.ChainID) &&
+			inRange(row.Height, filter.HeightRange) &&
+			(filter.CodeID <= 0 || row.CodeID == filter.CodeID) &&
+			matches(row.Creator, filter.Creator) &&
+			matches(row.Admin, filter.Admin) &&
+			likeMatches(row.Label, filter.Label) {
+			result = append(result, row)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return wasmContractsOrder(result[i].Cursor(), result[j].Cursor())
+	})
+
+	cursor := func(i int) dbtypes.Cursor { return result[i].Cursor() }
+	start, end := pageBounds(len(result), cursor, wasmContractsOrder, pagination)
+	return result[start:end], nil
+}
+
+// GetWasmExecuteContracts implements database.Reader
+func (db *Database) GetWasmExecuteContracts(
+	filter dbtypes.WasmExecuteContractsFilter, pagination dbtypes.Pagination,
+) ([]dbtypes.WasmExecuteContractRow, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var result []dbtypes.WasmExecuteContractRow
+	for _, row := range db.executions {
+		if db.inChain(row.ChainID) &&
+			inRange(row.Height, filter.HeightRange) &&
+			matches(row.ContractAddress, filter.ContractAddress) &&
+			matches(row.Sender, filter.Sender) &&
+			matches(row.TxHash, filter.TxHash) {
+			result = append(result, row)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return rowsOrder(result[i].Cursor(), result[j].Cursor())
+	})
+
+	cursor := func(i int) dbtypes.Cursor { return result[i].Cursor() }
+	start, end := pageBounds(len(result), cursor, rowsOrder, pagination)
+	return result[start:end], nil
+}
+
+// GetContractMetadata implements database.Reader
+func (db *Database) GetContractMetadata(
+	filter dbtypes.ContractMetadataFilter, pagination dbtypes.Pagination,
+) ([]dbtypes.ContractMetadataRow, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var result []dbtypes.ContractMetadataRow
+	for _, row := range db.metadata {
+		if db.inChain(row.ChainID) &&
+			inRange(row.Height, filter.HeightRange) &&
+			matches(row.ContractAddress, filter.ContractAddress) &&
+			matches(row.RewardAddress, filter.RewardAddress) &&
+			matches(row.DeveloperAddress, filter.DeveloperAddress) {
+			result = append(result, row)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return rowsOrder(result[i].Cursor(), result[j].Cursor())
+	})
+
+	cursor := func(i int) dbtypes.Cursor { return result[i].Cursor() }
+	start, end := pageBounds(len(result), cursor, rowsOrder, pagination)
+	return result[start:end], nil
+}
+
+// GetContractRewards implements database.Reader
+func (db *Database) GetContractRewards(
+	filter dbtypes.ContractRewardsFilter, pagination dbtypes.Pagination,
+) ([]dbtypes.ContractRewardRow, error) {
+	result := db.filterContractRewards(filter)
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return rowsOrder(result[i].Cursor(), result[j].Cursor())
+	})
+
+	cursor := func(i int) dbtypes.Cursor { return result[i].Cursor() }
+	start, end := pageBounds(len(result), cursor, rowsOrder, pagination)
+	return result[start:end], nil
+}
+
+// StreamContractRewards implements database.RewardsStreamer
+func (db *Database) StreamContractRewards(
+	filter dbtypes.ContractRewardsFilter, fn func(row dbtypes.ContractRewardRow) error,
+) error {
+	result := db.filterContractRewards(filter)
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].RewardAddress != result[j].RewardAddress {
+			return result[i].RewardAddress < result[j].RewardAddress
+		}
+		if result[i].Height != result[j].Height {
+			return result[i].Height < result[j].Height
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	for _, row := range result {
+		err := fn(row)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterContractRewards returns a copy of the contract rewards matching the given filter
+func (db *Database) filterContractRewards(filter dbtypes.ContractRewardsFilter) []dbtypes.ContractRewardRow {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var result []dbtypes.ContractRewardRow
+	for _, row := range db.rewards {
+		if db.inChain(row.ChainID) &&
+			inRange(row.Height, filter.HeightRange) &&
+			matches(row.ContractAddress, filter.ContractAddress) &&
+			matches(row.RewardAddress, filter.RewardAddress) &&
+			matches(row.DeveloperAddress, filter.DeveloperAddress) {
+			result = append(result, row)
+		}
+	}
+	return result
+}
@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/database/databasetest"
+	"github.com/nuclearblock/archgregator/database/memory"
+)
+
+func TestSuite(t *testing.T) {
+	databasetest.RunSuite(t, func(t *testing.T) database.Database {
+		db := memory.NewDatabase()
+		t.Cleanup(db.Close)
+		return db
+	})
+}
@@ -0,0 +1,279 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/nuclearblock/archgregator/database"
+)
+
+// type check to ensure interface is properly implemented
+var _ database.Aggregator = &Database{}
+
+// contractStatsPeriod describes an aggregate table containing the executions statistics of each contract per period
+type contractStatsPeriod struct {
+	// table is the name of the statistics table
+	table string
+
+	// senders is the name of the table containing the senders that executed each contract during each period
+	senders string
+
+	// column is the name of the column containing the period
+	column string
+
+	// period is the expression returning the period of an execution
+	period string
+}
+
+var contractStatsPeriods = []contractStatsPeriod{
+	{
+		table:   "contract_daily_stats",
+		senders: "contract_daily_sender",
+		column:  "day",
+		period:  "executed_at::DATE",
+	},
+	{
+		table:   "contract_hourly_stats",
+		senders: "contract_hourly_sender",
+		column:  "hour",
+		period:  "date_trunc('hour', executed_at)",
+	},
+}
+
+// rewardsLeaderboard describes an aggregate table containing the gastracker rewards of each address per day
+type rewardsLeaderboard struct {
+	// table is the name of the leaderboard table
+	table string
+
+	// column is the name of the column containing the address
+	column string
+}
+
+var rewardsLeaderboards = []rewardsLeaderboard{
+	{
+		table:  "developer_daily_rewards",
+		column: "developer_address",
+	},
+	{
+		table:  "reward_address_daily_rewards",
+		column: "reward_address",
+	},
+}
+
+// updateAggregates adds the executions, metadata and rewards of the given chain stored at the given heights to the
+// aggregate tables. The heights must be the ones whose rows have just been written, so that no row is added twice.
+func updateAggregates(db execer, chainID string, executionHeights, metadataHeights, rewardHeights []int64) error {
+	if len(executionHeights) > 0 {
+		err := updateContractStats(db, chainID, executionHeights)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(metadataHeights) == 0 && len(rewardHeights) == 0 {
+		return nil
+	}
+
+	return updateRewardsLeaderboards(db,
+		"chain_id = $1 AND height = ANY($2)", []interface{}{chainID, pq.Array(rewardHeights)},
+		"chain_id = $1 AND height = ANY($2)", []interface{}{chainID, pq.Array(metadataHeights)},
+	)
+}
+
+// updateContractStats adds the executions of the given chain stored at the given heights to the contracts statistics.
+// The senders of each period are tracked on their own, so that the unique senders can be counted incrementally.
+func updateContractStats(db execer, chainID string, heights []int64) error {
+	for _, stats := range contractStatsPeriods {
+		stmt := fmt.Sprintf(`
+WITH executions AS (
+    SELECT chain_id, contract_address, %[3]s AS period, sender, gas_used, fees_amount
+    FROM wasm_execute_contract WHERE chain_id = $1 AND height = ANY($2)
+), new_senders AS (
+    INSERT INTO %[2]s (chain_id, contract_address, %[4]s, sender)
+    SELECT DISTINCT chain_id, contract_address, period, sender FROM executions
+    ON CONFLICT DO NOTHING
+    RETURNING chain_id, contract_address, %[4]s AS period
+), senders AS (
+    SELECT chain_id, contract_address, period, COUNT(*) AS unique_senders
+    FROM new_senders GROUP BY chain_id, contract_address, period
+), totals AS (
+    SELECT chain_id, contract_address, period, COUNT(*) AS executions, SUM(gas_used) AS total_gas, SUM(fees_amount) AS total_fees
+    FROM executions GROUP BY chain_id, contract_address, period
+)
+INSERT INTO %[1]s AS s (chain_id, contract_address, %[4]s, executions, unique_senders, total_gas, total_fees)
+SELECT t.chain_id, t.contract_address, t.period, t.executions, COALESCE(n.unique_senders, 0), t.total_gas, t.total_fees
+FROM totals t LEFT JOIN senders n USING (chain_id, contract_address, period)
+ON CONFLICT (chain_id, contract_address, %[4]s) DO UPDATE SET
+    executions = s.executions + EXCLUDED.executions,
+    unique_senders = s.unique_senders + EXCLUDED.unique_senders,
+    total_gas = s.total_gas + EXCLUDED.total_gas,
+    total_fees = s.total_fees + EXCLUDED.total_fees`,
+			stats.table, stats.senders, stats.period, stats.column)
+
+		_, err := db.Exec(stmt, chainID, pq.Array(heights))
+		if err != nil {
+			return fmt.Errorf("error while updating %s: %s", stats.table, err)
+		}
+	}
+
+	return nil
+}
+
+// updateRewardsLeaderboards adds the rewards and contract metadata matching the given filters to the leaderboards.
+// The contracts rewarded each day are tracked on their own, so that they can be counted incrementally.
+// Since the distributed and leftover rewards belong to a reward address and are copied into the rows of all its
+// contracts rewarded at the same height, they are only counted once for each reward address and height.
+func updateRewardsLeaderboards(
+	db execer, rewardsFilter string, rewardsArgs []interface{}, metadataFilter string, metadataArgs []interface{},
+) error {
+	stmt := fmt.Sprintf(`
+INSERT INTO reward_daily_contract (chain_id, day, contract_address, developer_address, reward_address)
+SELECT DISTINCT chain_id, reward_date::DATE, contract_address, developer_address, reward_address
+FROM contract_reward WHERE %s
+ON CONFLICT DO NOTHING`, rewardsFilter)
+
+	_, err := db.Exec(stmt, rewardsArgs...)
+	if err != nil {
+		return fmt.Errorf("error while updating reward_daily_contract: %s", err)
+	}
+
+	for _, leaderboard := range rewardsLeaderboards {
+		stmt = fmt.Sprintf(`
+WITH rewards AS (
+    SELECT chain_id, %[2]s AS address, reward_address, height, reward_date::DATE AS day,
+           COALESCE(NULLIF(gas_consumed, '')::NUMERIC, 0) AS gas_consumed,
+           contract_rewards_amount, inflation_rewards_amount, distributed_rewards_amount, leftover_rewards_amount
+    FROM contract_reward WHERE %[3]s
+), distributions AS (
+    SELECT DISTINCT ON (chain_id, address, reward_address, height)
+           chain_id, address, day, distributed_rewards_amount, leftover_rewards_amount
+    FROM rewards
+), distributed AS (
+    SELECT chain_id, address, day,
+           SUM(distributed_rewards_amount) AS distributed_rewards,
+           SUM(leftover_rewards_amount) AS leftover_rewards
+    FROM distributions
+    GROUP BY chain_id, address, day
+), totals AS (
+    SELECT chain_id, address, day,
+           SUM(gas_consumed) AS gas_consumed,
+           SUM(contract_rewards_amount) AS contract_rewards,
+           SUM(inflation_rewards_amount) AS inflation_rewards
+    FROM rewards
+    GROUP BY chain_id, address, day
+)
+INSERT INTO %[1]s AS s 
+    (chain_id, %[2]s, day, contracts, gas_consumed, contract_rewards, inflation_rewards, distributed_rewards, leftover_rewards)
+SELECT t.chain_id, t.address, t.day,
+       (SELECT COUNT(DISTINCT c.contract_address) FROM reward_daily_contract c 
+        WHERE c.chain_id = t.chain_id AND c.%[2]s = t.address AND c.day = t.day),
+       t.gas_consumed, t.contract_rewards, t.inflation_rewards, d.distributed_rewards, d.leftover_rewards
+FROM totals t JOIN distributed d USING (chain_id, address, day)
+ON CONFLICT (chain_id, %[2]s, day) DO UPDATE SET
+    contracts = EXCLUDED.contracts,
+    gas_consumed = s.gas_consumed + EXCLUDED.gas_consumed,
+    contract_rewards = s.contract_rewards + EXCLUDED.contract_rewards,
+    inflation_rewards = s.inflation_rewards + EXCLUDED.inflation_rewards,
+    distributed_rewards = s.distributed_rewards + EXCLUDED.distributed_rewards,
+    leftover_rewards = s.leftover_rewards + EXCLUDED.leftover_rewards`,
+			leaderboard.table, leaderboard.column, rewardsFilter)
+
+		_, err = db.Exec(stmt, rewardsArgs...)
+		if err != nil {
+			return fmt.Errorf("error while updating %s: %s", leaderboard.table, err)
+		}
+
+		stmt = fmt.Sprintf(`
+INSERT INTO %[1]s AS s (chain_id, %[2]s, day, metadata_updates)
+SELECT chain_id, %[2]s, saved_at::DATE, COUNT(*) FROM contract_metadata WHERE %[3]s
+GROUP BY chain_id, %[2]s, saved_at::DATE
+ON CONFLICT (chain_id, %[2]s, day) DO UPDATE SET
+    metadata_updates = s.metadata_updates + EXCLUDED.metadata_updates`,
+			leaderboard.table, leaderboard.column, metadataFilter)
+
+		_, err = db.Exec(stmt, metadataArgs...)
+		if err != nil {
+			return fmt.Errorf("error while updating %s: %s", leaderboard.table, err)
+		}
+	}
+
+	return nil
+}
+
+// RebuildAggregates implements database.Aggregator.
+// The aggregates of the period are removed and computed again within a single transaction.
+// When the database is scoped to a chain, only the aggregates of that chain are rebuilt.
+func (db *Database) RebuildAggregates(from, to time.Time) error {
+	tx, err := db.Sql.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting rebuild transaction: %s", err)
+	}
+	defer tx.Rollback() // nolint
+
+	err = rebuildAggregates(tx, db.ChainID, from, to)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error while committing rebuild transaction: %s", err)
+	}
+
+	return nil
+}
+
+// rebuildAggregates removes the aggregates of the given chain for the period between from (included) and to
+// (excluded), and computes them again from the stored records using the given transaction.
+// When the chain id is empty, the aggregates of all the chains are rebuilt.
+func rebuildAggregates(tx *sql.Tx, chainID string, from, to time.Time) error {
+	for _, stats := range contractStatsPeriods {
+		stmts := []string{
+			fmt.Sprintf(`DELETE FROM %[1]s WHERE ($1 = '' OR chain_id = $1) AND %[2]s >= $2 AND %[2]s < $3`,
+				stats.senders, stats.column),
+			fmt.Sprintf(`DELETE FROM %[1]s WHERE ($1 = '' OR chain_id = $1) AND %[2]s >= $2 AND %[2]s < $3`,
+				stats.table, stats.column),
+			fmt.Sprintf(`
+INSERT INTO %[1]s (chain_id, contract_address, %[2]s, sender)
+SELECT DISTINCT chain_id, contract_address, %[3]s, sender FROM wasm_execute_contract
+WHERE ($1 = '' OR chain_id = $1) AND executed_at >= $2 AND executed_at < $3`,
+				stats.senders, stats.column, stats.period),
+			fmt.Sprintf(`
+INSERT INTO %[1]s (chain_id, contract_address, %[2]s, executions, unique_senders, total_gas, total_fees)
+SELECT chain_id, contract_address, %[3]s, COUNT(*), COUNT(DISTINCT sender), SUM(gas_used), SUM(fees_amount)
+FROM wasm_execute_contract
+WHERE ($1 = '' OR chain_id = $1) AND executed_at >= $2 AND executed_at < $3
+GROUP BY chain_id, contract_address, %[3]s`,
+				stats.table, stats.column, stats.period),
+		}
+
+		for _, stmt := range stmts {
+			_, err := tx.Exec(stmt, chainID, from, to)
+			if err != nil {
+				return fmt.Errorf("error while rebuilding %s: %s", stats.table, err)
+			}
+		}
+	}
+
+	stmts := []string{`DELETE FROM reward_daily_contract WHERE ($1 = '' OR chain_id = $1) AND day >= $2 AND day < $3`}
+	for _, leaderboard := range rewardsLeaderboards {
+		stmts = append(stmts, fmt.Sprintf(`DELETE FROM %s WHERE ($1 = '' OR chain_id = $1) AND day >= $2 AND day < $3`,
+			leaderboard.table))
+	}
+
+	for _, stmt := range stmts {
+		_, err := tx.Exec(stmt, chainID, from, to)
+		if err != nil {
+			return fmt.Errorf("error while rebuilding rewards leaderboards: %s", err)
+		}
+	}
+
+	args := []interface{}{chainID, from, to}
+	return updateRewardsLeaderboards(tx,
+		"($1 = '' OR chain_id = $1) AND reward_date >= $2 AND reward_date < $3", args,
+		"($1 = '' OR chain_id = $1) AND saved_at >= $2 AND saved_at < $3", args,
+	)
+}
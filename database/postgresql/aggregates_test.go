@@ -0,0 +1,191 @@
+package postgresql_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	gastrackertypes "github.com/archway-network/archway/x/gastracker/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/database/postgresql"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// aggregatesTime is the time of all the records stored by the aggregates tests
+var aggregatesTime = time.Date(2022, 5, 10, 12, 30, 15, 0, time.UTC)
+
+// contractStats contains a row of the contract_daily_stats table
+type contractStats struct {
+	Executions    int64
+	UniqueSenders int64
+	TotalGas      int64
+	TotalFees     float64
+}
+
+// rewardsLeaderboard contains a row of the developer_daily_rewards table
+type rewardsLeaderboard struct {
+	Contracts          int64
+	MetadataUpdates    int64
+	GasConsumed        int64
+	ContractRewards    float64
+	InflationRewards   float64
+	DistributedRewards float64
+	LeftoverRewards    float64
+}
+
+// aggregatesDatabase returns a database updating the aggregate tables, storing the records for a chain that is
+// unique to the given test
+func aggregatesDatabase(t *testing.T) (*postgresql.Database, string) {
+	db := openDatabase(t)
+	db.Aggregates = true
+
+	chainID := t.Name() + "-" + time.Now().Format("150405.000000000")
+	return db.ForChain(chainID).(*postgresql.Database), chainID
+}
+
+// commitExecutions saves and commits the block having the given height, containing two executions
+func commitExecutions(t *testing.T, db database.Database, height int64) {
+	block := types.NewBlock(height, fmt.Sprintf("HASH%d", height), 2, 240000, aggregatesTime)
+	require.NoError(t, db.SaveBlock(block))
+	for _, sender := range []string{"archway1sender", "archway1other"} {
+		require.NoError(t, db.SaveWasmExecuteContract(types.WasmExecuteContract{
+			Sender:          sender,
+			ContractAddress: "archway1contract",
+			RawContractMsg:  []byte(`{}`),
+			GasUsed:         120000,
+			Fees:            sdk.NewCoins(sdk.NewInt64Coin("utorii", 3500)),
+			TxHash:          "TXHASH",
+			ExecutedAt:      aggregatesTime,
+			Height:          height,
+		}))
+	}
+	require.NoError(t, db.CommitBlock(block))
+}
+
+// readContractStats returns the daily statistics of the test contract, or nil if there are none
+func readContractStats(t *testing.T, db *postgresql.Database, chainID string) *contractStats {
+	rows, err := db.Sql.Query(`
+SELECT executions, unique_senders, total_gas, total_fees FROM contract_daily_stats
+WHERE chain_id = $1 AND contract_address = 'archway1contract'`, chainID)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	if !rows.Next() {
+		require.NoError(t, rows.Err())
+		return nil
+	}
+
+	var stats contractStats
+	require.NoError(t, rows.Scan(&stats.Executions, &stats.UniqueSenders, &stats.TotalGas, &stats.TotalFees))
+	return &stats
+}
+
+func TestContractStatsCommittedTwice(t *testing.T) {
+	db, chainID := aggregatesDatabase(t)
+
+	commitExecutions(t, db, 10)
+	expected := &contractStats{Executions: 2, UniqueSenders: 2, TotalGas: 240000, TotalFees: 7000}
+	require.Equal(t, expected, readContractStats(t, db, chainID))
+
+	// Committing the same block again must not count its executions twice
+	commitExecutions(t, db, 10)
+	require.Equal(t, expected, readContractStats(t, db, chainID))
+}
+
+func TestContractStatsRemovedHeight(t *testing.T) {
+	db, chainID := aggregatesDatabase(t)
+
+	commitExecutions(t, db, 10)
+	commitExecutions(t, db, 11)
+	require.Equal(t, int64(4), readContractStats(t, db, chainID).Executions)
+
+	// The executions of the removed height are taken out of the aggregates, and added again once it is parsed
+	require.NoError(t, db.RemoveHeight(11))
+	require.Equal(t, &contractStats{Executions: 2, UniqueSenders: 2, TotalGas: 240000, TotalFees: 7000},
+		readContractStats(t, db, chainID))
+
+	commitExecutions(t, db, 11)
+	require.Equal(t, &contractStats{Executions: 4, UniqueSenders: 2, TotalGas: 480000, TotalFees: 14000},
+		readContractStats(t, db, chainID))
+}
+
+// commitRewards saves and commits the block having the given height, containing the metadata of a contract and the
+// rewards of two contracts of the same reward address at the previous height, along with their distribution
+func commitRewards(t *testing.T, db database.Database, height int64) {
+	block := types.NewBlock(height, fmt.Sprintf("HASH%d", height), 1, 80000, aggregatesTime)
+	require.NoError(t, db.SaveBlock(block))
+
+	require.NoError(t, db.SaveGasTrackerContractMetadata(types.GasTrackerContractMetadata{
+		Sender:          "archway1developer",
+		ContractAddress: "archway1contract",
+		Metadata: gastrackertypes.ContractInstanceMetadata{
+			DeveloperAddress: "archway1developer",
+			RewardAddress:    "archway1reward",
+		},
+		TxHash:  "TXHASH",
+		SavedAt: aggregatesTime,
+		Height:  height,
+	}))
+
+	for _, contractAddress := range []string{"archway1contract", "archway1other"} {
+		contractRewards := sdk.NewDecCoinFromDec("utorii", sdk.MustNewDecFromStr("12.5"))
+		inflationRewards := sdk.NewDecCoinFromDec("utorii", sdk.MustNewDecFromStr("3.25"))
+		require.NoError(t, db.SaveContractRewardCalculation(types.NewContractRewardCalculation(
+			contractAddress, "archway1reward", "archway1developer", 40000,
+			[]*sdk.DecCoin{&contractRewards}, &inflationRewards, false, false, 0, aggregatesTime, height-1,
+		)))
+	}
+
+	distributed := sdk.NewInt64Coin("utorii", 31)
+	leftover := sdk.NewDecCoinFromDec("utorii", sdk.MustNewDecFromStr("0.5"))
+	require.NoError(t, db.SaveContractRewardDistribution(types.NewContractRewardDistribution(
+		"archway1reward", []*sdk.Coin{&distributed}, []*sdk.DecCoin{&leftover}, height-1,
+	)))
+
+	require.NoError(t, db.CommitBlock(block))
+}
+
+// readRewardsLeaderboard returns the daily rewards of the test developer, or nil if there are none
+func readRewardsLeaderboard(t *testing.T, db *postgresql.Database, chainID string) *rewardsLeaderboard {
+	rows, err := db.Sql.Query(`
+SELECT contracts, metadata_updates, gas_consumed, contract_rewards, inflation_rewards, distributed_rewards, leftover_rewards
+FROM developer_daily_rewards WHERE chain_id = $1 AND developer_address = 'archway1developer'`, chainID)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	if !rows.Next() {
+		require.NoError(t, rows.Err())
+		return nil
+	}
+
+	var leaderboard rewardsLeaderboard
+	require.NoError(t, rows.Scan(
+		&leaderboard.Contracts, &leaderboard.MetadataUpdates, &leaderboard.GasConsumed, &leaderboard.ContractRewards,
+		&leaderboard.InflationRewards, &leaderboard.DistributedRewards, &leaderboard.LeftoverRewards,
+	))
+	return &leaderboard
+}
+
+func TestRewardsLeaderboardsCommittedTwice(t *testing.T) {
+	db, chainID := aggregatesDatabase(t)
+
+	// The distribution of the reward address is only counted once, even if it is copied to both contracts
+	commitRewards(t, db, 20)
+	expected := &rewardsLeaderboard{
+		Contracts:          2,
+		MetadataUpdates:    1,
+		GasConsumed:        80000,
+		ContractRewards:    25,
+		InflationRewards:   6.5,
+		DistributedRewards: 31,
+		LeftoverRewards:    0.5,
+	}
+	require.Equal(t, expected, readRewardsLeaderboard(t, db, chainID))
+
+	// Committing the same rewards and metadata again must not count them twice
+	commitRewards(t, db, 20)
+	require.Equal(t, expected, readRewardsLeaderboard(t, db, chainID))
+}
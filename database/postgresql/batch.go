@@ -0,0 +1,461 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/nuclearblock/archgregator/database"
+	databaseconfig "github.com/nuclearblock/archgregator/database/config"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// type check to ensure interface is properly implemented
+var _ database.Batcher = &Database{}
+
+// maxParameters is the maximum number of parameters that can be bound to a single Postgres statement
+const maxParameters = 65535
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertRows writes the given rows inside table using multi-row INSERT statements, each one
+// containing as many rows as the parameters limit allows. Rows that already exist are skipped.
+func insertRows(db execer, table string, columns []string, rows [][]interface{}) error {
+	perStatement := maxParameters / len(columns)
+	for start := 0; start < len(rows); start += perStatement {
+		end := start + perStatement
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		values, args := valuesList(rows[start:end])
+		stmt := fmt.Sprintf(
+			"INSERT INTO %s (%s) VALUES %s ON CONFLICT DO NOTHING",
+			table, strings.Join(columns, ", "), values,
+		)
+		_, err := db.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// valuesList returns the VALUES list binding the given rows, along with its arguments
+func valuesList(rows [][]interface{}) (string, []interface{}) {
+	var values []string
+	var args []interface{}
+	for _, row := range rows {
+		placeholders := make([]string, len(row))
+		for i := range row {
+			placeholders[i] = fmt.Sprintf("$%d", len(args)+i+1)
+		}
+		values = append(values, "("+strings.Join(placeholders, ", ")+")")
+		args = append(args, row...)
+	}
+	return strings.Join(values, ", "), args
+}
+
+// copyRows writes the given rows inside table using COPY. Since COPY does not allow to skip
+// existing rows, it must only be used for staging tables.
+func copyRows(tx *sql.Tx, table string, columns []string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn(table, columns...))
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		_, err = stmt.Exec(row...)
+		if err != nil {
+			_ = stmt.Close()
+			return err
+		}
+	}
+
+	_, err = stmt.Exec()
+	if err != nil {
+		_ = stmt.Close()
+		return err
+	}
+
+	return stmt.Close()
+}
+
+// insertNewHeights writes the given rows inside table, which has no unique constraint, skipping the rows whose
+// chain and height already have rows inside it, so that writing a block twice does not duplicate them.
+// The rows are first written to a temporary staging table, using COPY when useCopy is true.
+// It returns the heights of each chain whose rows have been written.
+func insertNewHeights(
+	tx *sql.Tx, table string, columns []string, rows [][]interface{}, useCopy bool,
+) (map[string][]int64, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	staging := "staging_" + table
+	_, err := tx.Exec(fmt.Sprintf(`CREATE TEMPORARY TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP`, staging, table))
+	if err != nil {
+		return nil, err
+	}
+
+	if useCopy {
+		err = copyRows(tx, staging, columns, rows)
+	} else {
+		err = insertRows(tx, staging, columns, rows)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	inserted, err := queryHeights(tx, fmt.Sprintf(`
+WITH inserted AS (
+    INSERT INTO %[1]s (%[3]s)
+    SELECT %[3]s FROM %[2]s s
+    WHERE NOT EXISTS (SELECT 1 FROM %[1]s t WHERE t.chain_id = s.chain_id AND t.height = s.height)
+    RETURNING chain_id, height
+)
+SELECT DISTINCT chain_id, height FROM inserted ORDER BY chain_id, height`,
+		table, staging, strings.Join(columns, ", ")))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`DROP TABLE %s`, staging))
+	return inserted, err
+}
+
+// queryHeights runs the given query, which must return a chain id and a height for each row, and returns the
+// heights of each chain
+func queryHeights(tx *sql.Tx, query string, args ...interface{}) (map[string][]int64, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	heights := map[string][]int64{}
+	for rows.Next() {
+		var chainID string
+		var height int64
+		err = rows.Scan(&chainID, &height)
+		if err != nil {
+			return nil, err
+		}
+		heights[chainID] = append(heights[chainID], height)
+	}
+
+	return heights, rows.Err()
+}
+
+// blockRows contains all the records saved while processing a single block
+type blockRows struct {
+	chainID       string
+	block         *types.Block
+	codes         []types.WasmCode
+	contracts     []types.WasmContract
+	executions    []types.WasmExecuteContract
+	metadata      []types.GasTrackerContractMetadata
+	rewards       []types.ContractRewardCalculation
+	distributions []types.ContractRewardDistribution
+	cw20Tokens    []types.Cw20Token
+	cw20Transfers []types.Cw20Transfer
+
+	// done, if set, is called once the records have been written or have failed to be written
+	done func(err error)
+}
+
+// batch contains the records of the committed blocks that have not been written yet
+type batch struct {
+	cfg *databaseconfig.BatchConfig
+
+	mu          sync.Mutex
+	blocks      []*blockRows
+	committedAt time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newBatch returns a new empty batch using the given configuration
+func newBatch(cfg *databaseconfig.BatchConfig) *batch {
+	return &batch{
+		cfg:  cfg,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// has tells whether the block having the given height is buffered for the given chain
+func (b *batch) has(chainID string, height int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, rows := range b.blocks {
+		if rows.chainID == chainID && rows.block.Height == height {
+			return true
+		}
+	}
+	return false
+}
+
+// flushPeriodically flushes the batch once its oldest block has been waiting for the configured
+// interval, until the batch is stopped
+func (db *Database) flushPeriodically() {
+	defer close(db.batch.done)
+
+	interval := db.batch.cfg.FlushInterval
+	if interval <= 0 {
+		<-db.batch.stop
+		return
+	}
+
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.batch.stop:
+			return
+		case <-ticker.C:
+			db.batch.mu.Lock()
+			expired := len(db.batch.blocks) > 0 && time.Since(db.batch.committedAt) >= interval
+			db.batch.mu.Unlock()
+
+			if expired {
+				err := db.flush()
+				if err != nil {
+					db.Logger.Error("error while flushing batch", "err", err)
+				}
+			}
+		}
+	}
+}
+
+// commitPending moves the records of the block being processed into the batch, and flushes
+// the batch when it contains the configured number of blocks.
+// If set, done is called once the records have been written or have failed to be written.
+func (db *Database) commitPending(done func(err error)) error {
+	rows := db.pending
+	db.pending = nil
+	rows.done = done
+
+	db.batch.mu.Lock()
+	if len(db.batch.blocks) == 0 {
+		db.batch.committedAt = time.Now()
+	}
+	db.batch.blocks = append(db.batch.blocks, rows)
+	full := len(db.batch.blocks) >= db.batch.cfg.Size
+	db.batch.mu.Unlock()
+
+	if !full {
+		return nil
+	}
+	return db.flush()
+}
+
+// flush writes all the buffered records within a single transaction and, if enabled,
+// notifies the listeners about each written block.
+// The blocks are taken out of the batch even when the transaction fails, so that a bad row does not make all
+// the following flushes fail too, and the batch never holds more than the configured number of blocks.
+// Their heights are no longer reported as stored, and the error is passed to the done function of each
+// of them so that they can be processed again.
+func (db *Database) flush() error {
+	db.batch.mu.Lock()
+	defer db.batch.mu.Unlock()
+
+	blocks := db.batch.blocks
+	if len(blocks) == 0 {
+		return nil
+	}
+	db.batch.blocks = nil
+
+	err := db.write(blocks, db.batch.cfg.Copy)
+	for _, rows := range blocks {
+		if rows.done != nil {
+			rows.done(err)
+		}
+	}
+
+	return err
+}
+
+// write writes the records of the given blocks within a single transaction, along with the updates of the aggregates
+// and the notifications about each written block, if enabled
+func (db *Database) write(blocks []*blockRows, useCopy bool) error {
+	// The partitions are created outside the transaction, so that they are not rolled back if it fails
+	for _, rows := range blocks {
+		for _, execution := range rows.executions {
+			err := db.ensurePartition("wasm_execute_contract", execution.Height, execution.ExecutedAt)
+			if err != nil {
+				return err
+			}
+		}
+		for _, reward := range rows.rewards {
+			err := db.ensurePartition("contract_reward", reward.Height, reward.RewardDate)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	tx, err := db.Sql.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting batch transaction: %s", err)
+	}
+
+	written, err := writeBlocks(tx, blocks, useCopy)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if db.Aggregates {
+		err = updateWrittenAggregates(tx, written)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	if db.Notify {
+		for _, rows := range blocks {
+			err = notifyBlock(tx, rows.chainID, rows.block)
+			if err != nil {
+				_ = tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error while committing batch transaction: %s", err)
+	}
+
+	return nil
+}
+
+// writtenHeights contains the heights of each chain whose executions, metadata and rewards have been written by
+// a transaction. Only the rows of these heights are added to the aggregates, so that writing a block again does not
+// count its rows twice.
+type writtenHeights struct {
+	executions map[string][]int64
+	metadata   map[string][]int64
+	rewards    map[string][]int64
+}
+
+// writeBlocks writes the records of the given blocks using the given transaction, and returns the heights whose
+// executions, metadata and rewards have been written.
+// The executions, metadata and rewards have no unique constraint, so they are only written for the heights having
+// none of them yet. When useCopy is true, they are staged using COPY.
+// The gastracker distributions are applied last, since they update the rewards rows.
+func writeBlocks(tx *sql.Tx, blocks []*blockRows, useCopy bool) (*writtenHeights, error) {
+	var blockValues, codes, contracts, executions, metadata, rewards, cw20Tokens, cw20Transfers [][]interface{}
+	for _, rows := range blocks {
+		blockValues = append(blockValues, blockRowValues(rows.chainID, rows.block))
+		for _, code := range rows.codes {
+			codes = append(codes, wasmCodeValues(rows.chainID, code))
+		}
+		for _, contract := range rows.contracts {
+			contracts = append(contracts, wasmContractValues(rows.chainID, contract))
+		}
+		for _, execution := range rows.executions {
+			executions = append(executions, wasmExecuteContractValues(rows.chainID, execution))
+		}
+		for _, m := range rows.metadata {
+			metadata = append(metadata, contractMetadataValues(rows.chainID, m))
+		}
+		for _, reward := range rows.rewards {
+			rewards = append(rewards, contractRewardValues(rows.chainID, reward))
+		}
+		for _, token := range rows.cw20Tokens {
+			cw20Tokens = append(cw20Tokens, cw20TokenValues(rows.chainID, token))
+		}
+		for _, transfer := range rows.cw20Transfers {
+			cw20Transfers = append(cw20Transfers, cw20TransferValues(rows.chainID, transfer))
+		}
+	}
+
+	var written writtenHeights
+	err := insertRows(tx, "block", blockColumns, blockValues)
+	if err != nil {
+		return nil, fmt.Errorf("error while saving blocks: %s", err)
+	}
+
+	err = insertRows(tx, "wasm_code", wasmCodeColumns, codes)
+	if err != nil {
+		return nil, fmt.Errorf("error while saving wasm codes: %s", err)
+	}
+
+	err = insertRows(tx, "wasm_contract", wasmContractColumns, contracts)
+	if err != nil {
+		return nil, fmt.Errorf("error while saving wasm contracts: %s", err)
+	}
+
+	written.executions, err = insertNewHeights(tx, "wasm_execute_contract", wasmExecuteContractColumns, executions, useCopy)
+	if err != nil {
+		return nil, fmt.Errorf("error while saving wasm contract executions: %s", err)
+	}
+
+	written.metadata, err = insertNewHeights(tx, "contract_metadata", contractMetadataColumns, metadata, useCopy)
+	if err != nil {
+		return nil, fmt.Errorf("error while saving contract metadata: %s", err)
+	}
+
+	written.rewards, err = insertNewHeights(tx, "contract_reward", contractRewardColumns, rewards, useCopy)
+	if err != nil {
+		return nil, fmt.Errorf("error while saving contract rewards: %s", err)
+	}
+
+	err = insertRows(tx, "cw20_token", cw20TokenColumns, cw20Tokens)
+	if err != nil {
+		return nil, fmt.Errorf("error while saving cw20 tokens: %s", err)
+	}
+
+	err = insertCw20Transfers(tx, cw20Transfers)
+	if err != nil {
+		return nil, fmt.Errorf("error while saving cw20 transfers: %s", err)
+	}
+
+	for _, rows := range blocks {
+		for _, distribution := range rows.distributions {
+			err = updateDistribution(tx, rows.chainID, distribution)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &written, nil
+}
+
+// updateWrittenAggregates adds the records written at the given heights to the aggregate tables
+func updateWrittenAggregates(tx *sql.Tx, written *writtenHeights) error {
+	chainIDs := map[string]bool{}
+	for _, heights := range []map[string][]int64{written.executions, written.metadata, written.rewards} {
+		for chainID := range heights {
+			chainIDs[chainID] = true
+		}
+	}
+
+	for chainID := range chainIDs {
+		err := updateAggregates(tx, chainID, written.executions[chainID], written.metadata[chainID], written.rewards[chainID])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
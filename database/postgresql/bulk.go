@@ -0,0 +1,163 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/nuclearblock/archgregator/database"
+)
+
+// type check to ensure interface is properly implemented
+var _ database.BulkLoader = &Database{}
+
+var (
+	// bulkLoadTables contains the tables whose secondary indexes are deferred while bulk loading
+	bulkLoadTables = []string{
+		"block", "wasm_code", "wasm_contract", "wasm_execute_contract", "contract_metadata", "contract_reward",
+	}
+
+	// bulkLoadKeptIndexes contains the secondary indexes that are kept while bulk loading, since the gastracker
+	// distributions update the rewards rows by chain and height, and the aggregates read the rows the same way
+	bulkLoadKeptIndexes = []string{
+		"contract_reward_chain_id_height_index",
+		"execute_contract_chain_id_height_index",
+		"contract_metadata_chain_id_height_index",
+	}
+)
+
+// DeferIndexes implements database.BulkLoader.
+// The primary keys and unique constraints are kept, since they are needed to skip the records that already exist.
+// The definitions of the dropped indexes are stored inside the bulk_load_index table, so that they can be
+// rebuilt even if the loading gets interrupted.
+func (db *Database) DeferIndexes() ([]string, error) {
+	_, err := db.Sql.Exec(`
+CREATE TABLE IF NOT EXISTS bulk_load_index
+(
+    name       TEXT NOT NULL PRIMARY KEY,
+    definition TEXT NOT NULL
+)`)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating bulk_load_index table: %s", err)
+	}
+
+	stmt := `
+SELECT indexname, indexdef FROM pg_indexes
+WHERE schemaname = current_schema() AND tablename = ANY($1) AND NOT (indexname = ANY($2))
+  AND indexdef NOT LIKE 'CREATE UNIQUE INDEX%'`
+
+	rows, err := db.Sql.Query(stmt, pq.Array(bulkLoadTables), pq.Array(bulkLoadKeptIndexes))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting secondary indexes: %s", err)
+	}
+	defer rows.Close()
+
+	var names, definitions []string
+	for rows.Next() {
+		var name, definition string
+		err = rows.Scan(&name, &definition)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading secondary indexes: %s", err)
+		}
+		names = append(names, name)
+		definitions = append(definitions, definition)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error while reading secondary indexes: %s", err)
+	}
+
+	tx, err := db.Sql.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error while starting defer indexes transaction: %s", err)
+	}
+
+	for i, name := range names {
+		_, err = tx.Exec(`INSERT INTO bulk_load_index (name, definition) VALUES ($1, $2) ON CONFLICT DO NOTHING`, name, definitions[i])
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("error while saving definition of index %s: %s", name, err)
+		}
+
+		_, err = tx.Exec(fmt.Sprintf(`DROP INDEX IF EXISTS %s`, pq.QuoteIdentifier(name)))
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("error while dropping index %s: %s", name, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error while committing defer indexes transaction: %s", err)
+	}
+
+	return names, nil
+}
+
+// RebuildIndexes implements database.BulkLoader.
+// The indexes are created concurrently, so that the tables can still be written while they are built,
+// except the ones of partitioned tables.
+// Any index that was deferred by a previous interrupted loading is rebuilt as well.
+func (db *Database) RebuildIndexes(progress func(index string, done, total int)) error {
+	if db.batch != nil {
+		err := db.flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, err := db.Sql.Query(`SELECT name, definition FROM bulk_load_index ORDER BY name`)
+	if err != nil {
+		return fmt.Errorf("error while getting deferred indexes: %s", err)
+	}
+	defer rows.Close()
+
+	var names, definitions []string
+	for rows.Next() {
+		var name, definition string
+		err = rows.Scan(&name, &definition)
+		if err != nil {
+			return fmt.Errorf("error while reading deferred indexes: %s", err)
+		}
+		names = append(names, name)
+		definitions = append(definitions, definition)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("error while reading deferred indexes: %s", err)
+	}
+
+	for i, name := range names {
+		// A failed concurrent build leaves an invalid index behind, so it is dropped before building it again
+		_, err = db.Sql.Exec(fmt.Sprintf(`DROP INDEX IF EXISTS %s`, pq.QuoteIdentifier(name)))
+		if err != nil {
+			return fmt.Errorf("error while dropping index %s: %s", name, err)
+		}
+
+		// Indexes of partitioned tables cannot be created concurrently, and their definition only refers to the
+		// parent table, while they must be created on all the partitions too
+		definition := definitions[i]
+		if strings.Contains(definition, " ON ONLY ") {
+			definition = strings.Replace(definition, " ON ONLY ", " ON ", 1)
+		} else {
+			definition = strings.Replace(definition, "CREATE INDEX ", "CREATE INDEX CONCURRENTLY ", 1)
+		}
+
+		_, err = db.Sql.Exec(definition)
+		if err != nil {
+			return fmt.Errorf("error while rebuilding index %s: %s", name, err)
+		}
+
+		_, err = db.Sql.Exec(`DELETE FROM bulk_load_index WHERE name = $1`, name)
+		if err != nil {
+			return fmt.Errorf("error while removing definition of index %s: %s", name, err)
+		}
+
+		progress(name, i+1, len(names))
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nuclearblock/archgregator/types"
+)
+
+var cw20TokenColumns = []string{
+	"chain_id", "contract_address", "name", "symbol", "decimals", "minter", "tx_hash", "saved_at", "height",
+}
+
+func cw20TokenValues(chainID string, token types.Cw20Token) []interface{} {
+	return []interface{}{
+		chainID, token.ContractAddress, token.Name, token.Symbol, token.Decimals, token.Minter,
+		token.TxHash, token.SavedAt, token.Height,
+	}
+}
+
+var cw20TransferColumns = []string{
+	"chain_id", "contract_address", "action", "from_address", "to_address", "by_address", "amount",
+	"tx_hash", "msg_index", "event_index", "executed_at", "height",
+}
+
+func cw20TransferValues(chainID string, transfer types.Cw20Transfer) []interface{} {
+	return []interface{}{
+		chainID, transfer.ContractAddress, transfer.Action, transfer.From, transfer.To, transfer.By,
+		transfer.Amount.String(), transfer.TxHash, transfer.MsgIndex, transfer.EventIndex,
+		transfer.ExecutedAt, transfer.Height,
+	}
+}
+
+// HasCw20Token implements database.Database
+func (db *Database) HasCw20Token(contractAddress string) (bool, error) {
+	if db.pending != nil {
+		for _, token := range db.pending.cw20Tokens {
+			if token.ContractAddress == contractAddress {
+				return true, nil
+			}
+		}
+	}
+
+	if db.batch != nil && db.batch.hasCw20Token(db.ChainID, contractAddress) {
+		return true, nil
+	}
+
+	var res bool
+	err := db.Sql.QueryRow(`SELECT EXISTS(SELECT 1 FROM cw20_token WHERE chain_id = $1 AND contract_address = $2);`,
+		db.ChainID, contractAddress).Scan(&res)
+	return res, err
+}
+
+// hasCw20Token tells whether the CW20 token having the given address is buffered for the given chain
+func (b *batch) hasCw20Token(chainID string, contractAddress string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, rows := range b.blocks {
+		if rows.chainID != chainID {
+			continue
+		}
+		for _, token := range rows.cw20Tokens {
+			if token.ContractAddress == contractAddress {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SaveCw20Token implements database.Database
+func (db *Database) SaveCw20Token(token types.Cw20Token) error {
+	if db.pending != nil {
+		db.pending.cw20Tokens = append(db.pending.cw20Tokens, token)
+		return nil
+	}
+
+	err := insertRows(db.Sql, "cw20_token", cw20TokenColumns, [][]interface{}{cw20TokenValues(db.ChainID, token)})
+	if err != nil {
+		return fmt.Errorf("error while saving cw20 token: %s", err)
+	}
+
+	return nil
+}
+
+// SaveCw20Transfer implements database.Database
+func (db *Database) SaveCw20Transfer(transfer types.Cw20Transfer) error {
+	if db.pending != nil {
+		db.pending.cw20Transfers = append(db.pending.cw20Transfers, transfer)
+		return nil
+	}
+
+	err := insertCw20Transfers(db.Sql, [][]interface{}{cw20TransferValues(db.ChainID, transfer)})
+	if err != nil {
+		return fmt.Errorf("error while saving cw20 transfer: %s", err)
+	}
+
+	return nil
+}
+
+// insertCw20Transfers writes the given cw20_transfer rows and applies them to the cw20_balance table.
+// Each statement only applies the transfers it actually inserted, so that the balances are not updated twice
+// when a block is parsed again.
+func insertCw20Transfers(db execer, rows [][]interface{}) error {
+	perStatement := maxParameters / len(cw20TransferColumns)
+	for start := 0; start < len(rows); start += perStatement {
+		end := start + perStatement
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		values, args := valuesList(rows[start:end])
+		stmt := fmt.Sprintf(`
+WITH inserted AS (
+    INSERT INTO cw20_transfer (%s) VALUES %s ON CONFLICT DO NOTHING
+    RETURNING chain_id, contract_address, from_address, to_address, amount, height
+), deltas AS (
+    SELECT chain_id, contract_address, to_address AS address, amount AS delta, height FROM inserted WHERE to_address <> ''
+    UNION ALL
+    SELECT chain_id, contract_address, from_address AS address, -amount AS delta, height FROM inserted WHERE from_address <> ''
+)
+INSERT INTO cw20_balance (chain_id, contract_address, address, balance, height)
+SELECT chain_id, contract_address, address, SUM(delta), MAX(height) FROM deltas
+GROUP BY chain_id, contract_address, address
+ON CONFLICT (chain_id, contract_address, address) DO UPDATE
+    SET balance = cw20_balance.balance + excluded.balance,
+        height = GREATEST(cw20_balance.height, excluded.height)`,
+			strings.Join(cw20TransferColumns, ", "), values,
+		)
+		_, err := db.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+-- Adds the chain_id column to the databases created before multiple chains could be indexed, assigning all the
+-- existing rows to the given chain, and scopes the primary key and unique constraints to each chain.
+-- It also creates the chain_info and prune_log tables if they are missing.
+-- Run it once before starting the new version, passing the chain id of the indexed node, using:
+--   psql -v chain_id=torii-1 -f add_chain_id.sql
+
+\set ON_ERROR_STOP on
+
+\if :{?chain_id}
+\else
+\echo 'missing chain id, run using: psql -v chain_id=<chain id> -f add_chain_id.sql'
+\quit
+\endif
+
+BEGIN;
+
+-- The existing rows get the given chain id, while the new ones get the same default of the tables created from scratch
+ALTER TABLE block ADD COLUMN IF NOT EXISTS chain_id TEXT NOT NULL DEFAULT :'chain_id';
+ALTER TABLE block ALTER COLUMN chain_id SET DEFAULT '';
+ALTER TABLE wasm_code ADD COLUMN IF NOT EXISTS chain_id TEXT NOT NULL DEFAULT :'chain_id';
+ALTER TABLE wasm_code ALTER COLUMN chain_id SET DEFAULT '';
+ALTER TABLE wasm_contract ADD COLUMN IF NOT EXISTS chain_id TEXT NOT NULL DEFAULT :'chain_id';
+ALTER TABLE wasm_contract ALTER COLUMN chain_id SET DEFAULT '';
+ALTER TABLE wasm_execute_contract ADD COLUMN IF NOT EXISTS chain_id TEXT NOT NULL DEFAULT :'chain_id';
+ALTER TABLE wasm_execute_contract ALTER COLUMN chain_id SET DEFAULT '';
+ALTER TABLE contract_metadata ADD COLUMN IF NOT EXISTS chain_id TEXT NOT NULL DEFAULT :'chain_id';
+ALTER TABLE contract_metadata ALTER COLUMN chain_id SET DEFAULT '';
+ALTER TABLE contract_reward ADD COLUMN IF NOT EXISTS chain_id TEXT NOT NULL DEFAULT :'chain_id';
+ALTER TABLE contract_reward ALTER COLUMN chain_id SET DEFAULT '';
+
+-- The notification_delivery table only exists when webhook notifications have been used
+ALTER TABLE IF EXISTS notification_delivery ADD COLUMN IF NOT EXISTS chain_id TEXT NOT NULL DEFAULT :'chain_id';
+ALTER TABLE IF EXISTS notification_delivery ALTER COLUMN chain_id SET DEFAULT '';
+
+-- The constraints are named as the ones of the tables created from scratch, so that running the script again
+-- replaces them instead of adding duplicates
+ALTER TABLE block
+    DROP CONSTRAINT IF EXISTS block_pkey,
+    DROP CONSTRAINT IF EXISTS block_height_key,
+    DROP CONSTRAINT IF EXISTS block_hash_key,
+    DROP CONSTRAINT IF EXISTS block_chain_id_hash_key,
+    ADD CONSTRAINT block_pkey PRIMARY KEY (chain_id, height),
+    ADD CONSTRAINT block_chain_id_hash_key UNIQUE (chain_id, hash);
+
+ALTER TABLE wasm_code
+    DROP CONSTRAINT IF EXISTS wasm_code_code_id_key,
+    DROP CONSTRAINT IF EXISTS wasm_code_chain_id_code_id_key,
+    ADD CONSTRAINT wasm_code_chain_id_code_id_key UNIQUE (chain_id, code_id);
+
+ALTER TABLE wasm_contract
+    DROP CONSTRAINT IF EXISTS wasm_contract_contract_address_key,
+    DROP CONSTRAINT IF EXISTS wasm_contract_chain_id_contract_address_key,
+    ADD CONSTRAINT wasm_contract_chain_id_contract_address_key UNIQUE (chain_id, contract_address);
+
+CREATE INDEX IF NOT EXISTS wasm_code_chain_id_height_index ON wasm_code (chain_id, height);
+CREATE INDEX IF NOT EXISTS wasm_contract_chain_id_height_index ON wasm_contract (chain_id, height);
+CREATE INDEX IF NOT EXISTS execute_contract_chain_id_height_index ON wasm_execute_contract (chain_id, height);
+CREATE INDEX IF NOT EXISTS contract_metadata_chain_id_height_index ON contract_metadata (chain_id, height);
+CREATE INDEX IF NOT EXISTS contract_reward_chain_id_height_index ON contract_reward (chain_id, height);
+
+DO $$
+BEGIN
+    IF to_regclass('notification_delivery') IS NOT NULL THEN
+        CREATE INDEX IF NOT EXISTS notification_delivery_chain_id_height_index ON notification_delivery (chain_id, height);
+    END IF;
+END
+$$;
+
+-- The identity of the chain is stored at the next start, once it has been checked against the node
+CREATE TABLE IF NOT EXISTS chain_info
+(
+    chain_id     TEXT      NOT NULL PRIMARY KEY,
+    genesis_hash TEXT      NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS prune_log
+(
+    id            SERIAL    PRIMARY KEY,
+    chain_id      TEXT      NOT NULL DEFAULT '',
+    table_name    TEXT      NOT NULL,
+    pruned_rows   BIGINT    NOT NULL,
+    cutoff_height BIGINT,
+    cutoff_time   TIMESTAMP,
+    pruned_at     TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS prune_log_chain_id_table_name_index ON prune_log (chain_id, table_name);
+
+COMMIT;
@@ -0,0 +1,22 @@
+-- Adds the id column used to paginate the tables having no unique key to the databases created before it was
+-- introduced. The existing rows are numbered while adding the column, which rewrites the tables.
+-- Run it once before starting the new version, using: psql -f add_row_ids.sql
+
+BEGIN;
+
+CREATE SEQUENCE IF NOT EXISTS wasm_execute_contract_id_seq;
+ALTER TABLE wasm_execute_contract
+    ADD COLUMN IF NOT EXISTS id BIGINT NOT NULL DEFAULT nextval('wasm_execute_contract_id_seq');
+ALTER SEQUENCE wasm_execute_contract_id_seq OWNED BY wasm_execute_contract.id;
+
+CREATE SEQUENCE IF NOT EXISTS contract_metadata_id_seq;
+ALTER TABLE contract_metadata
+    ADD COLUMN IF NOT EXISTS id BIGINT NOT NULL DEFAULT nextval('contract_metadata_id_seq');
+ALTER SEQUENCE contract_metadata_id_seq OWNED BY contract_metadata.id;
+
+CREATE SEQUENCE IF NOT EXISTS contract_reward_id_seq;
+ALTER TABLE contract_reward
+    ADD COLUMN IF NOT EXISTS id BIGINT NOT NULL DEFAULT nextval('contract_reward_id_seq');
+ALTER SEQUENCE contract_reward_id_seq OWNED BY contract_reward.id;
+
+COMMIT;
@@ -0,0 +1,142 @@
+package postgresql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nuclearblock/archgregator/types"
+)
+
+const (
+	// BlockChannel represents the channel on which a notification is sent each time a block is committed
+	BlockChannel = "archgregator_block"
+
+	// tableChannelPrefix is prepended to the name of each table to get the channel of that table
+	tableChannelPrefix = "archgregator_"
+)
+
+// blockNotification represents the payload sent on BlockChannel
+type blockNotification struct {
+	ChainID   string    `json:"chain_id,omitempty"`
+	Height    int64     `json:"height"`
+	Hash      string    `json:"hash"`
+	TxNum     int       `json:"num_txs"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// tableNotification represents the payload sent on the channel of a table that received new rows
+type tableNotification struct {
+	ChainID string `json:"chain_id,omitempty"`
+	Height  int64  `json:"height"`
+	Count   int64  `json:"count"`
+}
+
+// TableChannel returns the channel on which the notifications about the given table are sent
+func TableChannel(table string) string {
+	return tableChannelPrefix + table
+}
+
+// CommitBlock implements database.Database.
+// All the records of the block are written within a single transaction, along with the updates of the aggregates
+// and the notifications, so that a block is either stored entirely or not at all and can be safely parsed again
+// when committing fails. When batching is enabled, the records of the block are moved into the batch instead.
+func (db *Database) CommitBlock(block *types.Block) error {
+	if db.pending == nil {
+		return nil
+	}
+
+	if db.batch != nil {
+		return db.commitPending(nil)
+	}
+
+	rows := db.pending
+	db.pending = nil
+	return db.write([]*blockRows{rows}, false)
+}
+
+// CommitBlockAsync implements database.Batcher.
+// When batching is enabled, done is called once the batch containing the block has been flushed.
+func (db *Database) CommitBlockAsync(block *types.Block, done func(err error)) error {
+	if db.pending == nil || db.batch == nil {
+		err := db.CommitBlock(block)
+		if err != nil {
+			return err
+		}
+
+		done(nil)
+		return nil
+	}
+
+	// The errors of the flush are reported to each block of the batch through its done function
+	_ = db.commitPending(done)
+	return nil
+}
+
+// notifyBlock notifies the given block of the given chain on BlockChannel, and each table that received
+// rows while processing it on its own channel along with the number of such rows. Note that the
+// gastracker rewards contained inside a block refer to the previous height.
+// The notifications are sent within the given transaction, so the listeners only receive them once it commits.
+func notifyBlock(tx *sql.Tx, chainID string, block *types.Block) error {
+	stmt := `
+SELECT 'wasm_code', height, COUNT(*) FROM wasm_code WHERE chain_id = $1 AND height = $2 GROUP BY height
+UNION ALL
+SELECT 'wasm_contract', height, COUNT(*) FROM wasm_contract WHERE chain_id = $1 AND height = $2 GROUP BY height
+UNION ALL
+SELECT 'wasm_execute_contract', height, COUNT(*) FROM wasm_execute_contract WHERE chain_id = $1 AND height = $2 GROUP BY height
+UNION ALL
+SELECT 'contract_metadata', height, COUNT(*) FROM contract_metadata WHERE chain_id = $1 AND height = $2 GROUP BY height
+UNION ALL
+SELECT 'contract_reward', height, COUNT(*) FROM contract_reward WHERE chain_id = $1 AND height = $3 GROUP BY height`
+
+	rows, err := tx.Query(stmt, chainID, block.Height, block.Height-1)
+	if err != nil {
+		return fmt.Errorf("error while counting the rows of block %d: %s", block.Height, err)
+	}
+	defer rows.Close()
+
+	notifications := map[string]interface{}{}
+	for rows.Next() {
+		var table string
+		notification := tableNotification{ChainID: chainID}
+		err = rows.Scan(&table, &notification.Height, &notification.Count)
+		if err != nil {
+			return fmt.Errorf("error while reading the rows count of block %d: %s", block.Height, err)
+		}
+		notifications[TableChannel(table)] = notification
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("error while reading the rows count of block %d: %s", block.Height, err)
+	}
+
+	notifications[BlockChannel] = blockNotification{
+		ChainID:   chainID,
+		Height:    block.Height,
+		Hash:      block.Hash,
+		TxNum:     block.TxNum,
+		Timestamp: block.Timestamp,
+	}
+
+	return notify(tx, notifications)
+}
+
+// notify sends the given payloads, indexed by channel, within the given transaction
+// so that the listeners receive them all together
+func notify(tx *sql.Tx, notifications map[string]interface{}) error {
+	for channel, payload := range notifications {
+		bz, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("error while serializing %s notification: %s", channel, err)
+		}
+
+		_, err = tx.Exec(`SELECT pg_notify($1, $2)`, channel, string(bz))
+		if err != nil {
+			return fmt.Errorf("error while notifying %s: %s", channel, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,315 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	databaseconfig "github.com/nuclearblock/archgregator/database/config"
+)
+
+const (
+	// partitioningLock is the advisory lock that prevents multiple processes from converting the tables at once
+	partitioningLock = "archgregator_partitioning"
+
+	// monthBoundLayout is the layout of the timestamps used as bounds of the monthly partitions
+	monthBoundLayout = "2006-01-02 15:04:05"
+)
+
+var (
+	// partitionedTables contains the name of each table that is partitioned, associated with the
+	// timestamp column used when partitioning by month
+	partitionedTables = map[string]string{
+		"wasm_execute_contract": "executed_at",
+		"contract_reward":       "reward_date",
+	}
+
+	// partitionBoundRegex matches the bounds of a range partition
+	partitionBoundRegex = regexp.MustCompile(`FROM \((.+)\) TO \((.+)\)`)
+)
+
+// partitioner creates the partitions of the partitioned tables as soon as they are needed.
+// Each partition is identified by an index, which is the height divided by the partition size when
+// partitioning by height, or the number of months since year zero when partitioning by month.
+type partitioner struct {
+	cfg *databaseconfig.PartitioningConfig
+	sql *sql.DB
+
+	mu sync.Mutex
+
+	// created contains, for each table, the indexes of the partitions that exist
+	created map[string]map[int64]bool
+
+	// legacy contains, for each table, the index of the first partition that is not covered by the
+	// partition holding the rows stored before the table was partitioned
+	legacy map[string]int64
+}
+
+// newPartitioner converts the partitioned tables into partitioned ones if they are not yet,
+// and returns a partitioner aware of all their existing partitions
+func newPartitioner(cfg *databaseconfig.PartitioningConfig, db *sql.DB) (*partitioner, error) {
+	switch cfg.By {
+	case databaseconfig.PartitionByMonth:
+	case databaseconfig.PartitionByHeight:
+		if cfg.Size <= 0 {
+			return nil, fmt.Errorf("invalid partition size: %d", cfg.Size)
+		}
+	default:
+		return nil, fmt.Errorf("invalid partitioning: %s", cfg.By)
+	}
+
+	p := &partitioner{
+		cfg:     cfg,
+		sql:     db,
+		created: map[string]map[int64]bool{},
+		legacy:  map[string]int64{},
+	}
+
+	for table := range partitionedTables {
+		err := p.partitionTable(table)
+		if err != nil {
+			return nil, err
+		}
+
+		err = p.loadPartitions(table)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// column returns the column the given table is partitioned by
+func (p *partitioner) column(table string) string {
+	if p.cfg.By == databaseconfig.PartitionByMonth {
+		return partitionedTables[table]
+	}
+	return "height"
+}
+
+// index returns the index of the partition containing the row having the given height and timestamp
+func (p *partitioner) index(height int64, timestamp time.Time) int64 {
+	if p.cfg.By == databaseconfig.PartitionByMonth {
+		timestamp = timestamp.UTC()
+		return int64(timestamp.Year())*12 + int64(timestamp.Month()) - 1
+	}
+	return height / p.cfg.Size
+}
+
+// bound returns the value of the lower bound of the partition having the given index
+func (p *partitioner) bound(index int64) string {
+	if p.cfg.By == databaseconfig.PartitionByMonth {
+		return time.Date(int(index/12), time.Month(index%12+1), 1, 0, 0, 0, 0, time.UTC).Format(monthBoundLayout)
+	}
+	return strconv.FormatInt(index*p.cfg.Size, 10)
+}
+
+// boundIndex returns the index of the partition having the given lower bound
+func (p *partitioner) boundIndex(bound string) (int64, error) {
+	bound = strings.Trim(bound, "'")
+	if p.cfg.By == databaseconfig.PartitionByMonth {
+		timestamp, err := time.Parse(monthBoundLayout, bound)
+		if err != nil {
+			return 0, err
+		}
+		return p.index(0, timestamp), nil
+	}
+
+	height, err := strconv.ParseInt(bound, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return p.index(height, time.Time{}), nil
+}
+
+// partitionName returns the name of the partition of the given table having the given index
+func (p *partitioner) partitionName(table string, index int64) string {
+	if p.cfg.By == databaseconfig.PartitionByMonth {
+		return fmt.Sprintf("%s_y%04dm%02d", table, index/12, index%12+1)
+	}
+	return fmt.Sprintf("%s_h%d", table, index*p.cfg.Size)
+}
+
+// partitionTable converts the given table into a partitioned one, if it is not yet.
+// The existing rows are kept inside the <table>_legacy partition, which covers all the values up to the
+// partition following the one of the most recent row, and the existing indexes are created again on the
+// partitioned table.
+func (p *partitioner) partitionTable(table string) error {
+	tx, err := p.sql.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting partitioning transaction: %s", err)
+	}
+	defer tx.Rollback() // nolint
+
+	_, err = tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, partitioningLock)
+	if err != nil {
+		return fmt.Errorf("error while acquiring partitioning lock: %s", err)
+	}
+
+	var kind string
+	err = tx.QueryRow(`SELECT relkind FROM pg_class WHERE oid = to_regclass($1)`, table).Scan(&kind)
+	if err != nil {
+		return fmt.Errorf("error while getting kind of table %s: %s", table, err)
+	}
+	if kind == "p" {
+		return nil
+	}
+
+	rows, err := tx.Query(`SELECT indexname, indexdef FROM pg_indexes WHERE schemaname = current_schema() AND tablename = $1`, table)
+	if err != nil {
+		return fmt.Errorf("error while getting indexes of table %s: %s", table, err)
+	}
+
+	var indexes, definitions []string
+	for rows.Next() {
+		var index, definition string
+		err = rows.Scan(&index, &definition)
+		if err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("error while reading indexes of table %s: %s", table, err)
+		}
+		indexes = append(indexes, index)
+		definitions = append(definitions, definition)
+	}
+	_ = rows.Close()
+
+	legacy := table + "_legacy"
+	stmts := []string{
+		fmt.Sprintf(`ALTER TABLE %s RENAME TO %s`, pq.QuoteIdentifier(table), pq.QuoteIdentifier(legacy)),
+	}
+	for _, index := range indexes {
+		stmts = append(stmts, fmt.Sprintf(`ALTER INDEX %s RENAME TO %s`,
+			pq.QuoteIdentifier(index), pq.QuoteIdentifier(index+"_legacy")))
+	}
+	stmts = append(stmts, fmt.Sprintf(
+		`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS) PARTITION BY RANGE (%s)`,
+		pq.QuoteIdentifier(table), pq.QuoteIdentifier(legacy), p.column(table),
+	))
+
+	for _, stmt := range stmts {
+		_, err = tx.Exec(stmt)
+		if err != nil {
+			return fmt.Errorf("error while partitioning table %s: %s", table, err)
+		}
+	}
+
+	// The sequence generating the row ids is owned by the legacy table, and would be dropped along with it
+	var sequence sql.NullString
+	err = tx.QueryRow(`SELECT pg_get_serial_sequence($1, 'id')`, legacy).Scan(&sequence)
+	if err != nil {
+		return fmt.Errorf("error while getting id sequence of table %s: %s", table, err)
+	}
+	if sequence.Valid {
+		_, err = tx.Exec(fmt.Sprintf(`ALTER SEQUENCE %s OWNED BY %s.id`, sequence.String, pq.QuoteIdentifier(table)))
+		if err != nil {
+			return fmt.Errorf("error while moving id sequence of table %s: %s", table, err)
+		}
+	}
+
+	var maxHeight sql.NullInt64
+	var maxTimestamp sql.NullTime
+	err = tx.QueryRow(fmt.Sprintf(`SELECT MAX(height), MAX(%s) FROM %s`, partitionedTables[table], pq.QuoteIdentifier(legacy))).
+		Scan(&maxHeight, &maxTimestamp)
+	if err != nil {
+		return fmt.Errorf("error while getting most recent row of table %s: %s", table, err)
+	}
+
+	if !maxHeight.Valid {
+		_, err = tx.Exec(fmt.Sprintf(`DROP TABLE %s`, pq.QuoteIdentifier(legacy)))
+	} else {
+		_, err = tx.Exec(fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (MINVALUE) TO ('%s')`,
+			pq.QuoteIdentifier(table), pq.QuoteIdentifier(legacy), p.bound(p.index(maxHeight.Int64, maxTimestamp.Time)+1)))
+	}
+	if err != nil {
+		return fmt.Errorf("error while moving existing rows of table %s: %s", table, err)
+	}
+
+	for i, definition := range definitions {
+		_, err = tx.Exec(definition)
+		if err != nil {
+			return fmt.Errorf("error while creating index %s: %s", indexes[i], err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error while committing partitioning transaction: %s", err)
+	}
+
+	return nil
+}
+
+// loadPartitions reads the bounds of the existing partitions of the given table
+func (p *partitioner) loadPartitions(table string) error {
+	stmt := `
+SELECT c.relname, pg_get_expr(c.relpartbound, c.oid) FROM pg_inherits i
+JOIN pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = to_regclass($1)`
+
+	rows, err := p.sql.Query(stmt, table)
+	if err != nil {
+		return fmt.Errorf("error while getting partitions of table %s: %s", table, err)
+	}
+	defer rows.Close()
+
+	p.created[table] = map[int64]bool{}
+	for rows.Next() {
+		var name, bounds string
+		err = rows.Scan(&name, &bounds)
+		if err != nil {
+			return fmt.Errorf("error while reading partitions of table %s: %s", table, err)
+		}
+
+		matches := partitionBoundRegex.FindStringSubmatch(bounds)
+		if matches == nil {
+			return fmt.Errorf("invalid bounds of partition %s: %s", name, bounds)
+		}
+
+		if matches[1] == "MINVALUE" {
+			index, err := p.boundIndex(matches[2])
+			if err != nil {
+				return fmt.Errorf("invalid bounds of partition %s: %s", name, bounds)
+			}
+			p.legacy[table] = index
+			continue
+		}
+
+		index, err := p.boundIndex(matches[1])
+		if err != nil {
+			return fmt.Errorf("invalid bounds of partition %s: %s", name, bounds)
+		}
+		p.created[table][index] = true
+	}
+
+	return rows.Err()
+}
+
+// ensure makes sure that the partition of the given table containing the row having the given height and
+// timestamp exists, along with the configured number of following partitions
+func (p *partitioner) ensure(table string, height int64, timestamp time.Time) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	first := p.index(height, timestamp)
+	for index := first; index <= first+int64(p.cfg.Ahead); index++ {
+		if index < p.legacy[table] || p.created[table][index] {
+			continue
+		}
+
+		_, err := p.sql.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')`,
+			pq.QuoteIdentifier(p.partitionName(table, index)), pq.QuoteIdentifier(table), p.bound(index), p.bound(index+1)))
+		if err != nil {
+			return fmt.Errorf("error while creating partition of table %s: %s", table, err)
+		}
+		p.created[table][index] = true
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package postgresql
 import (
 	"database/sql"
 	"strconv"
+	"time"
 
 	"fmt"
 
@@ -50,11 +51,27 @@ func Builder(ctx *database.Context) (database.Database, error) {
 	postgresDb.SetMaxOpenConns(ctx.Cfg.MaxOpenConnections)
 	postgresDb.SetMaxIdleConns(ctx.Cfg.MaxIdleConnections)
 
-	return &Database{
+	db := &Database{
 		Sql:            postgresDb,
 		EncodingConfig: ctx.EncodingConfig,
 		Logger:         ctx.Logger,
-	}, nil
+		Notify:         ctx.Cfg.Notify,
+		Aggregates:     ctx.Cfg.Aggregates,
+	}
+
+	if ctx.Cfg.Partitioning != nil {
+		db.partitions, err = newPartitioner(ctx.Cfg.Partitioning, postgresDb)
+		if err != nil {
+			return nil, fmt.Errorf("error while setting up partitioning: %s", err)
+		}
+	}
+
+	if ctx.Cfg.Batch != nil {
+		db.batch = newBatch(ctx.Cfg.Batch)
+		go db.flushPeriodically()
+	}
+
+	return db, nil
 }
 
 // type check to ensure interface is properly implemented
@@ -66,39 +83,83 @@ type Database struct {
 	Sql            *sql.DB
 	EncodingConfig *params.EncodingConfig
 	Logger         logging.Logger
+
+	// Notify tells whether a NOTIFY should be sent each time a block is committed
+	Notify bool
+
+	// Aggregates tells whether the aggregate tables should be updated each time a block is committed
+	Aggregates bool
+
+	// ChainID is the id of the chain all the data is stored for and read from.
+	// When empty, the data of all the chains is read
+	ChainID string
+
+	// batch is shared by all the instances using the same connection, and is nil when batching is disabled
+	batch *batch
+
+	// partitions is nil when partitioning is disabled
+	partitions *partitioner
+
+	// pending contains the records of the block being processed, which are written or moved into the batch
+	// once the block is committed
+	pending *blockRows
 }
 
-// HasBlock implements database.Database
+// ForChain implements database.Database.
+// The returned Database tracks the records of the block being processed on its own, so each worker must
+// use its own instance.
+func (db *Database) ForChain(chainID string) database.Database {
+	scoped := *db
+	scoped.ChainID = chainID
+	scoped.pending = nil
+	return &scoped
+}
+
+// HasBlock implements database.Database.
+// Blocks that are buffered inside the batch are considered as stored.
 func (db *Database) HasBlock(height int64) (bool, error) {
+	if db.batch != nil && db.batch.has(db.ChainID, height) {
+		return true, nil
+	}
+
 	var res bool
-	err := db.Sql.QueryRow(`SELECT EXISTS(SELECT 1 FROM block WHERE height = $1);`, height).Scan(&res)
+	err := db.Sql.QueryRow(`SELECT EXISTS(SELECT 1 FROM block WHERE chain_id = $1 AND height = $2);`, db.ChainID, height).Scan(&res)
 	return res, err
 }
 
-// SaveBlock implements database.Database
+var blockColumns = []string{"chain_id", "height", "hash", "num_txs", "total_gas", "timestamp"}
+
+func blockRowValues(chainID string, block *types.Block) []interface{} {
+	return []interface{}{
+		chainID, block.Height, block.Hash, block.TxNum, block.TotalGas, block.Timestamp,
+	}
+}
+
+// SaveBlock implements database.Database.
+// All the records of the block are buffered until it is committed.
 func (db *Database) SaveBlock(block *types.Block) error {
-	sqlStatement := `
-	INSERT INTO block (height, hash, num_txs, total_gas, timestamp)
-	VALUES ($1, $2, $3, $4, $5) ON CONFLICT DO NOTHING`
+	db.pending = &blockRows{chainID: db.ChainID, block: block}
+	return nil
+}
 
-	_, err := db.Sql.Exec(sqlStatement,
-		block.Height, block.Hash, block.TxNum, block.TotalGas, block.Timestamp,
-	)
-	return err
+var wasmCodeColumns = []string{"chain_id", "creator", "code_hash", "code_id", "size", "tx_hash", "saved_at", "height"}
+
+func wasmCodeValues(chainID string, wasmCode types.WasmCode) []interface{} {
+	return []interface{}{
+		chainID, wasmCode.Creator, wasmCode.CodeHash,
+		wasmCode.CodeID, wasmCode.Size, wasmCode.TxHash,
+		wasmCode.SavedAt, wasmCode.Height,
+	}
 }
 
 // SaveWasmCode allows to store the wasm code from MsgStoreCode
 func (db *Database) SaveWasmCode(wasmCode types.WasmCode) error {
-	stmt := `
-	INSERT INTO wasm_code(creator, code_hash, code_id, size, tx_hash, saved_at, height) 
-	VALUES ($1, $2, $3, $4, $5, $6, $7) 
-	ON CONFLICT DO NOTHING`
+	if db.pending != nil {
+		db.pending.codes = append(db.pending.codes, wasmCode)
+		return nil
+	}
 
-	_, err := db.Sql.Exec(stmt,
-		wasmCode.Creator, wasmCode.CodeHash,
-		wasmCode.CodeID, wasmCode.Size, wasmCode.TxHash,
-		wasmCode.SavedAt, wasmCode.Height,
-	)
+	err := insertRows(db.Sql, "wasm_code", wasmCodeColumns, [][]interface{}{wasmCodeValues(db.ChainID, wasmCode)})
 	if err != nil {
 		return fmt.Errorf("error while saving wasm code: %s", err)
 	}
@@ -106,21 +167,27 @@ func (db *Database) SaveWasmCode(wasmCode types.WasmCode) error {
 	return nil
 }
 
-// SaveWasmContract allows to store the wasm contract from MsgInstantiateContract
-func (db *Database) SaveWasmContract(wasmContract types.WasmContract) error {
-
-	stmt := `
-	INSERT INTO wasm_contract 
-	(sender, creator, admin, code_id, label, raw_contract_message, funds, contract_address, tx_hash, instantiated_at, height) 
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) 
-	ON CONFLICT DO NOTHING`
+var wasmContractColumns = []string{
+	"chain_id", "sender", "creator", "admin", "code_id", "label", "raw_contract_message",
+	"funds", "contract_address", "tx_hash", "instantiated_at", "height",
+}
 
-	_, err := db.Sql.Exec(stmt,
-		wasmContract.Sender, wasmContract.Creator, wasmContract.Admin, wasmContract.CodeID, wasmContract.Label, string(wasmContract.RawContractMsg),
+func wasmContractValues(chainID string, wasmContract types.WasmContract) []interface{} {
+	return []interface{}{
+		chainID, wasmContract.Sender, wasmContract.Creator, wasmContract.Admin, wasmContract.CodeID, wasmContract.Label, string(wasmContract.RawContractMsg),
 		pq.Array(dbtypes.NewDbCoins(wasmContract.Funds)), wasmContract.ContractAddress, wasmContract.TxHash,
 		wasmContract.InstantiatedAt, wasmContract.Height,
-	)
+	}
+}
+
+// SaveWasmContract allows to store the wasm contract from MsgInstantiateContract
+func (db *Database) SaveWasmContract(wasmContract types.WasmContract) error {
+	if db.pending != nil {
+		db.pending.contracts = append(db.pending.contracts, wasmContract)
+		return nil
+	}
 
+	err := insertRows(db.Sql, "wasm_contract", wasmContractColumns, [][]interface{}{wasmContractValues(db.ChainID, wasmContract)})
 	if err != nil {
 		return fmt.Errorf("error while saving wasm contract: %s", err)
 	}
@@ -128,18 +195,16 @@ func (db *Database) SaveWasmContract(wasmContract types.WasmContract) error {
 	return nil
 }
 
-// SaveWasmExecuteContract allows to store the wasm contract from MsgExecuteeContract
-func (db *Database) SaveWasmExecuteContract(executeContract types.WasmExecuteContract) error {
-
-	stmt := `
-	INSERT INTO wasm_execute_contract 
-	(sender, contract_address, raw_contract_message, funds, gas_used, fees_denom, fees_amount, tx_hash, executed_at, height) 
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) 
-	ON CONFLICT DO NOTHING`
+var wasmExecuteContractColumns = []string{
+	"chain_id", "sender", "contract_address", "raw_contract_message", "funds", "gas_used",
+	"fees_denom", "fees_amount", "tx_hash", "executed_at", "height",
+}
 
+func wasmExecuteContractValues(chainID string, executeContract types.WasmExecuteContract) []interface{} {
 	denom := "utorii"
 
-	_, err := db.Sql.Exec(stmt,
+	return []interface{}{
+		chainID,
 		executeContract.Sender,
 		executeContract.ContractAddress,
 		executeContract.RawContractMsg,
@@ -150,8 +215,23 @@ func (db *Database) SaveWasmExecuteContract(executeContract types.WasmExecuteCon
 		executeContract.TxHash,
 		executeContract.ExecutedAt,
 		executeContract.Height,
-	)
+	}
+}
+
+// SaveWasmExecuteContract allows to store the wasm contract from MsgExecuteeContract
+func (db *Database) SaveWasmExecuteContract(executeContract types.WasmExecuteContract) error {
+	if db.pending != nil {
+		db.pending.executions = append(db.pending.executions, executeContract)
+		return nil
+	}
+
+	err := db.ensurePartition("wasm_execute_contract", executeContract.Height, executeContract.ExecutedAt)
+	if err != nil {
+		return err
+	}
 
+	err = insertRows(db.Sql, "wasm_execute_contract", wasmExecuteContractColumns,
+		[][]interface{}{wasmExecuteContractValues(db.ChainID, executeContract)})
 	if err != nil {
 		return fmt.Errorf("error while saving wasm contract: %s", err)
 	}
@@ -159,16 +239,15 @@ func (db *Database) SaveWasmExecuteContract(executeContract types.WasmExecuteCon
 	return nil
 }
 
-func (db *Database) SaveContractRewardCalculation(contractRewardCalculation types.ContractRewardCalculation) error {
-
-	stmt := `
-	INSERT INTO contract_reward 
-	(contract_address, reward_address, developer_address, gas_consumed, contract_rewards_denom, contract_rewards_amount, inflation_rewards_amount, gas_rebate_to_user, collect_premium, premium_percentage_charged, reward_date, height) 
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) 
-	ON CONFLICT DO NOTHING`
+var contractRewardColumns = []string{
+	"chain_id", "contract_address", "reward_address", "developer_address", "gas_consumed",
+	"contract_rewards_denom", "contract_rewards_amount", "inflation_rewards_amount", "gas_rebate_to_user",
+	"collect_premium", "premium_percentage_charged", "reward_date", "height",
+}
 
-	_, err := db.Sql.Exec(
-		stmt,
+func contractRewardValues(chainID string, contractRewardCalculation types.ContractRewardCalculation) []interface{} {
+	return []interface{}{
+		chainID,
 		contractRewardCalculation.ContractAddress,
 		contractRewardCalculation.RewardAddress,
 		contractRewardCalculation.DeveloperAddress,
@@ -181,8 +260,22 @@ func (db *Database) SaveContractRewardCalculation(contractRewardCalculation type
 		contractRewardCalculation.PremiumPercentageCharged,
 		contractRewardCalculation.RewardDate,
 		contractRewardCalculation.Height,
-	)
+	}
+}
+
+func (db *Database) SaveContractRewardCalculation(contractRewardCalculation types.ContractRewardCalculation) error {
+	if db.pending != nil {
+		db.pending.rewards = append(db.pending.rewards, contractRewardCalculation)
+		return nil
+	}
+
+	err := db.ensurePartition("contract_reward", contractRewardCalculation.Height, contractRewardCalculation.RewardDate)
+	if err != nil {
+		return err
+	}
 
+	err = insertRows(db.Sql, "contract_reward", contractRewardColumns,
+		[][]interface{}{contractRewardValues(db.ChainID, contractRewardCalculation)})
 	if err != nil {
 		return fmt.Errorf("error while saving contract reward into DB: %s, query=", err)
 	}
@@ -190,15 +283,34 @@ func (db *Database) SaveContractRewardCalculation(contractRewardCalculation type
 }
 
 func (db *Database) SaveContractRewardDistribution(contractRewardDistribution types.ContractRewardDistribution) error {
+	if db.pending != nil {
+		db.pending.distributions = append(db.pending.distributions, contractRewardDistribution)
+		return nil
+	}
+
+	return updateDistribution(db.Sql, db.ChainID, contractRewardDistribution)
+}
+
+// ensurePartition makes sure that the partition of the given table where the row having the given height and
+// timestamp is stored exists, if partitioning is enabled
+func (db *Database) ensurePartition(table string, height int64, timestamp time.Time) error {
+	if db.partitions == nil {
+		return nil
+	}
+	return db.partitions.ensure(table, height, timestamp)
+}
 
+// updateDistribution sets the distributed rewards of the reward row the given distribution refers to
+func updateDistribution(db execer, chainID string, contractRewardDistribution types.ContractRewardDistribution) error {
 	stmt := `UPDATE contract_reward SET 
 	distributed_rewards_amount = $1, leftover_rewards_amount = $2 
-	WHERE reward_address = $3 AND height = $4 `
+	WHERE chain_id = $3 AND reward_address = $4 AND height = $5 `
 
-	_, err := db.Sql.Exec(
+	_, err := db.Exec(
 		stmt,
 		contractRewardDistribution.DistributedRewards.Amount.String(),
 		contractRewardDistribution.LeftoverRewards.Amount.String(),
+		chainID,
 		contractRewardDistribution.RewardAddress,
 		contractRewardDistribution.Height,
 	)
@@ -208,15 +320,14 @@ func (db *Database) SaveContractRewardDistribution(contractRewardDistribution ty
 	return nil
 }
 
-func (db *Database) SaveGasTrackerContractMetadata(gastrackerContractMetadata types.GasTrackerContractMetadata) error {
-
-	stmt := `INSERT INTO contract_metadata 
-	(contract_address, reward_address, developer_address, collect_premium, gas_rebate_to_user, premium_percentage_charged, tx_hash, saved_at, height) 
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) 
-	ON CONFLICT DO NOTHING`
+var contractMetadataColumns = []string{
+	"chain_id", "contract_address", "reward_address", "developer_address", "collect_premium",
+	"gas_rebate_to_user", "premium_percentage_charged", "tx_hash", "saved_at", "height",
+}
 
-	_, err := db.Sql.Exec(
-		stmt,
+func contractMetadataValues(chainID string, gastrackerContractMetadata types.GasTrackerContractMetadata) []interface{} {
+	return []interface{}{
+		chainID,
 		gastrackerContractMetadata.ContractAddress,
 		gastrackerContractMetadata.Metadata.RewardAddress,
 		gastrackerContractMetadata.Metadata.DeveloperAddress,
@@ -226,15 +337,112 @@ func (db *Database) SaveGasTrackerContractMetadata(gastrackerContractMetadata ty
 		gastrackerContractMetadata.TxHash,
 		gastrackerContractMetadata.SavedAt,
 		gastrackerContractMetadata.Height,
-	)
+	}
+}
+
+func (db *Database) SaveGasTrackerContractMetadata(gastrackerContractMetadata types.GasTrackerContractMetadata) error {
+	if db.pending != nil {
+		db.pending.metadata = append(db.pending.metadata, gastrackerContractMetadata)
+		return nil
+	}
+
+	err := insertRows(db.Sql, "contract_metadata", contractMetadataColumns,
+		[][]interface{}{contractMetadataValues(db.ChainID, gastrackerContractMetadata)})
 	if err != nil {
 		return fmt.Errorf("error while saving contract metadata: %s", err)
 	}
 	return nil
 }
 
-// Close implements database.Database
+// SaveNotificationDelivery implements database.Database
+func (db *Database) SaveNotificationDelivery(delivery types.NotificationDelivery) error {
+	stmt := `
+INSERT INTO notification_delivery 
+    (chain_id, delivery_id, rule, webhook, payload, height, attempt, status_code, error, success, attempted_at) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := db.Sql.Exec(stmt,
+		db.ChainID,
+		delivery.DeliveryID,
+		delivery.Rule,
+		delivery.Webhook,
+		string(delivery.Payload),
+		delivery.Height,
+		delivery.Attempt,
+		delivery.StatusCode,
+		delivery.Error,
+		delivery.Success,
+		delivery.AttemptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving notification delivery: %s", err)
+	}
+
+	return nil
+}
+
+// SaveContractStateSnapshot implements database.Database
+func (db *Database) SaveContractStateSnapshot(snapshot types.ContractStateSnapshot) error {
+	stmt := `
+INSERT INTO contract_state_snapshot (chain_id, contract_address, snapshot_type, query, result, height, snapshot_at) 
+VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := db.Sql.Exec(stmt,
+		db.ChainID,
+		snapshot.ContractAddress,
+		snapshot.Type,
+		sql.NullString{String: string(snapshot.Query), Valid: len(snapshot.Query) > 0},
+		string(snapshot.Result),
+		snapshot.Height,
+		snapshot.SnapshotAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving contract state snapshot: %s", err)
+	}
+
+	return nil
+}
+
+// GetChainInfo implements database.Database.
+// When the database is not scoped to any chain, the identity of the first stored chain is returned.
+func (db *Database) GetChainInfo() (*types.ChainInfo, error) {
+	stmt := `SELECT chain_id, genesis_hash FROM chain_info WHERE $1 = '' OR chain_id = $1 ORDER BY created_at LIMIT 1`
+
+	var info types.ChainInfo
+	err := db.Sql.QueryRow(stmt, db.ChainID).Scan(&info.ChainID, &info.GenesisHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting chain info: %s", err)
+	}
+
+	return &info, nil
+}
+
+// SaveChainInfo implements database.Database
+func (db *Database) SaveChainInfo(info types.ChainInfo) error {
+	_, err := db.Sql.Exec(`INSERT INTO chain_info (chain_id, genesis_hash) VALUES ($1, $2)`, info.ChainID, info.GenesisHash)
+	if err != nil {
+		return fmt.Errorf("error while saving chain info: %s", err)
+	}
+
+	return nil
+}
+
+// Close implements database.Database.
+// Any buffered record is written before closing the connection.
 func (db *Database) Close() {
+	if db.batch != nil {
+		close(db.batch.stop)
+		<-db.batch.done
+
+		err := db.flush()
+		if err != nil {
+			db.Logger.Error("error while flushing batch", "err", err)
+		}
+	}
+
 	err := db.Sql.Close()
 	if err != nil {
 		db.Logger.Error("error while closing connection", "err", err)
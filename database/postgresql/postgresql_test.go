@@ -0,0 +1,39 @@
+package postgresql_test
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/database/databasetest"
+	"github.com/nuclearblock/archgregator/database/postgresql"
+	"github.com/nuclearblock/archgregator/logging"
+)
+
+// dsnEnv is the environment variable containing the connection string of the database the tests are run against.
+// The database must have been created using schema.sql, and the tests are skipped when it is not set.
+const dsnEnv = "ARCHGREGATOR_TEST_POSTGRES_DSN"
+
+// openDatabase returns a new connection to the test database, skipping the test when it is not configured
+func openDatabase(t *testing.T) *postgresql.Database {
+	dsn := os.Getenv(dsnEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", dsnEnv)
+	}
+
+	postgresDb, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+
+	db := &postgresql.Database{Sql: postgresDb, Logger: logging.DefaultLogger()}
+	t.Cleanup(db.Close)
+	return db
+}
+
+func TestSuite(t *testing.T) {
+	databasetest.RunSuite(t, func(t *testing.T) database.Database {
+		return openDatabase(t)
+	})
+}
@@ -0,0 +1,218 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/nuclearblock/archgregator/database"
+	databaseconfig "github.com/nuclearblock/archgregator/database/config"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// type check to ensure interface is properly implemented
+var _ database.Pruner = &Database{}
+
+// prunableTable describes a table whose rows can be pruned
+type prunableTable struct {
+	// timeColumn is the column compared with the maximum age of the rows
+	timeColumn string
+
+	// referenced is the condition telling whether the row r is still referenced by a live contract,
+	// in which case it must never be removed
+	referenced string
+}
+
+// prunableTables contains the tables that can be pruned, indexed by their name.
+// The wasm codes are kept as long as any contract is instantiated from them, and the most recent
+// metadata of each contract is always kept
+var prunableTables = map[string]prunableTable{
+	"wasm_code": {
+		timeColumn: "saved_at",
+		referenced: `EXISTS (SELECT 1 FROM wasm_contract c WHERE c.chain_id = r.chain_id AND c.code_id = r.code_id)`,
+	},
+	"wasm_execute_contract": {
+		timeColumn: "executed_at",
+	},
+	"contract_metadata": {
+		timeColumn: "saved_at",
+		referenced: `NOT EXISTS (SELECT 1 FROM contract_metadata n WHERE n.chain_id = r.chain_id AND n.contract_address = r.contract_address AND n.height > r.height)`,
+	},
+	"contract_reward": {
+		timeColumn: "reward_date",
+	},
+	"notification_delivery": {
+		timeColumn: "attempted_at",
+	},
+	"contract_state_snapshot": {
+		timeColumn: "snapshot_at",
+	},
+	"cw20_transfer": {
+		timeColumn: "executed_at",
+	},
+}
+
+// Prune implements database.Pruner.
+// The rows of each table are removed in batches, each one within its own statement so that the tables are not
+// locked for long, and each pruning is recorded inside the prune_log table.
+// When the database is scoped to a chain, only the rows of that chain are removed.
+func (db *Database) Prune(cfg *databaseconfig.RetentionConfig) ([]types.Pruning, error) {
+	var tables []string
+	for table := range cfg.Tables {
+		if _, ok := prunableTables[table]; !ok {
+			return nil, fmt.Errorf("table %s cannot be pruned", table)
+		}
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = databaseconfig.DefaultRetentionConfig().BatchSize
+	}
+
+	latestHeights, err := db.getLatestHeights()
+	if err != nil {
+		return nil, err
+	}
+
+	var chainIDs []string
+	for chainID := range latestHeights {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+
+	var prunings []types.Pruning
+	for _, chainID := range chainIDs {
+		for _, table := range tables {
+			retention := cfg.Tables[table]
+
+			var cutoffHeight int64
+			if retention.MaxHeights > 0 && latestHeights[chainID] > retention.MaxHeights {
+				cutoffHeight = latestHeights[chainID] - retention.MaxHeights
+			}
+
+			var cutoffTime time.Time
+			if retention.MaxAge > 0 {
+				cutoffTime = time.Now().UTC().Add(-retention.MaxAge)
+			}
+
+			if cutoffHeight == 0 && cutoffTime.IsZero() {
+				continue
+			}
+
+			rows, err := db.pruneTable(table, chainID, cutoffHeight, cutoffTime, batchSize)
+			if err != nil {
+				return prunings, err
+			}
+
+			if rows == 0 {
+				continue
+			}
+
+			pruning := types.NewPruning(chainID, table, rows, cutoffHeight, cutoffTime, time.Now().UTC())
+			err = db.savePruning(pruning)
+			if err != nil {
+				return prunings, err
+			}
+			prunings = append(prunings, pruning)
+		}
+	}
+
+	return prunings, nil
+}
+
+// getLatestHeights returns the latest indexed height of each chain
+func (db *Database) getLatestHeights() (map[string]int64, error) {
+	rows, err := db.Sql.Query(`SELECT chain_id, MAX(height) FROM block WHERE $1 = '' OR chain_id = $1 GROUP BY chain_id`, db.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting latest heights: %s", err)
+	}
+	defer rows.Close()
+
+	heights := map[string]int64{}
+	for rows.Next() {
+		var chainID string
+		var height int64
+		err = rows.Scan(&chainID, &height)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading latest heights: %s", err)
+		}
+		heights[chainID] = height
+	}
+
+	return heights, rows.Err()
+}
+
+// pruneTable removes the rows of the given table and chain having a height lower or equal to cutoffHeight,
+// or older than cutoffTime, in batches of batchSize rows, and returns the number of removed rows.
+// Each cutoff is ignored when not set
+func (db *Database) pruneTable(table string, chainID string, cutoffHeight int64, cutoffTime time.Time, batchSize int) (int64, error) {
+	prunable := prunableTables[table]
+
+	var limits []string
+	args := []interface{}{chainID}
+	if cutoffHeight > 0 {
+		args = append(args, cutoffHeight)
+		limits = append(limits, fmt.Sprintf("r.height <= $%d", len(args)))
+	}
+	if !cutoffTime.IsZero() {
+		args = append(args, cutoffTime)
+		limits = append(limits, fmt.Sprintf("r.%s < $%d", prunable.timeColumn, len(args)))
+	}
+	args = append(args, batchSize)
+
+	conditions := fmt.Sprintf("r.chain_id = $1 AND (%s)", strings.Join(limits, " OR "))
+	if prunable.referenced != "" {
+		conditions += fmt.Sprintf(" AND NOT (%s)", prunable.referenced)
+	}
+
+	// Rows are identified by their partition too, since the same ctid can be found inside different partitions
+	stmt := fmt.Sprintf(
+		`DELETE FROM %[1]s WHERE (tableoid, ctid) IN (SELECT tableoid, ctid FROM %[1]s AS r WHERE %[2]s LIMIT $%[3]d)`,
+		pq.QuoteIdentifier(table), conditions, len(args),
+	)
+
+	var pruned int64
+	for {
+		res, err := db.Sql.Exec(stmt, args...)
+		if err != nil {
+			return pruned, fmt.Errorf("error while pruning table %s: %s", table, err)
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return pruned, fmt.Errorf("error while pruning table %s: %s", table, err)
+		}
+
+		pruned += rows
+		if rows < int64(batchSize) {
+			return pruned, nil
+		}
+	}
+}
+
+// savePruning stores the details of the given pruning
+func (db *Database) savePruning(pruning types.Pruning) error {
+	stmt := `
+INSERT INTO prune_log (chain_id, table_name, pruned_rows, cutoff_height, cutoff_time, pruned_at)
+VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := db.Sql.Exec(stmt,
+		pruning.ChainID,
+		pruning.Table,
+		pruning.Rows,
+		sql.NullInt64{Int64: pruning.CutoffHeight, Valid: pruning.CutoffHeight > 0},
+		sql.NullTime{Time: pruning.CutoffTime, Valid: !pruning.CutoffTime.IsZero()},
+		pruning.PrunedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving pruning of table %s: %s", pruning.Table, err)
+	}
+
+	return nil
+}
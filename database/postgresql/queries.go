@@ -0,0 +1,437 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nuclearblock/archgregator/database"
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+)
+
+// type check to ensure interface is properly implemented
+var (
+	_ database.Reader          = &Database{}
+	_ database.RewardsStreamer = &Database{}
+)
+
+// sortColumn represents a column the results of a query are sorted by
+type sortColumn struct {
+	name string
+	desc bool
+
+	// value returns the value of the column inside a cursor
+	value func(cursor *dbtypes.Cursor) interface{}
+}
+
+var (
+	cursorHeight  = func(cursor *dbtypes.Cursor) interface{} { return cursor.Height }
+	cursorChainID = func(cursor *dbtypes.Cursor) interface{} { return cursor.ChainID }
+	cursorKey     = func(cursor *dbtypes.Cursor) interface{} { return cursor.Key }
+	cursorID      = func(cursor *dbtypes.Cursor) interface{} { return cursor.ID }
+
+	blocksOrder = []sortColumn{
+		{name: "height", desc: true, value: cursorHeight},
+		{name: "chain_id", value: cursorChainID},
+	}
+	wasmCodesOrder = []sortColumn{
+		{name: "code_id", desc: true, value: cursorID},
+		{name: "chain_id", value: cursorChainID},
+	}
+	wasmContractsOrder = []sortColumn{
+		{name: "height", desc: true, value: cursorHeight},
+		{name: "chain_id", value: cursorChainID},
+		{name: "contract_address", value: cursorKey},
+	}
+
+	// rowsOrder sorts the rows of the tables having no unique key using their id
+	rowsOrder = []sortColumn{
+		{name: "height", desc: true, value: cursorHeight},
+		{name: "id", desc: true, value: cursorID},
+	}
+
+	// rewardAddressOrder sorts the contract rewards of each reward address by height
+	rewardAddressOrder = []sortColumn{
+		{name: "reward_address", value: cursorKey},
+		{name: "height", value: cursorHeight},
+		{name: "id", value: cursorID},
+	}
+)
+
+// streamPageSize is the number of rows read by each query of a stream
+const streamPageSize = 1000
+
+// whereClause helps building the WHERE clause of a query along with its positional arguments
+type whereClause struct {
+	conditions []string
+	args       []interface{}
+}
+
+// add appends the given condition, replacing each "?" with the next positional parameter
+func (w *whereClause) add(condition string, arg interface{}) {
+	w.args = append(w.args, arg)
+	w.conditions = append(w.conditions, strings.Replace(condition, "?", fmt.Sprintf("$%d", len(w.args)), 1))
+}
+
+// addString appends the given condition only if value is not empty
+func (w *whereClause) addString(condition string, value string) {
+	if value != "" {
+		w.add(condition, value)
+	}
+}
+
+// addHeightRange appends the conditions needed to filter the given column using the provided range
+func (w *whereClause) addHeightRange(column string, heightRange dbtypes.HeightRange) {
+	if heightRange.FromHeight > 0 {
+		w.add(column+" >= ?", heightRange.FromHeight)
+	}
+	if heightRange.ToHeight > 0 {
+		w.add(column+" <= ?", heightRange.ToHeight)
+	}
+}
+
+// addAfter appends the condition selecting the rows that follow the given cursor when sorted by the given columns
+func (w *whereClause) addAfter(order []sortColumn, cursor *dbtypes.Cursor) {
+	alternatives := make([]string, len(order))
+	for i, column := range order {
+		var conditions []string
+		for _, previous := range order[:i] {
+			w.args = append(w.args, previous.value(cursor))
+			conditions = append(conditions, fmt.Sprintf("%s = $%d", previous.name, len(w.args)))
+		}
+
+		operator := ">"
+		if column.desc {
+			operator = "<"
+		}
+		w.args = append(w.args, column.value(cursor))
+		conditions = append(conditions, fmt.Sprintf("%s %s $%d", column.name, operator, len(w.args)))
+
+		alternatives[i] = "(" + strings.Join(conditions, " AND ") + ")"
+	}
+	w.conditions = append(w.conditions, "("+strings.Join(alternatives, " OR ")+")")
+}
+
+// build returns the WHERE clause followed by the ORDER BY, LIMIT and OFFSET clauses.
+// The given columns must identify each row, so that the rows following a cursor are always the same.
+func (w *whereClause) build(order []sortColumn, pagination dbtypes.Pagination) (string, []interface{}) {
+	if pagination.After != nil {
+		w.addAfter(order, pagination.After)
+	}
+
+	var clause string
+	if len(w.conditions) > 0 {
+		clause = " WHERE " + strings.Join(w.conditions, " AND ")
+	}
+
+	orderBy := make([]string, len(order))
+	for i, column := range order {
+		orderBy[i] = column.name
+		if column.desc {
+			orderBy[i] += " DESC"
+		}
+	}
+	clause += " ORDER BY " + strings.Join(orderBy, ", ")
+
+	args := w.args
+	if pagination.Limit > 0 {
+		args = append(args, pagination.Limit)
+		clause += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if pagination.Offset > 0 && pagination.After == nil {
+		args = append(args, pagination.Offset)
+		clause += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	return clause, args
+}
+
+// chainClause returns a new whereClause selecting the rows of the chain the database is scoped to, if any
+func (db *Database) chainClause() whereClause {
+	var where whereClause
+	where.addString("chain_id = ?", db.ChainID)
+	return where
+}
+
+// GetBlock implements database.Reader
+func (db *Database) GetBlock(height int64) (*dbtypes.BlockRow, error) {
+	rows, err := db.GetBlocks(
+		dbtypes.BlocksFilter{HeightRange: dbtypes.HeightRange{FromHeight: height, ToHeight: height}},
+		dbtypes.NewPagination(1, 0),
+	)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+// GetBlocks implements database.Reader
+func (db *Database) GetBlocks(filter dbtypes.BlocksFilter, pagination dbtypes.Pagination) ([]dbtypes.BlockRow, error) {
+	where := db.chainClause()
+	where.addHeightRange("height", filter.HeightRange)
+	clause, args := where.build(blocksOrder, pagination)
+
+	stmt := `SELECT chain_id, height, hash, num_txs, total_gas, timestamp FROM block` + clause
+	rows, err := db.Sql.Query(stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying blocks: %s", err)
+	}
+	defer rows.Close()
+
+	var blocks []dbtypes.BlockRow
+	for rows.Next() {
+		var row dbtypes.BlockRow
+		err = rows.Scan(&row.ChainID, &row.Height, &row.Hash, &row.TxNum, &row.TotalGas, &row.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning block: %s", err)
+		}
+		blocks = append(blocks, row)
+	}
+
+	return blocks, rows.Err()
+}
+
+// GetWasmCode implements database.Reader
+func (db *Database) GetWasmCode(codeID int64) (*dbtypes.WasmCodeRow, error) {
+	where := db.chainClause()
+	where.add("code_id = ?", codeID)
+
+	rows, err := db.queryWasmCodes(where, dbtypes.NewPagination(1, 0))
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+// GetWasmCodes implements database.Reader
+func (db *Database) GetWasmCodes(filter dbtypes.WasmCodesFilter, pagination dbtypes.Pagination) ([]dbtypes.WasmCodeRow, error) {
+	where := db.chainClause()
+	where.addHeightRange("height", filter.HeightRange)
+	where.addString("creator = ?", filter.Creator)
+	return db.queryWasmCodes(where, pagination)
+}
+
+func (db *Database) queryWasmCodes(where whereClause, pagination dbtypes.Pagination) ([]dbtypes.WasmCodeRow, error) {
+	clause, args := where.build(wasmCodesOrder, pagination)
+
+	stmt := `SELECT chain_id, creator, code_hash, code_id, size, tx_hash, saved_at, height FROM wasm_code` + clause
+	rows, err := db.Sql.Query(stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying wasm codes: %s", err)
+	}
+	defer rows.Close()
+
+	var codes []dbtypes.WasmCodeRow
+	for rows.Next() {
+		var row dbtypes.WasmCodeRow
+		err = rows.Scan(&row.ChainID, &row.Creator, &row.CodeHash, &row.CodeID, &row.Size, &row.TxHash, &row.SavedAt, &row.Height)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning wasm code: %s", err)
+		}
+		codes = append(codes, row)
+	}
+
+	return codes, rows.Err()
+}
+
+// GetWasmContract implements database.Reader
+func (db *Database) GetWasmContract(address string) (*dbtypes.WasmContractRow, error) {
+	where := db.chainClause()
+	where.add("contract_address = ?", address)
+
+	rows, err := db.queryWasmContracts(where, dbtypes.NewPagination(1, 0))
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+// GetWasmContracts implements database.Reader
+func (db *Database) GetWasmContracts(filter dbtypes.WasmContractsFilter, pagination dbtypes.Pagination) ([]dbtypes.WasmContractRow, error) {
+	where := db.chainClause()
+	where.addHeightRange("height", filter.HeightRange)
+	if filter.CodeID > 0 {
+		where.add("code_id = ?", filter.CodeID)
+	}
+	where.addString("creator = ?", filter.Creator)
+	where.addString("admin = ?", filter.Admin)
+	where.addString("label ILIKE ?", filter.Label)
+	return db.queryWasmContracts(where, pagination)
+}
+
+func (db *Database) queryWasmContracts(where whereClause, pagination dbtypes.Pagination) ([]dbtypes.WasmContractRow, error) {
+	clause, args := where.build(wasmContractsOrder, pagination)
+
+	stmt := `
+	SELECT chain_id, sender, creator, admin, code_id, COALESCE(label, ''), raw_contract_message, funds,
+	       contract_address, tx_hash, instantiated_at, height
+	FROM wasm_contract` + clause
+	rows, err := db.Sql.Query(stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying wasm contracts: %s", err)
+	}
+	defer rows.Close()
+
+	var contracts []dbtypes.WasmContractRow
+	for rows.Next() {
+		var row dbtypes.WasmContractRow
+		err = rows.Scan(
+			&row.ChainID, &row.Sender, &row.Creator, &row.Admin, &row.CodeID, &row.Label, &row.RawContractMessage, &row.Funds,
+			&row.ContractAddress, &row.TxHash, &row.InstantiatedAt, &row.Height,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning wasm contract: %s", err)
+		}
+		contracts = append(contracts, row)
+	}
+
+	return contracts, rows.Err()
+}
+
+// GetWasmExecuteContracts implements database.Reader
+func (db *Database) GetWasmExecuteContracts(
+	filter dbtypes.WasmExecuteContractsFilter, pagination dbtypes.Pagination,
+) ([]dbtypes.WasmExecuteContractRow, error) {
+	where := db.chainClause()
+	where.addHeightRange("height", filter.HeightRange)
+	where.addString("contract_address = ?", filter.ContractAddress)
+	where.addString("sender = ?", filter.Sender)
+	where.addString("tx_hash = ?", filter.TxHash)
+	clause, args := where.build(rowsOrder, pagination)
+
+	stmt := `
+	SELECT id, chain_id, sender, contract_address, raw_contract_message, funds, gas_used, fees_denom, fees_amount,
+	       tx_hash, executed_at, height
+	FROM wasm_execute_contract` + clause
+	rows, err := db.Sql.Query(stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying wasm contract executions: %s", err)
+	}
+	defer rows.Close()
+
+	var executions []dbtypes.WasmExecuteContractRow
+	for rows.Next() {
+		var row dbtypes.WasmExecuteContractRow
+		err = rows.Scan(
+			&row.ID, &row.ChainID, &row.Sender, &row.ContractAddress, &row.RawContractMessage, &row.Funds, &row.GasUsed,
+			&row.FeesDenom, &row.FeesAmount, &row.TxHash, &row.ExecutedAt, &row.Height,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning wasm contract execution: %s", err)
+		}
+		executions = append(executions, row)
+	}
+
+	return executions, rows.Err()
+}
+
+// GetContractMetadata implements database.Reader
+func (db *Database) GetContractMetadata(
+	filter dbtypes.ContractMetadataFilter, pagination dbtypes.Pagination,
+) ([]dbtypes.ContractMetadataRow, error) {
+	where := db.chainClause()
+	where.addHeightRange("height", filter.HeightRange)
+	where.addString("contract_address = ?", filter.ContractAddress)
+	where.addString("reward_address = ?", filter.RewardAddress)
+	where.addString("developer_address = ?", filter.DeveloperAddress)
+	clause, args := where.build(rowsOrder, pagination)
+
+	stmt := `
+	SELECT id, chain_id, contract_address, reward_address, developer_address, COALESCE(collect_premium, false),
+	       COALESCE(gas_rebate_to_user, false), COALESCE(premium_percentage_charged, 0), tx_hash, saved_at, height
+	FROM contract_metadata` + clause
+	rows, err := db.Sql.Query(stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying contract metadata: %s", err)
+	}
+	defer rows.Close()
+
+	var metadata []dbtypes.ContractMetadataRow
+	for rows.Next() {
+		var row dbtypes.ContractMetadataRow
+		err = rows.Scan(
+			&row.ID, &row.ChainID, &row.ContractAddress, &row.RewardAddress, &row.DeveloperAddress, &row.CollectPremium,
+			&row.GasRebateToUser, &row.PremiumPercentageCharged, &row.TxHash, &row.SavedAt, &row.Height,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning contract metadata: %s", err)
+		}
+		metadata = append(metadata, row)
+	}
+
+	return metadata, rows.Err()
+}
+
+// GetContractRewards implements database.Reader
+func (db *Database) GetContractRewards(
+	filter dbtypes.ContractRewardsFilter, pagination dbtypes.Pagination,
+) ([]dbtypes.ContractRewardRow, error) {
+	return db.queryContractRewards(filter, rowsOrder, pagination)
+}
+
+// StreamContractRewards implements database.RewardsStreamer
+func (db *Database) StreamContractRewards(
+	filter dbtypes.ContractRewardsFilter, fn func(row dbtypes.ContractRewardRow) error,
+) error {
+	var after *dbtypes.Cursor
+	for {
+		rows, err := db.queryContractRewards(filter, rewardAddressOrder, dbtypes.NewCursorPagination(streamPageSize, after))
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			err = fn(row)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < streamPageSize {
+			return nil
+		}
+
+		last := rows[len(rows)-1]
+		after = &dbtypes.Cursor{Key: last.RewardAddress, Height: last.Height, ID: last.ID}
+	}
+}
+
+// queryContractRewards returns the contract rewards matching the given filter, sorted by the given columns
+func (db *Database) queryContractRewards(
+	filter dbtypes.ContractRewardsFilter, order []sortColumn, pagination dbtypes.Pagination,
+) ([]dbtypes.ContractRewardRow, error) {
+	where := db.chainClause()
+	where.addHeightRange("height", filter.HeightRange)
+	where.addString("contract_address = ?", filter.ContractAddress)
+	where.addString("reward_address = ?", filter.RewardAddress)
+	where.addString("developer_address = ?", filter.DeveloperAddress)
+	clause, args := where.build(order, pagination)
+
+	stmt := `
+	SELECT id, chain_id, contract_address, reward_address, developer_address, COALESCE(gas_consumed, '0'), contract_rewards_denom,
+	       contract_rewards_amount, inflation_rewards_amount, distributed_rewards_amount, leftover_rewards_amount,
+	       COALESCE(gas_rebate_to_user, false), COALESCE(collect_premium, false),
+	       COALESCE(premium_percentage_charged, 0), reward_date, height
+	FROM contract_reward` + clause
+	rows, err := db.Sql.Query(stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying contract rewards: %s", err)
+	}
+	defer rows.Close()
+
+	var rewards []dbtypes.ContractRewardRow
+	for rows.Next() {
+		var row dbtypes.ContractRewardRow
+		err = rows.Scan(
+			&row.ID, &row.ChainID, &row.ContractAddress, &row.RewardAddress, &row.DeveloperAddress, &row.GasConsumed,
+			&row.ContractRewardsDenom, &row.ContractRewardsAmount, &row.InflationRewardsAmount,
+			&row.DistributedRewardsAmount, &row.LeftoverRewardsAmount, &row.GasRebateToUser,
+			&row.CollectPremium, &row.PremiumPercentageCharged, &row.RewardDate, &row.Height,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error while scanning contract reward: %s", err)
+		}
+		rewards = append(rewards, row)
+	}
+
+	return rewards, rows.Err()
+}
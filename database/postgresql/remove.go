@@ -0,0 +1,115 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/nuclearblock/archgregator/database"
+)
+
+// type check to ensure interface is properly implemented
+var _ database.HeightRemover = &Database{}
+
+// RemoveHeight implements database.HeightRemover.
+// All the records are removed within a single transaction. Since the gastracker rewards contained inside a block
+// refer to the previous height, the rewards stored at the previous height are removed too, while the CW20 transfers
+// of the height are reverted from the stored balances.
+// When the aggregate tables are enabled, the aggregates of the days of the removed records are rebuilt without them,
+// so that they are only counted once the height has been parsed again.
+func (db *Database) RemoveHeight(height int64) error {
+	tx, err := db.Sql.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting remove height transaction: %s", err)
+	}
+	defer tx.Rollback() // nolint
+
+	var days []time.Time
+	if db.Aggregates {
+		days, err = aggregatedDays(tx, db.ChainID, height)
+		if err != nil {
+			return fmt.Errorf("error while getting the aggregated days of height %d: %s", height, err)
+		}
+	}
+
+	// The CW20 transfers of the height are reverted from the balances before being removed
+	_, err = tx.Exec(`
+WITH deltas AS (
+    SELECT contract_address, to_address AS address, -amount AS delta FROM cw20_transfer
+    WHERE chain_id = $1 AND height = $2 AND to_address <> ''
+    UNION ALL
+    SELECT contract_address, from_address AS address, amount AS delta FROM cw20_transfer
+    WHERE chain_id = $1 AND height = $2 AND from_address <> ''
+), totals AS (
+    SELECT contract_address, address, SUM(delta) AS delta FROM deltas GROUP BY contract_address, address
+)
+UPDATE cw20_balance b SET balance = b.balance + t.delta
+FROM totals t
+WHERE b.chain_id = $1 AND b.contract_address = t.contract_address AND b.address = t.address`, db.ChainID, height)
+	if err != nil {
+		return fmt.Errorf("error while reverting cw20 balances of height %d: %s", height, err)
+	}
+
+	stmts := []struct {
+		table  string
+		height int64
+	}{
+		{"block", height},
+		{"wasm_code", height},
+		{"wasm_contract", height},
+		{"wasm_execute_contract", height},
+		{"contract_metadata", height},
+		{"contract_reward", height - 1},
+		{"cw20_token", height},
+		{"cw20_transfer", height},
+	}
+
+	for _, stmt := range stmts {
+		_, err = tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE chain_id = $1 AND height = $2`, stmt.table), db.ChainID, stmt.height)
+		if err != nil {
+			return fmt.Errorf("error while removing height %d from %s: %s", height, stmt.table, err)
+		}
+	}
+
+	for _, day := range days {
+		err = rebuildAggregates(tx, db.ChainID, day, day.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error while committing remove height transaction: %s", err)
+	}
+
+	return nil
+}
+
+// aggregatedDays returns the days of the records stored for the given height of the given chain that have been
+// added to the aggregate tables
+func aggregatedDays(tx *sql.Tx, chainID string, height int64) ([]time.Time, error) {
+	rows, err := tx.Query(`
+SELECT executed_at::DATE AS day FROM wasm_execute_contract WHERE chain_id = $1 AND height = $2
+UNION
+SELECT saved_at::DATE FROM contract_metadata WHERE chain_id = $1 AND height = $2
+UNION
+SELECT reward_date::DATE FROM contract_reward WHERE chain_id = $1 AND height = $3
+ORDER BY day`, chainID, height, height-1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []time.Time
+	for rows.Next() {
+		var day time.Time
+		err = rows.Scan(&day)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+
+	return days, rows.Err()
+}
@@ -6,11 +6,14 @@ CREATE TYPE COIN AS
 
 CREATE TABLE block
 (
-    height           BIGINT UNIQUE PRIMARY KEY,
-    hash             TEXT NOT NULL UNIQUE,
+    chain_id         TEXT NOT NULL DEFAULT '',
+    height           BIGINT NOT NULL,
+    hash             TEXT NOT NULL,
     num_txs          INTEGER DEFAULT 0,
     total_gas        BIGINT  DEFAULT 0,
-    timestamp        TIMESTAMP WITHOUT TIME ZONE NOT NULL
+    timestamp        TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    PRIMARY KEY (chain_id, height),
+    UNIQUE (chain_id, hash)
 );
 CREATE INDEX block_height_index ON block (height);
 CREATE INDEX block_hash_index ON block (hash);
@@ -18,19 +21,23 @@ CREATE INDEX block_hash_index ON block (hash);
 
 CREATE TABLE wasm_code
 (
+    chain_id                TEXT            NOT NULL DEFAULT '',
     creator                 TEXT            NOT NULL,
     code_hash               TEXT            NOT NULL,
-    code_id                 BIGINT          NOT NULL UNIQUE,
+    code_id                 BIGINT          NOT NULL,
     size                    INT             NOT NULL,
     tx_hash                 TEXT            NOT NULL,
     saved_at                TIMESTAMP       NOT NULL,
-    height                  BIGINT          NOT NULL
+    height                  BIGINT          NOT NULL,
+    UNIQUE (chain_id, code_id)
 );
+CREATE INDEX wasm_code_chain_id_height_index ON wasm_code (chain_id, height);
 CREATE INDEX wasm_code_height_index ON wasm_code (height);
 
 
 CREATE TABLE wasm_contract
 (
+    chain_id                TEXT            NOT NULL DEFAULT '',
     sender                  TEXT            NOT NULL,
     creator                 TEXT            NOT NULL,
     admin                   TEXT            NOT NULL DEFAULT '',
@@ -38,11 +45,13 @@ CREATE TABLE wasm_contract
     label                   TEXT            NULL,
     raw_contract_message    JSONB           NOT NULL DEFAULT '{}'::JSONB,
     funds                   COIN[]          NOT NULL DEFAULT '{}',
-    contract_address        TEXT            NOT NULL UNIQUE,
+    contract_address        TEXT            NOT NULL,
     tx_hash                 TEXT            NOT NULL,
     instantiated_at         TIMESTAMP       NOT NULL,
-    height                  BIGINT          NOT NULL
+    height                  BIGINT          NOT NULL,
+    UNIQUE (chain_id, contract_address)
 );
+CREATE INDEX wasm_contract_chain_id_height_index ON wasm_contract (chain_id, height);
 CREATE INDEX wasm_contract_height_index ON wasm_contract (height);
 CREATE INDEX wasm_contract_creator ON wasm_contract (creator);
 CREATE INDEX wasm_contract_contract_address ON wasm_contract (contract_address);
@@ -50,6 +59,8 @@ CREATE INDEX wasm_contract_contract_address ON wasm_contract (contract_address);
 
 CREATE TABLE wasm_execute_contract
 (
+    id                      BIGSERIAL       NOT NULL,
+    chain_id                TEXT            NOT NULL DEFAULT '',
     sender                  TEXT            NOT NULL,
     contract_address        TEXT            NOT NULL,
     raw_contract_message    JSONB           NOT NULL DEFAULT '{}'::JSONB,
@@ -61,13 +72,61 @@ CREATE TABLE wasm_execute_contract
     executed_at             TIMESTAMP       NOT NULL,
     height                  BIGINT          NOT NULL
 );
+CREATE INDEX execute_contract_chain_id_height_index ON wasm_execute_contract (chain_id, height);
 CREATE INDEX execute_contract_height_index ON wasm_execute_contract (height);
 CREATE INDEX execute_contract_executed_at_index ON wasm_execute_contract (executed_at);
 CREATE INDEX execute_contract_contract_address ON wasm_execute_contract (contract_address);
 
 
+CREATE TABLE contract_daily_stats
+(
+    chain_id         TEXT             NOT NULL DEFAULT '',
+    contract_address TEXT             NOT NULL,
+    day              DATE             NOT NULL,
+    executions       BIGINT           NOT NULL DEFAULT 0,
+    unique_senders   BIGINT           NOT NULL DEFAULT 0,
+    total_gas        BIGINT           NOT NULL DEFAULT 0,
+    total_fees       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (chain_id, contract_address, day)
+);
+CREATE INDEX contract_daily_stats_day_index ON contract_daily_stats (day);
+
+CREATE TABLE contract_daily_sender
+(
+    chain_id         TEXT NOT NULL DEFAULT '',
+    contract_address TEXT NOT NULL,
+    day              DATE NOT NULL,
+    sender           TEXT NOT NULL,
+    PRIMARY KEY (chain_id, contract_address, day, sender)
+);
+
+CREATE TABLE contract_hourly_stats
+(
+    chain_id         TEXT             NOT NULL DEFAULT '',
+    contract_address TEXT             NOT NULL,
+    hour             TIMESTAMP        NOT NULL,
+    executions       BIGINT           NOT NULL DEFAULT 0,
+    unique_senders   BIGINT           NOT NULL DEFAULT 0,
+    total_gas        BIGINT           NOT NULL DEFAULT 0,
+    total_fees       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (chain_id, contract_address, hour)
+);
+CREATE INDEX contract_hourly_stats_hour_index ON contract_hourly_stats (hour);
+
+CREATE TABLE contract_hourly_sender
+(
+    chain_id         TEXT      NOT NULL DEFAULT '',
+    contract_address TEXT      NOT NULL,
+    hour             TIMESTAMP NOT NULL,
+    sender           TEXT      NOT NULL,
+    PRIMARY KEY (chain_id, contract_address, hour, sender)
+);
+
+
 CREATE TABLE contract_metadata
 (
+    id                         BIGSERIAL NOT NULL,
+    chain_id                   TEXT    NOT NULL DEFAULT '',
     contract_address           TEXT    NOT NULL,
     reward_address             TEXT    NOT NULL,
     developer_address          TEXT    NOT NULL,
@@ -78,6 +137,7 @@ CREATE TABLE contract_metadata
     saved_at                   TIMESTAMP  NOT NULL,
     height                     BIGINT  NOT NULL
 );
+CREATE INDEX contract_metadata_chain_id_height_index ON contract_metadata (chain_id, height);
 CREATE INDEX contract_metadata_height_index ON contract_metadata (height);
 CREATE INDEX contract_metadata_contract_address_index ON contract_metadata (contract_address);
 CREATE INDEX contract_metadata_developer_address_index ON contract_metadata (developer_address);
@@ -86,6 +146,8 @@ CREATE INDEX contract_metadata_reward_address_index ON contract_metadata (reward
 
 CREATE TABLE contract_reward
 (
+    id                         BIGSERIAL NOT NULL,
+    chain_id                   TEXT    NOT NULL DEFAULT '',
     contract_address           TEXT    NOT NULL,
     reward_address             TEXT    NOT NULL,
     developer_address          TEXT    NOT NULL,
@@ -101,7 +163,159 @@ CREATE TABLE contract_reward
     reward_date                TIMESTAMP  NOT NULL,
     height                     BIGINT  NOT NULL
 );
+CREATE INDEX contract_reward_chain_id_height_index ON contract_reward (chain_id, height);
+CREATE INDEX contract_reward_height_index ON contract_reward (height);
 CREATE INDEX contract_reward_reward_date_index ON contract_reward (reward_date);
 CREATE INDEX contract_reward_contract_address_index ON contract_reward (contract_address);
 CREATE INDEX contract_reward_developer_address_index ON contract_reward (developer_address);
-CREATE INDEX contract_reward_reward_address_index ON contract_reward (reward_address);
\ No newline at end of file
+CREATE INDEX contract_reward_reward_address_index ON contract_reward (reward_address);
+
+
+CREATE TABLE developer_daily_rewards
+(
+    chain_id            TEXT             NOT NULL DEFAULT '',
+    developer_address   TEXT             NOT NULL,
+    day                 DATE             NOT NULL,
+    contracts           BIGINT           NOT NULL DEFAULT 0,
+    metadata_updates    BIGINT           NOT NULL DEFAULT 0,
+    gas_consumed        NUMERIC          NOT NULL DEFAULT 0,
+    contract_rewards    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    inflation_rewards   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    distributed_rewards DOUBLE PRECISION NOT NULL DEFAULT 0,
+    leftover_rewards    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (chain_id, developer_address, day)
+);
+CREATE INDEX developer_daily_rewards_day_index ON developer_daily_rewards (day);
+
+CREATE TABLE reward_address_daily_rewards
+(
+    chain_id            TEXT             NOT NULL DEFAULT '',
+    reward_address      TEXT             NOT NULL,
+    day                 DATE             NOT NULL,
+    contracts           BIGINT           NOT NULL DEFAULT 0,
+    metadata_updates    BIGINT           NOT NULL DEFAULT 0,
+    gas_consumed        NUMERIC          NOT NULL DEFAULT 0,
+    contract_rewards    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    inflation_rewards   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    distributed_rewards DOUBLE PRECISION NOT NULL DEFAULT 0,
+    leftover_rewards    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (chain_id, reward_address, day)
+);
+CREATE INDEX reward_address_daily_rewards_day_index ON reward_address_daily_rewards (day);
+
+CREATE TABLE reward_daily_contract
+(
+    chain_id          TEXT NOT NULL DEFAULT '',
+    day               DATE NOT NULL,
+    contract_address  TEXT NOT NULL,
+    developer_address TEXT NOT NULL,
+    reward_address    TEXT NOT NULL,
+    PRIMARY KEY (chain_id, day, contract_address, developer_address, reward_address)
+);
+CREATE INDEX reward_daily_contract_developer_address_index ON reward_daily_contract (chain_id, developer_address, day);
+CREATE INDEX reward_daily_contract_reward_address_index ON reward_daily_contract (chain_id, reward_address, day);
+
+
+CREATE TABLE notification_delivery
+(
+    id           SERIAL    PRIMARY KEY,
+    chain_id     TEXT      NOT NULL DEFAULT '',
+    delivery_id  TEXT      NOT NULL,
+    rule         TEXT      NOT NULL,
+    webhook      TEXT      NOT NULL,
+    payload      JSONB     NOT NULL,
+    height       BIGINT    NOT NULL,
+    attempt      INTEGER   NOT NULL,
+    status_code  INTEGER,
+    error        TEXT,
+    success      BOOLEAN   NOT NULL,
+    attempted_at TIMESTAMP NOT NULL
+);
+CREATE INDEX notification_delivery_chain_id_height_index ON notification_delivery (chain_id, height);
+CREATE INDEX notification_delivery_delivery_id_index ON notification_delivery (delivery_id);
+CREATE INDEX notification_delivery_rule_index ON notification_delivery (rule);
+
+
+CREATE TABLE contract_state_snapshot
+(
+    id               SERIAL    PRIMARY KEY,
+    chain_id         TEXT      NOT NULL DEFAULT '',
+    contract_address TEXT      NOT NULL,
+    snapshot_type    TEXT      NOT NULL,
+    query            JSONB,
+    result           JSONB     NOT NULL,
+    height           BIGINT    NOT NULL,
+    snapshot_at      TIMESTAMP NOT NULL
+);
+CREATE INDEX contract_state_snapshot_chain_id_height_index ON contract_state_snapshot (chain_id, height);
+CREATE INDEX contract_state_snapshot_contract_address_index ON contract_state_snapshot (chain_id, contract_address, height);
+
+
+CREATE TABLE cw20_token
+(
+    chain_id         TEXT      NOT NULL DEFAULT '',
+    contract_address TEXT      NOT NULL,
+    name             TEXT      NOT NULL,
+    symbol           TEXT      NOT NULL,
+    decimals         INTEGER   NOT NULL,
+    minter           TEXT,
+    tx_hash          TEXT      NOT NULL,
+    saved_at         TIMESTAMP NOT NULL,
+    height           BIGINT    NOT NULL,
+    UNIQUE (chain_id, contract_address)
+);
+CREATE INDEX cw20_token_chain_id_height_index ON cw20_token (chain_id, height);
+
+-- amounts are stored as NUMERIC, since CW20 amounts are 128 bits integers
+CREATE TABLE cw20_transfer
+(
+    chain_id         TEXT      NOT NULL DEFAULT '',
+    contract_address TEXT      NOT NULL,
+    action           TEXT      NOT NULL,
+    from_address     TEXT      NOT NULL DEFAULT '',
+    to_address       TEXT      NOT NULL DEFAULT '',
+    by_address       TEXT      NOT NULL DEFAULT '',
+    amount           NUMERIC   NOT NULL,
+    tx_hash          TEXT      NOT NULL,
+    msg_index        INTEGER   NOT NULL,
+    event_index      INTEGER   NOT NULL,
+    executed_at      TIMESTAMP NOT NULL,
+    height           BIGINT    NOT NULL,
+    UNIQUE (chain_id, tx_hash, msg_index, event_index)
+);
+CREATE INDEX cw20_transfer_chain_id_height_index ON cw20_transfer (chain_id, height);
+CREATE INDEX cw20_transfer_contract_address_index ON cw20_transfer (chain_id, contract_address, height);
+CREATE INDEX cw20_transfer_from_address_index ON cw20_transfer (chain_id, from_address, height);
+CREATE INDEX cw20_transfer_to_address_index ON cw20_transfer (chain_id, to_address, height);
+
+CREATE TABLE cw20_balance
+(
+    chain_id         TEXT    NOT NULL DEFAULT '',
+    contract_address TEXT    NOT NULL,
+    address          TEXT    NOT NULL,
+    balance          NUMERIC NOT NULL,
+    height           BIGINT  NOT NULL,
+    PRIMARY KEY (chain_id, contract_address, address)
+);
+CREATE INDEX cw20_balance_address_index ON cw20_balance (chain_id, address);
+
+
+CREATE TABLE chain_info
+(
+    chain_id     TEXT      NOT NULL PRIMARY KEY,
+    genesis_hash TEXT      NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+
+CREATE TABLE prune_log
+(
+    id            SERIAL    PRIMARY KEY,
+    chain_id      TEXT      NOT NULL DEFAULT '',
+    table_name    TEXT      NOT NULL,
+    pruned_rows   BIGINT    NOT NULL,
+    cutoff_height BIGINT,
+    cutoff_time   TIMESTAMP,
+    pruned_at     TIMESTAMP NOT NULL
+);
+CREATE INDEX prune_log_chain_id_table_name_index ON prune_log (chain_id, table_name);
@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// HasCw20Token implements database.Database
+func (db *Database) HasCw20Token(contractAddress string) (bool, error) {
+	var res bool
+	err := db.Sql.QueryRow(`SELECT EXISTS(SELECT 1 FROM cw20_token WHERE chain_id = ? AND contract_address = ?);`,
+		db.ChainID, contractAddress).Scan(&res)
+	return res, err
+}
+
+// SaveCw20Token implements database.Database
+func (db *Database) SaveCw20Token(token types.Cw20Token) error {
+	stmt := `
+INSERT INTO cw20_token (chain_id, contract_address, name, symbol, decimals, minter, tx_hash, saved_at, height)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT DO NOTHING`
+
+	_, err := db.Sql.Exec(stmt,
+		db.ChainID, token.ContractAddress, token.Name, token.Symbol, token.Decimals, token.Minter,
+		token.TxHash, token.SavedAt, token.Height,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving cw20 token: %s", err)
+	}
+
+	return nil
+}
+
+// SaveCw20Transfer implements database.Database.
+// Since SQLite cannot sum 128 bits integers, the balances are updated in Go within the same transaction
+// inserting the transfer, and only when the transfer was not stored already.
+func (db *Database) SaveCw20Transfer(transfer types.Cw20Transfer) error {
+	tx, err := db.Sql.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting cw20 transfer transaction: %s", err)
+	}
+	defer tx.Rollback() // nolint
+
+	stmt := `
+INSERT INTO cw20_transfer (chain_id, contract_address, action, from_address, to_address, by_address, amount,
+                           tx_hash, msg_index, event_index, executed_at, height)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT DO NOTHING`
+
+	res, err := tx.Exec(stmt,
+		db.ChainID, transfer.ContractAddress, transfer.Action, transfer.From, transfer.To, transfer.By,
+		transfer.Amount.String(), transfer.TxHash, transfer.MsgIndex, transfer.EventIndex,
+		transfer.ExecutedAt, transfer.Height,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving cw20 transfer: %s", err)
+	}
+
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error while saving cw20 transfer: %s", err)
+	}
+	if inserted == 0 {
+		return nil
+	}
+
+	err = db.addCw20Balance(tx, transfer.ContractAddress, transfer.To, transfer.Amount, transfer.Height)
+	if err != nil {
+		return err
+	}
+
+	err = db.addCw20Balance(tx, transfer.ContractAddress, transfer.From, transfer.Amount.Neg(), transfer.Height)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error while committing cw20 transfer transaction: %s", err)
+	}
+
+	return nil
+}
+
+// addCw20Balance adds the given amount to the balance of the given address, if any
+func (db *Database) addCw20Balance(tx *sql.Tx, contractAddress, address string, amount sdk.Int, height int64) error {
+	if address == "" {
+		return nil
+	}
+
+	var stored string
+	var storedHeight int64
+	err := tx.QueryRow(`SELECT balance, height FROM cw20_balance WHERE chain_id = ? AND contract_address = ? AND address = ?`,
+		db.ChainID, contractAddress, address).Scan(&stored, &storedHeight)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error while getting cw20 balance: %s", err)
+	}
+
+	balance := sdk.ZeroInt()
+	if err == nil {
+		var ok bool
+		balance, ok = sdk.NewIntFromString(stored)
+		if !ok {
+			return fmt.Errorf("invalid cw20 balance of %s for %s: %s", address, contractAddress, stored)
+		}
+	}
+
+	if storedHeight > height {
+		height = storedHeight
+	}
+
+	stmt := `
+INSERT INTO cw20_balance (chain_id, contract_address, address, balance, height)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (chain_id, contract_address, address) DO UPDATE
+    SET balance = excluded.balance, height = excluded.height`
+
+	_, err = tx.Exec(stmt, db.ChainID, contractAddress, address, balance.Add(amount).String(), height)
+	if err != nil {
+		return fmt.Errorf("error while saving cw20 balance: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+-- Same schema as database/postgresql/schema.sql.
+-- COIN[] columns contain a JSON array of {"denom", "amount"} objects, and JSONB columns contain JSON text.
+
+CREATE TABLE IF NOT EXISTS block
+(
+    chain_id         TEXT NOT NULL DEFAULT '',
+    height           BIGINT NOT NULL,
+    hash             TEXT NOT NULL,
+    num_txs          INTEGER DEFAULT 0,
+    total_gas        BIGINT  DEFAULT 0,
+    timestamp        TIMESTAMP NOT NULL,
+    PRIMARY KEY (chain_id, height),
+    UNIQUE (chain_id, hash)
+);
+CREATE INDEX IF NOT EXISTS block_hash_index ON block (hash);
+
+
+CREATE TABLE IF NOT EXISTS wasm_code
+(
+    chain_id                TEXT            NOT NULL DEFAULT '',
+    creator                 TEXT            NOT NULL,
+    code_hash               TEXT            NOT NULL,
+    code_id                 BIGINT          NOT NULL,
+    size                    INT             NOT NULL,
+    tx_hash                 TEXT            NOT NULL,
+    saved_at                TIMESTAMP       NOT NULL,
+    height                  BIGINT          NOT NULL,
+    UNIQUE (chain_id, code_id)
+);
+CREATE INDEX IF NOT EXISTS wasm_code_chain_id_height_index ON wasm_code (chain_id, height);
+CREATE INDEX IF NOT EXISTS wasm_code_height_index ON wasm_code (height);
+
+
+CREATE TABLE IF NOT EXISTS wasm_contract
+(
+    chain_id                TEXT            NOT NULL DEFAULT '',
+    sender                  TEXT            NOT NULL,
+    creator                 TEXT            NOT NULL,
+    admin                   TEXT            NOT NULL DEFAULT '',
+    code_id                 BIGINT          NOT NULL,
+    label                   TEXT            NULL,
+    raw_contract_message    TEXT            NOT NULL DEFAULT '{}',
+    funds                   TEXT            NOT NULL DEFAULT '[]',
+    contract_address        TEXT            NOT NULL,
+    tx_hash                 TEXT            NOT NULL,
+    instantiated_at         TIMESTAMP       NOT NULL,
+    height                  BIGINT          NOT NULL,
+    UNIQUE (chain_id, contract_address)
+);
+CREATE INDEX IF NOT EXISTS wasm_contract_chain_id_height_index ON wasm_contract (chain_id, height);
+CREATE INDEX IF NOT EXISTS wasm_contract_height_index ON wasm_contract (height);
+CREATE INDEX IF NOT EXISTS wasm_contract_creator ON wasm_contract (creator);
+
+
+CREATE TABLE IF NOT EXISTS wasm_execute_contract
+(
+    id                      INTEGER PRIMARY KEY,
+    chain_id                TEXT            NOT NULL DEFAULT '',
+    sender                  TEXT            NOT NULL,
+    contract_address        TEXT            NOT NULL,
+    raw_contract_message    TEXT            NOT NULL DEFAULT '{}',
+    funds                   TEXT            NOT NULL DEFAULT '[]',
+    gas_used                BIGINT          NOT NULL,
+    fees_denom              TEXT            NOT NULL,
+    fees_amount             DOUBLE PRECISION NOT NULL DEFAULT 0,
+    tx_hash                 TEXT            NOT NULL,
+    executed_at             TIMESTAMP       NOT NULL,
+    height                  BIGINT          NOT NULL
+);
+CREATE INDEX IF NOT EXISTS execute_contract_chain_id_height_index ON wasm_execute_contract (chain_id, height);
+CREATE INDEX IF NOT EXISTS execute_contract_height_index ON wasm_execute_contract (height);
+CREATE INDEX IF NOT EXISTS execute_contract_executed_at_index ON wasm_execute_contract (executed_at);
+CREATE INDEX IF NOT EXISTS execute_contract_contract_address ON wasm_execute_contract (contract_address);
+
+
+CREATE TABLE IF NOT EXISTS contract_metadata
+(
+    id                         INTEGER PRIMARY KEY,
+    chain_id                   TEXT    NOT NULL DEFAULT '',
+    contract_address           TEXT    NOT NULL,
+    reward_address             TEXT    NOT NULL,
+    developer_address          TEXT    NOT NULL,
+    collect_premium            BOOLEAN,
+    gas_rebate_to_user         BOOLEAN,
+    premium_percentage_charged BIGINT,
+    tx_hash                    TEXT    NOT NULL,
+    saved_at                   TIMESTAMP  NOT NULL,
+    height                     BIGINT  NOT NULL
+);
+CREATE INDEX IF NOT EXISTS contract_metadata_chain_id_height_index ON contract_metadata (chain_id, height);
+CREATE INDEX IF NOT EXISTS contract_metadata_height_index ON contract_metadata (height);
+CREATE INDEX IF NOT EXISTS contract_metadata_contract_address_index ON contract_metadata (contract_address);
+CREATE INDEX IF NOT EXISTS contract_metadata_developer_address_index ON contract_metadata (developer_address);
+CREATE INDEX IF NOT EXISTS contract_metadata_reward_address_index ON contract_metadata (reward_address);
+
+
+CREATE TABLE IF NOT EXISTS contract_reward
+(
+    id                         INTEGER PRIMARY KEY,
+    chain_id                   TEXT    NOT NULL DEFAULT '',
+    contract_address           TEXT    NOT NULL,
+    reward_address             TEXT    NOT NULL,
+    developer_address          TEXT    NOT NULL,
+    gas_consumed               TEXT    DEFAULT 0,
+    contract_rewards_denom     TEXT    NOT NULL,
+    contract_rewards_amount    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    inflation_rewards_amount   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    distributed_rewards_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+    leftover_rewards_amount    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    gas_rebate_to_user         BOOLEAN,
+    collect_premium            BOOLEAN,
+    premium_percentage_charged BIGINT,
+    reward_date                TIMESTAMP  NOT NULL,
+    height                     BIGINT  NOT NULL
+);
+CREATE INDEX IF NOT EXISTS contract_reward_chain_id_height_index ON contract_reward (chain_id, height);
+CREATE INDEX IF NOT EXISTS contract_reward_height_index ON contract_reward (height);
+CREATE INDEX IF NOT EXISTS contract_reward_reward_date_index ON contract_reward (reward_date);
+CREATE INDEX IF NOT EXISTS contract_reward_contract_address_index ON contract_reward (contract_address);
+CREATE INDEX IF NOT EXISTS contract_reward_developer_address_index ON contract_reward (developer_address);
+CREATE INDEX IF NOT EXISTS contract_reward_reward_address_index ON contract_reward (reward_address);
+
+
+CREATE TABLE IF NOT EXISTS notification_delivery
+(
+    id           INTEGER   PRIMARY KEY AUTOINCREMENT,
+    chain_id     TEXT      NOT NULL DEFAULT '',
+    delivery_id  TEXT      NOT NULL,
+    rule         TEXT      NOT NULL,
+    webhook      TEXT      NOT NULL,
+    payload      TEXT      NOT NULL,
+    height       BIGINT    NOT NULL,
+    attempt      INTEGER   NOT NULL,
+    status_code  INTEGER,
+    error        TEXT,
+    success      BOOLEAN   NOT NULL,
+    attempted_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS notification_delivery_chain_id_height_index ON notification_delivery (chain_id, height);
+CREATE INDEX IF NOT EXISTS notification_delivery_delivery_id_index ON notification_delivery (delivery_id);
+CREATE INDEX IF NOT EXISTS notification_delivery_rule_index ON notification_delivery (rule);
+
+
+CREATE TABLE IF NOT EXISTS contract_state_snapshot
+(
+    id               INTEGER   PRIMARY KEY AUTOINCREMENT,
+    chain_id         TEXT      NOT NULL DEFAULT '',
+    contract_address TEXT      NOT NULL,
+    snapshot_type    TEXT      NOT NULL,
+    query            TEXT,
+    result           TEXT      NOT NULL,
+    height           BIGINT    NOT NULL,
+    snapshot_at      TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS contract_state_snapshot_chain_id_height_index ON contract_state_snapshot (chain_id, height);
+CREATE INDEX IF NOT EXISTS contract_state_snapshot_contract_address_index ON contract_state_snapshot (chain_id, contract_address, height);
+
+
+CREATE TABLE IF NOT EXISTS cw20_token
+(
+    chain_id         TEXT      NOT NULL DEFAULT '',
+    contract_address TEXT      NOT NULL,
+    name             TEXT      NOT NULL,
+    symbol           TEXT      NOT NULL,
+    decimals         INTEGER   NOT NULL,
+    minter           TEXT,
+    tx_hash          TEXT      NOT NULL,
+    saved_at         TIMESTAMP NOT NULL,
+    height           BIGINT    NOT NULL,
+    UNIQUE (chain_id, contract_address)
+);
+CREATE INDEX IF NOT EXISTS cw20_token_chain_id_height_index ON cw20_token (chain_id, height);
+
+-- amounts are stored as TEXT, since CW20 amounts are 128 bits integers
+CREATE TABLE IF NOT EXISTS cw20_transfer
+(
+    chain_id         TEXT      NOT NULL DEFAULT '',
+    contract_address TEXT      NOT NULL,
+    action           TEXT      NOT NULL,
+    from_address     TEXT      NOT NULL DEFAULT '',
+    to_address       TEXT      NOT NULL DEFAULT '',
+    by_address       TEXT      NOT NULL DEFAULT '',
+    amount           TEXT      NOT NULL,
+    tx_hash          TEXT      NOT NULL,
+    msg_index        INTEGER   NOT NULL,
+    event_index      INTEGER   NOT NULL,
+    executed_at      TIMESTAMP NOT NULL,
+    height           BIGINT    NOT NULL,
+    UNIQUE (chain_id, tx_hash, msg_index, event_index)
+);
+CREATE INDEX IF NOT EXISTS cw20_transfer_chain_id_height_index ON cw20_transfer (chain_id, height);
+CREATE INDEX IF NOT EXISTS cw20_transfer_contract_address_index ON cw20_transfer (chain_id, contract_address, height);
+CREATE INDEX IF NOT EXISTS cw20_transfer_from_address_index ON cw20_transfer (chain_id, from_address, height);
+CREATE INDEX IF NOT EXISTS cw20_transfer_to_address_index ON cw20_transfer (chain_id, to_address, height);
+
+CREATE TABLE IF NOT EXISTS cw20_balance
+(
+    chain_id         TEXT    NOT NULL DEFAULT '',
+    contract_address TEXT    NOT NULL,
+    address          TEXT    NOT NULL,
+    balance          TEXT    NOT NULL,
+    height           BIGINT  NOT NULL,
+    PRIMARY KEY (chain_id, contract_address, address)
+);
+CREATE INDEX IF NOT EXISTS cw20_balance_address_index ON cw20_balance (chain_id, address);
+
+
+CREATE TABLE IF NOT EXISTS chain_info
+(
+    chain_id     TEXT      NOT NULL PRIMARY KEY,
+    genesis_hash TEXT      NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,540 @@
+package sqlite
+
+import (
+	"database/sql"
+	_ "embed" // nolint
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/archway-network/archway/app/params"
+
+	_ "github.com/mattn/go-sqlite3" // nolint
+
+	"github.com/nuclearblock/archgregator/database"
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+	"github.com/nuclearblock/archgregator/logging"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// schema contains the statements creating all the tables, executed each time the database is opened
+//
+//go:embed schema.sql
+var schema string
+
+// Builder opens the SQLite database stored inside the file specified in the config, creating it
+// along with its tables if they do not exist yet. It returns a database handle or an error if the
+// database cannot be opened.
+func Builder(ctx *database.Context) (database.Database, error) {
+	if ctx.Cfg.Path == "" {
+		return nil, fmt.Errorf("sqlite database path cannot be empty")
+	}
+
+	// WAL mode and busy timeout allow the concurrent workers and readers to share the database
+	connStr := fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on", ctx.Cfg.Path)
+	sqliteDb, err := sql.Open("sqlite3", connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite only supports one writer at a time
+	sqliteDb.SetMaxOpenConns(1)
+
+	_, err = sqliteDb.Exec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating sqlite schema: %s", err)
+	}
+
+	return &Database{
+		Sql:            sqliteDb,
+		EncodingConfig: ctx.EncodingConfig,
+		Logger:         ctx.Logger,
+	}, nil
+}
+
+// type check to ensure interface is properly implemented
+var _ database.Database = &Database{}
+
+// Database defines a wrapper around a SQLite database and implements functionality
+// for data aggregation and exporting.
+type Database struct {
+	Sql            *sql.DB
+	EncodingConfig *params.EncodingConfig
+	Logger         logging.Logger
+
+	// ChainID is the id of the chain all the data is stored for and read from.
+	// When empty, the data of all the chains is read
+	ChainID string
+
+	// pending contains the records of the block being processed that are only written once it is committed.
+	// It is nil when no block is being processed, in which case each record is written right away.
+	pending *blockRows
+}
+
+// blockRows contains the records of a block that are written along with the block itself, inside a single
+// transaction, so that the block is never reported as stored while some of its records are missing.
+// They are the records of the tables having no unique key, which are skipped when the block is written again.
+type blockRows struct {
+	block         *types.Block
+	executions    []types.WasmExecuteContract
+	rewards       []types.ContractRewardCalculation
+	distributions []types.ContractRewardDistribution
+	metadata      []types.GasTrackerContractMetadata
+}
+
+// execer represents either a database connection or a transaction
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// marshalCoins returns the JSON representation of the given coins, which is used in place of COIN[]
+func marshalCoins(coins dbtypes.DbCoins) (string, error) {
+	bz, err := json.Marshal(coins)
+	if err != nil {
+		return "", fmt.Errorf("error while serializing coins: %s", err)
+	}
+	return string(bz), nil
+}
+
+// rawMessage returns the given raw contract message as text, using an empty object if it is not set
+func rawMessage(msg json.RawMessage) string {
+	if len(msg) == 0 {
+		return "{}"
+	}
+	return string(msg)
+}
+
+// ForChain implements database.Database
+func (db *Database) ForChain(chainID string) database.Database {
+	scoped := *db
+	scoped.ChainID = chainID
+	scoped.pending = nil
+	return &scoped
+}
+
+// HasBlock implements database.Database
+func (db *Database) HasBlock(height int64) (bool, error) {
+	var res bool
+	err := db.Sql.QueryRow(`SELECT EXISTS(SELECT 1 FROM block WHERE chain_id = ? AND height = ?);`, db.ChainID, height).Scan(&res)
+	return res, err
+}
+
+// SaveBlock implements database.Database.
+// The block is only written when it is committed, along with the records that have no unique key.
+func (db *Database) SaveBlock(block *types.Block) error {
+	db.pending = &blockRows{block: block}
+	return nil
+}
+
+// insertBlock writes the given block, unless it is stored already
+func (db *Database) insertBlock(ex execer, block *types.Block) error {
+	sqlStatement := `
+	INSERT INTO block (chain_id, height, hash, num_txs, total_gas, timestamp)
+	VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`
+
+	_, err := ex.Exec(sqlStatement,
+		db.ChainID, block.Height, block.Hash, block.TxNum, int64(block.TotalGas), block.Timestamp,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving block: %s", err)
+	}
+	return nil
+}
+
+// SaveWasmCode implements database.Database
+func (db *Database) SaveWasmCode(wasmCode types.WasmCode) error {
+	stmt := `
+	INSERT INTO wasm_code(chain_id, creator, code_hash, code_id, size, tx_hash, saved_at, height) 
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?) 
+	ON CONFLICT DO NOTHING`
+
+	_, err := db.Sql.Exec(stmt,
+		db.ChainID, wasmCode.Creator, wasmCode.CodeHash,
+		int64(wasmCode.CodeID), wasmCode.Size, wasmCode.TxHash,
+		wasmCode.SavedAt, wasmCode.Height,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving wasm code: %s", err)
+	}
+
+	return nil
+}
+
+// SaveWasmContract implements database.Database
+func (db *Database) SaveWasmContract(wasmContract types.WasmContract) error {
+	funds, err := marshalCoins(dbtypes.NewDbCoins(wasmContract.Funds))
+	if err != nil {
+		return err
+	}
+
+	stmt := `
+	INSERT INTO wasm_contract 
+	(chain_id, sender, creator, admin, code_id, label, raw_contract_message, funds, contract_address, tx_hash, instantiated_at, height) 
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) 
+	ON CONFLICT DO NOTHING`
+
+	_, err = db.Sql.Exec(stmt,
+		db.ChainID, wasmContract.Sender, wasmContract.Creator, wasmContract.Admin, int64(wasmContract.CodeID), wasmContract.Label,
+		rawMessage(wasmContract.RawContractMsg), funds, wasmContract.ContractAddress, wasmContract.TxHash,
+		wasmContract.InstantiatedAt, wasmContract.Height,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving wasm contract: %s", err)
+	}
+
+	return nil
+}
+
+// SaveWasmExecuteContract implements database.Database
+func (db *Database) SaveWasmExecuteContract(executeContract types.WasmExecuteContract) error {
+	if db.pending != nil {
+		db.pending.executions = append(db.pending.executions, executeContract)
+		return nil
+	}
+	return db.insertWasmExecuteContract(db.Sql, executeContract)
+}
+
+// insertWasmExecuteContract writes the given contract execution
+func (db *Database) insertWasmExecuteContract(ex execer, executeContract types.WasmExecuteContract) error {
+	funds, err := marshalCoins(dbtypes.NewDbCoins(executeContract.Funds))
+	if err != nil {
+		return err
+	}
+
+	stmt := `
+	INSERT INTO wasm_execute_contract 
+	(chain_id, sender, contract_address, raw_contract_message, funds, gas_used, fees_denom, fees_amount, tx_hash, executed_at, height) 
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) 
+	ON CONFLICT DO NOTHING`
+
+	denom := "utorii"
+
+	_, err = ex.Exec(stmt,
+		db.ChainID,
+		executeContract.Sender,
+		executeContract.ContractAddress,
+		rawMessage(executeContract.RawContractMsg),
+		funds,
+		executeContract.GasUsed,
+		denom,
+		executeContract.Fees.AmountOf(denom).String(),
+		executeContract.TxHash,
+		executeContract.ExecutedAt,
+		executeContract.Height,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving wasm contract execution: %s", err)
+	}
+
+	return nil
+}
+
+// SaveContractRewardCalculation implements database.Database
+func (db *Database) SaveContractRewardCalculation(contractRewardCalculation types.ContractRewardCalculation) error {
+	if db.pending != nil {
+		db.pending.rewards = append(db.pending.rewards, contractRewardCalculation)
+		return nil
+	}
+	return db.insertContractRewardCalculation(db.Sql, contractRewardCalculation)
+}
+
+// insertContractRewardCalculation writes the given contract reward
+func (db *Database) insertContractRewardCalculation(
+	ex execer, contractRewardCalculation types.ContractRewardCalculation,
+) error {
+	stmt := `
+	INSERT INTO contract_reward 
+	(chain_id, contract_address, reward_address, developer_address, gas_consumed, contract_rewards_denom, contract_rewards_amount, inflation_rewards_amount, gas_rebate_to_user, collect_premium, premium_percentage_charged, reward_date, height) 
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) 
+	ON CONFLICT DO NOTHING`
+
+	_, err := ex.Exec(
+		stmt,
+		db.ChainID,
+		contractRewardCalculation.ContractAddress,
+		contractRewardCalculation.RewardAddress,
+		contractRewardCalculation.DeveloperAddress,
+		strconv.FormatUint(contractRewardCalculation.GasConsumed, 10),
+		contractRewardCalculation.ContractRewards.Denom,
+		contractRewardCalculation.ContractRewards.Amount.String(),
+		contractRewardCalculation.InflationRewards.Amount.String(),
+		contractRewardCalculation.GasRebateToUser,
+		contractRewardCalculation.CollectPremium,
+		int64(contractRewardCalculation.PremiumPercentageCharged),
+		contractRewardCalculation.RewardDate,
+		contractRewardCalculation.Height,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving contract reward: %s", err)
+	}
+	return nil
+}
+
+// SaveContractRewardDistribution implements database.Database
+func (db *Database) SaveContractRewardDistribution(contractRewardDistribution types.ContractRewardDistribution) error {
+	if db.pending != nil {
+		db.pending.distributions = append(db.pending.distributions, contractRewardDistribution)
+		return nil
+	}
+	return db.updateContractRewardDistribution(db.Sql, contractRewardDistribution)
+}
+
+// updateContractRewardDistribution sets the given distribution on the rewards of its reward address
+func (db *Database) updateContractRewardDistribution(
+	ex execer, contractRewardDistribution types.ContractRewardDistribution,
+) error {
+	stmt := `UPDATE contract_reward SET 
+	distributed_rewards_amount = ?, leftover_rewards_amount = ? 
+	WHERE chain_id = ? AND reward_address = ? AND height = ?`
+
+	_, err := ex.Exec(
+		stmt,
+		contractRewardDistribution.DistributedRewards.Amount.String(),
+		contractRewardDistribution.LeftoverRewards.Amount.String(),
+		db.ChainID,
+		contractRewardDistribution.RewardAddress,
+		contractRewardDistribution.Height,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving contract distribution rewards: %s", err)
+	}
+	return nil
+}
+
+// SaveGasTrackerContractMetadata implements database.Database
+func (db *Database) SaveGasTrackerContractMetadata(gastrackerContractMetadata types.GasTrackerContractMetadata) error {
+	if db.pending != nil {
+		db.pending.metadata = append(db.pending.metadata, gastrackerContractMetadata)
+		return nil
+	}
+	return db.insertGasTrackerContractMetadata(db.Sql, gastrackerContractMetadata)
+}
+
+// insertGasTrackerContractMetadata writes the given contract metadata
+func (db *Database) insertGasTrackerContractMetadata(
+	ex execer, gastrackerContractMetadata types.GasTrackerContractMetadata,
+) error {
+	stmt := `INSERT INTO contract_metadata 
+	(chain_id, contract_address, reward_address, developer_address, collect_premium, gas_rebate_to_user, premium_percentage_charged, tx_hash, saved_at, height) 
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) 
+	ON CONFLICT DO NOTHING`
+
+	_, err := ex.Exec(
+		stmt,
+		db.ChainID,
+		gastrackerContractMetadata.ContractAddress,
+		gastrackerContractMetadata.Metadata.RewardAddress,
+		gastrackerContractMetadata.Metadata.DeveloperAddress,
+		gastrackerContractMetadata.Metadata.CollectPremium,
+		gastrackerContractMetadata.Metadata.GasRebateToUser,
+		int64(gastrackerContractMetadata.Metadata.PremiumPercentageCharged),
+		gastrackerContractMetadata.TxHash,
+		gastrackerContractMetadata.SavedAt,
+		gastrackerContractMetadata.Height,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving contract metadata: %s", err)
+	}
+	return nil
+}
+
+// SaveNotificationDelivery implements database.Database
+func (db *Database) SaveNotificationDelivery(delivery types.NotificationDelivery) error {
+	stmt := `
+INSERT INTO notification_delivery 
+    (chain_id, delivery_id, rule, webhook, payload, height, attempt, status_code, error, success, attempted_at) 
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := db.Sql.Exec(stmt,
+		db.ChainID,
+		delivery.DeliveryID,
+		delivery.Rule,
+		delivery.Webhook,
+		string(delivery.Payload),
+		delivery.Height,
+		delivery.Attempt,
+		delivery.StatusCode,
+		delivery.Error,
+		delivery.Success,
+		delivery.AttemptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving notification delivery: %s", err)
+	}
+
+	return nil
+}
+
+// SaveContractStateSnapshot implements database.Database
+func (db *Database) SaveContractStateSnapshot(snapshot types.ContractStateSnapshot) error {
+	stmt := `
+INSERT INTO contract_state_snapshot (chain_id, contract_address, snapshot_type, query, result, height, snapshot_at) 
+VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := db.Sql.Exec(stmt,
+		db.ChainID,
+		snapshot.ContractAddress,
+		snapshot.Type,
+		sql.NullString{String: string(snapshot.Query), Valid: len(snapshot.Query) > 0},
+		string(snapshot.Result),
+		snapshot.Height,
+		snapshot.SnapshotAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error while saving contract state snapshot: %s", err)
+	}
+
+	return nil
+}
+
+// GetChainInfo implements database.Database.
+// When the database is not scoped to any chain, the identity of the first stored chain is returned.
+func (db *Database) GetChainInfo() (*types.ChainInfo, error) {
+	stmt := `SELECT chain_id, genesis_hash FROM chain_info WHERE ?1 = '' OR chain_id = ?1 ORDER BY created_at LIMIT 1`
+
+	var info types.ChainInfo
+	err := db.Sql.QueryRow(stmt, db.ChainID).Scan(&info.ChainID, &info.GenesisHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting chain info: %s", err)
+	}
+
+	return &info, nil
+}
+
+// SaveChainInfo implements database.Database
+func (db *Database) SaveChainInfo(info types.ChainInfo) error {
+	_, err := db.Sql.Exec(`INSERT INTO chain_info (chain_id, genesis_hash) VALUES (?, ?)`, info.ChainID, info.GenesisHash)
+	if err != nil {
+		return fmt.Errorf("error while saving chain info: %s", err)
+	}
+
+	return nil
+}
+
+// CommitBlock implements database.Database.
+// The block is written along with its pending records inside a single transaction. The records of the tables
+// having no unique key are skipped when the table already contains rows at their height, so that writing a block
+// twice does not duplicate them.
+func (db *Database) CommitBlock(_ *types.Block) error {
+	rows := db.pending
+	if rows == nil {
+		return nil
+	}
+	db.pending = nil
+
+	tx, err := db.Sql.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting block transaction: %s", err)
+	}
+	defer tx.Rollback() // nolint
+
+	stored := newStoredHeights(tx, db.ChainID)
+
+	for _, executeContract := range rows.executions {
+		skip, err := stored.has("wasm_execute_contract", executeContract.Height)
+		if err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+
+		err = db.insertWasmExecuteContract(tx, executeContract)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, reward := range rows.rewards {
+		skip, err := stored.has("contract_reward", reward.Height)
+		if err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+
+		err = db.insertContractRewardCalculation(tx, reward)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Distributions are applied after all the rewards they refer to have been written
+	for _, distribution := range rows.distributions {
+		err = db.updateContractRewardDistribution(tx, distribution)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, metadata := range rows.metadata {
+		skip, err := stored.has("contract_metadata", metadata.Height)
+		if err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+
+		err = db.insertGasTrackerContractMetadata(tx, metadata)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = db.insertBlock(tx, rows.block)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error while committing block transaction: %s", err)
+	}
+
+	return nil
+}
+
+// storedHeights tells whether the tables having no unique key contained rows at a given height
+// before the current transaction wrote any row at that height
+type storedHeights struct {
+	tx      *sql.Tx
+	chainID string
+	heights map[string]bool
+}
+
+// newStoredHeights returns a new storedHeights instance checking the rows of the given chain
+func newStoredHeights(tx *sql.Tx, chainID string) *storedHeights {
+	return &storedHeights{tx: tx, chainID: chainID, heights: map[string]bool{}}
+}
+
+// has tells whether the given table contained rows at the given height. The first call for each table and height
+// must happen before writing any row at that height.
+func (s *storedHeights) has(table string, height int64) (bool, error) {
+	key := fmt.Sprintf("%s/%d", table, height)
+	if found, ok := s.heights[key]; ok {
+		return found, nil
+	}
+
+	var found bool
+	err := s.tx.QueryRow(fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE chain_id = ? AND height = ?)`, table),
+		s.chainID, height).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("error while checking stored %s rows: %s", table, err)
+	}
+
+	s.heights[key] = found
+	return found, nil
+}
+
+// Close implements database.Database
+func (db *Database) Close() {
+	err := db.Sql.Close()
+	if err != nil {
+		db.Logger.Error("error while closing connection", "err", err)
+	}
+}
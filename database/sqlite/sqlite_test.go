@@ -0,0 +1,25 @@
+package sqlite_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nuclearblock/archgregator/database"
+	databaseconfig "github.com/nuclearblock/archgregator/database/config"
+	"github.com/nuclearblock/archgregator/database/databasetest"
+	"github.com/nuclearblock/archgregator/database/sqlite"
+	"github.com/nuclearblock/archgregator/logging"
+)
+
+func TestSuite(t *testing.T) {
+	databasetest.RunSuite(t, func(t *testing.T) database.Database {
+		cfg := databaseconfig.Config{Type: databaseconfig.TypeSQLite, Path: filepath.Join(t.TempDir(), "archgregator.db")}
+		db, err := sqlite.Builder(database.NewContext(cfg, nil, logging.DefaultLogger()))
+		require.NoError(t, err)
+
+		t.Cleanup(db.Close)
+		return db
+	})
+}
@@ -36,8 +36,8 @@ func RemoveEmpty(s []string) []string {
 
 // DbCoin represents the information stored inside the database about a single coin
 type DbCoin struct {
-	Denom  string
-	Amount string
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
 }
 
 // NewDbCoin builds a DbCoin starting from an SDK Coin
@@ -145,8 +145,8 @@ func (coins DbCoins) ToCoins() sdk.Coins {
 
 // DbDecCoin represents the information stored inside the database about a single coin
 type DbDecCoin struct {
-	Denom  string
-	Amount string
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
 }
 
 // NewDbDecCoin builds a DbDecCoin starting from an SDK Coin
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Cursor identifies a row inside the sorted results of a query, so that the following rows can be fetched
+// without counting the previous ones. Each query only uses the fields matching the columns it is sorted by:
+//   - blocks are sorted by Height and ChainID;
+//   - wasm codes are sorted by ID, containing the code id, and ChainID;
+//   - wasm contracts are sorted by Height, ChainID and Key, containing the contract address;
+//   - executions, metadata and rewards are sorted by Height and ID, containing the row id.
+type Cursor struct {
+	Height  int64  `json:"h,omitempty"`
+	ChainID string `json:"c,omitempty"`
+	Key     string `json:"k,omitempty"`
+	ID      int64  `json:"i,omitempty"`
+}
+
+// Encode returns the opaque string representation of the cursor
+func (c Cursor) Encode() string {
+	bz, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(bz)
+}
+
+// DecodeCursor parses the given opaque cursor, returned by Cursor.Encode
+func DecodeCursor(value string) (*Cursor, error) {
+	bz, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", value)
+	}
+
+	var cursor Cursor
+	err = json.Unmarshal(bz, &cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", value)
+	}
+
+	return &cursor, nil
+}
+
+// Cursor returns the cursor identifying the row
+func (r BlockRow) Cursor() Cursor {
+	return Cursor{Height: r.Height, ChainID: r.ChainID}
+}
+
+// Cursor returns the cursor identifying the row
+func (r WasmCodeRow) Cursor() Cursor {
+	return Cursor{ChainID: r.ChainID, ID: r.CodeID}
+}
+
+// Cursor returns the cursor identifying the row
+func (r WasmContractRow) Cursor() Cursor {
+	return Cursor{Height: r.Height, ChainID: r.ChainID, Key: r.ContractAddress}
+}
+
+// Cursor returns the cursor identifying the row
+func (r WasmExecuteContractRow) Cursor() Cursor {
+	return Cursor{Height: r.Height, ID: r.ID}
+}
+
+// Cursor returns the cursor identifying the row
+func (r ContractMetadataRow) Cursor() Cursor {
+	return Cursor{Height: r.Height, ID: r.ID}
+}
+
+// Cursor returns the cursor identifying the row
+func (r ContractRewardRow) Cursor() Cursor {
+	return Cursor{Height: r.Height, ID: r.ID}
+}
@@ -0,0 +1,75 @@
+package types
+
+// Pagination contains the data used to paginate the results of a query.
+// When After is set, the results start right after the row it identifies and Offset is ignored.
+type Pagination struct {
+	Limit  int
+	Offset int
+	After  *Cursor
+}
+
+// NewPagination allows to build a new Pagination instance
+func NewPagination(limit, offset int) Pagination {
+	return Pagination{
+		Limit:  limit,
+		Offset: offset,
+	}
+}
+
+// NewCursorPagination allows to build a new Pagination instance returning the rows following the given cursor
+func NewCursorPagination(limit int, after *Cursor) Pagination {
+	return Pagination{
+		Limit: limit,
+		After: after,
+	}
+}
+
+// HeightRange represents an inclusive range of heights. A zero value on either side means "unbounded"
+type HeightRange struct {
+	FromHeight int64
+	ToHeight   int64
+}
+
+// BlocksFilter contains the filters that can be applied when querying the blocks
+type BlocksFilter struct {
+	HeightRange
+}
+
+// WasmCodesFilter contains the filters that can be applied when querying the wasm codes
+type WasmCodesFilter struct {
+	HeightRange
+	Creator string
+}
+
+// WasmContractsFilter contains the filters that can be applied when querying the wasm contracts
+type WasmContractsFilter struct {
+	HeightRange
+	CodeID  int64
+	Creator string
+	Admin   string
+	Label   string
+}
+
+// WasmExecuteContractsFilter contains the filters that can be applied when querying the wasm contract executions
+type WasmExecuteContractsFilter struct {
+	HeightRange
+	ContractAddress string
+	Sender          string
+	TxHash          string
+}
+
+// ContractMetadataFilter contains the filters that can be applied when querying the gastracker contract metadata
+type ContractMetadataFilter struct {
+	HeightRange
+	ContractAddress  string
+	RewardAddress    string
+	DeveloperAddress string
+}
+
+// ContractRewardsFilter contains the filters that can be applied when querying the gastracker contract rewards
+type ContractRewardsFilter struct {
+	HeightRange
+	ContractAddress  string
+	RewardAddress    string
+	DeveloperAddress string
+}
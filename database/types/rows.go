@@ -0,0 +1,98 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BlockRow represents a single row of the block table
+type BlockRow struct {
+	ChainID   string    `json:"chain_id"`
+	Height    int64     `json:"height"`
+	Hash      string    `json:"hash"`
+	TxNum     int       `json:"num_txs"`
+	TotalGas  int64     `json:"total_gas"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// WasmCodeRow represents a single row of the wasm_code table
+type WasmCodeRow struct {
+	ChainID  string    `json:"chain_id"`
+	Creator  string    `json:"creator"`
+	CodeHash string    `json:"code_hash"`
+	CodeID   int64     `json:"code_id"`
+	Size     int       `json:"size"`
+	TxHash   string    `json:"tx_hash"`
+	SavedAt  time.Time `json:"saved_at"`
+	Height   int64     `json:"height"`
+}
+
+// WasmContractRow represents a single row of the wasm_contract table
+type WasmContractRow struct {
+	ChainID            string          `json:"chain_id"`
+	Sender             string          `json:"sender"`
+	Creator            string          `json:"creator"`
+	Admin              string          `json:"admin"`
+	CodeID             int64           `json:"code_id"`
+	Label              string          `json:"label"`
+	RawContractMessage json.RawMessage `json:"raw_contract_message"`
+	Funds              DbCoins         `json:"funds"`
+	ContractAddress    string          `json:"contract_address"`
+	TxHash             string          `json:"tx_hash"`
+	InstantiatedAt     time.Time       `json:"instantiated_at"`
+	Height             int64           `json:"height"`
+}
+
+// WasmExecuteContractRow represents a single row of the wasm_execute_contract table.
+// Since the table has no unique key, ID is only used to sort the rows and is not exposed.
+type WasmExecuteContractRow struct {
+	ID                 int64           `json:"-"`
+	ChainID            string          `json:"chain_id"`
+	Sender             string          `json:"sender"`
+	ContractAddress    string          `json:"contract_address"`
+	RawContractMessage json.RawMessage `json:"raw_contract_message"`
+	Funds              DbCoins         `json:"funds"`
+	GasUsed            int64           `json:"gas_used"`
+	FeesDenom          string          `json:"fees_denom"`
+	FeesAmount         float64         `json:"fees_amount"`
+	TxHash             string          `json:"tx_hash"`
+	ExecutedAt         time.Time       `json:"executed_at"`
+	Height             int64           `json:"height"`
+}
+
+// ContractMetadataRow represents a single row of the contract_metadata table.
+// Since the table has no unique key, ID is only used to sort the rows and is not exposed.
+type ContractMetadataRow struct {
+	ID                       int64     `json:"-"`
+	ChainID                  string    `json:"chain_id"`
+	ContractAddress          string    `json:"contract_address"`
+	RewardAddress            string    `json:"reward_address"`
+	DeveloperAddress         string    `json:"developer_address"`
+	CollectPremium           bool      `json:"collect_premium"`
+	GasRebateToUser          bool      `json:"gas_rebate_to_user"`
+	PremiumPercentageCharged int64     `json:"premium_percentage_charged"`
+	TxHash                   string    `json:"tx_hash"`
+	SavedAt                  time.Time `json:"saved_at"`
+	Height                   int64     `json:"height"`
+}
+
+// ContractRewardRow represents a single row of the contract_reward table.
+// Since the table has no unique key, ID is only used to sort the rows and is not exposed.
+type ContractRewardRow struct {
+	ID                       int64     `json:"-"`
+	ChainID                  string    `json:"chain_id"`
+	ContractAddress          string    `json:"contract_address"`
+	RewardAddress            string    `json:"reward_address"`
+	DeveloperAddress         string    `json:"developer_address"`
+	GasConsumed              string    `json:"gas_consumed"`
+	ContractRewardsDenom     string    `json:"contract_rewards_denom"`
+	ContractRewardsAmount    float64   `json:"contract_rewards_amount"`
+	InflationRewardsAmount   float64   `json:"inflation_rewards_amount"`
+	DistributedRewardsAmount float64   `json:"distributed_rewards_amount"`
+	LeftoverRewardsAmount    float64   `json:"leftover_rewards_amount"`
+	GasRebateToUser          bool      `json:"gas_rebate_to_user"`
+	CollectPremium           bool      `json:"collect_premium"`
+	PremiumPercentageCharged int64     `json:"premium_percentage_charged"`
+	RewardDate               time.Time `json:"reward_date"`
+	Height                   int64     `json:"height"`
+}
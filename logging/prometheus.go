@@ -4,12 +4,13 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-// StartHeight represents the Telemetry counter used to set the start height of the parsing
-var StartHeight = prometheus.NewCounter(
-	prometheus.CounterOpts{
+// StartHeight represents the Telemetry gauge used to set the start height of the parsing of each chain
+var StartHeight = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
 		Name: "archgregator_initial_height",
 		Help: "Initial parsing height.",
 	},
+	[]string{"chain_id"},
 )
 
 // WorkerCount represents the Telemetry counter used to track the worker count
@@ -20,13 +21,13 @@ var WorkerCount = prometheus.NewCounter(
 	},
 )
 
-// WorkerHeight represents the Telemetry counter used to track the last indexed height for each worker
+// WorkerHeight represents the Telemetry counter used to track the last indexed height for each worker of each chain
 var WorkerHeight = prometheus.NewGaugeVec(
 	prometheus.GaugeOpts{
 		Name: "archgregator_last_indexed_height",
 		Help: "Height of the last indexed block.",
 	},
-	[]string{"worker_index"},
+	[]string{"chain_id", "worker_index"},
 )
 
 // ErrorCount represents the Telemetry counter used to track the number of errors emitted
@@ -37,6 +38,40 @@ var ErrorCount = prometheus.NewCounter(
 	},
 )
 
+// StreamSubscriptions represents the Telemetry gauge used to track the number of active stream subscriptions
+var StreamSubscriptions = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "archgregator_stream_subscriptions",
+		Help: "Number of active event stream subscriptions.",
+	},
+)
+
+// StreamDroppedEvents represents the Telemetry counter used to track the events dropped because of slow subscribers
+var StreamDroppedEvents = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "archgregator_stream_dropped_events",
+		Help: "Total number of stream events dropped because of slow subscribers.",
+	},
+)
+
+// NodeCacheRequests represents the Telemetry counter used to track the requests served by the node cache
+var NodeCacheRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "archgregator_node_cache_requests",
+		Help: "Total number of node responses requested to the cache, by kind and result (hit, disk_hit or miss).",
+	},
+	[]string{"kind", "result"},
+)
+
+// NodeThrottledRequests represents the Telemetry counter used to track the node requests that have been throttled
+var NodeThrottledRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "archgregator_node_throttled_requests",
+		Help: "Total number of node requests throttled, by endpoint and reason (rate_limit, max_in_flight, backoff or remote).",
+	},
+	[]string{"endpoint", "reason"},
+)
+
 func init() {
 	err := prometheus.Register(StartHeight)
 	if err != nil {
@@ -57,4 +92,24 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = prometheus.Register(StreamSubscriptions)
+	if err != nil {
+		panic(err)
+	}
+
+	err = prometheus.Register(StreamDroppedEvents)
+	if err != nil {
+		panic(err)
+	}
+
+	err = prometheus.Register(NodeCacheRequests)
+	if err != nil {
+		panic(err)
+	}
+
+	err = prometheus.Register(NodeThrottledRequests)
+	if err != nil {
+		panic(err)
+	}
 }
@@ -6,16 +6,26 @@ import (
 	"github.com/archway-network/archway/app/params"
 	"github.com/nuclearblock/archgregator/node"
 	nodeconfig "github.com/nuclearblock/archgregator/node/config"
+	"github.com/nuclearblock/archgregator/node/fixture"
 	"github.com/nuclearblock/archgregator/node/local"
+	"github.com/nuclearblock/archgregator/node/recorder"
 	"github.com/nuclearblock/archgregator/node/remote"
 )
 
 func BuildNode(cfg nodeconfig.Config, encodingConfig *params.EncodingConfig) (node.Node, error) {
 	switch cfg.Type {
 	case nodeconfig.TypeRemote:
-		return remote.NewNode(cfg.Details.(*remote.Details), encodingConfig.Marshaler)
+		return remote.NewNode(cfg.Details.(*remote.Details), encodingConfig.TxConfig, encodingConfig.Marshaler)
 	case nodeconfig.TypeLocal:
 		return local.NewNode(cfg.Details.(*local.Details), encodingConfig.TxConfig, encodingConfig.Marshaler)
+	case nodeconfig.TypeBlockStore:
+		return local.NewBlockStoreNode(cfg.Details.(*local.BlockStoreDetails), encodingConfig.TxConfig, encodingConfig.Marshaler)
+	case nodeconfig.TypeFixture:
+		return fixture.NewNode(cfg.Details.(*fixture.Details), encodingConfig.Marshaler)
+	case nodeconfig.TypeRecorder:
+		return recorder.NewNode(cfg.Details.(*recorder.Details), encodingConfig.TxConfig, encodingConfig.Marshaler)
+	case nodeconfig.TypeReplay:
+		return recorder.NewReplayNode(cfg.Details.(*recorder.ReplayDetails), encodingConfig.Marshaler)
 	case nodeconfig.TypeNone:
 		return nil, nil
 
@@ -3,14 +3,20 @@ package config
 import (
 	"gopkg.in/yaml.v3"
 
+	"github.com/nuclearblock/archgregator/node/fixture"
 	"github.com/nuclearblock/archgregator/node/local"
+	"github.com/nuclearblock/archgregator/node/recorder"
 	"github.com/nuclearblock/archgregator/node/remote"
 )
 
 const (
-	TypeRemote = "remote"
-	TypeLocal  = "local"
-	TypeNone   = "none"
+	TypeRemote     = "remote"
+	TypeLocal      = "local"
+	TypeBlockStore = "blockstore"
+	TypeFixture    = "fixture"
+	TypeRecorder   = "recorder"
+	TypeReplay     = "replay"
+	TypeNone       = "none"
 )
 
 type Config struct {
@@ -46,6 +52,14 @@ func (s *Config) UnmarshalYAML(n *yaml.Node) error {
 		s.Details = new(remote.Details)
 	case TypeLocal:
 		s.Details = new(local.Details)
+	case TypeBlockStore:
+		s.Details = new(local.BlockStoreDetails)
+	case TypeFixture:
+		s.Details = new(fixture.Details)
+	case TypeRecorder:
+		s.Details = new(recorder.Details)
+	case TypeReplay:
+		s.Details = new(recorder.ReplayDetails)
 	default:
 		panic("unknown node type")
 	}
@@ -0,0 +1,26 @@
+package fixture
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Details represents the nodeconfig.Details implementation for a fixture node
+type Details struct {
+	Path string `yaml:"path"`
+}
+
+func NewDetails(path string) *Details {
+	return &Details{
+		Path: path,
+	}
+}
+
+// Validate implements nodeconfig.Details
+func (d *Details) Validate() error {
+	if strings.TrimSpace(d.Path) == "" {
+		return fmt.Errorf("fixtures path cannot be empty")
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	constypes "github.com/tendermint/tendermint/consensus/types"
+	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+
+	"github.com/nuclearblock/archgregator/node"
+	"github.com/nuclearblock/archgregator/node/store"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+var (
+	_ node.Node = &Node{}
+)
+
+// Node represents a node.Node implementation that serves the data previously stored as fixture files,
+// allowing to run the whole parsing pipeline without contacting any chain node.
+// Code info is returned regardless of the requested height, since it never changes once the code has been stored.
+type Node struct {
+	store *store.Store
+}
+
+// NewNode returns a new Node instance serving the fixtures contained inside the configured directory
+func NewNode(cfg *Details, codec codec.Codec) (*Node, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStoreNode(store.NewStore(cfg.Path, codec)), nil
+}
+
+// NewStoreNode returns a new Node instance serving the fixtures contained inside the given store
+func NewStoreNode(s *store.Store) *Node {
+	return &Node{
+		store: s,
+	}
+}
+
+// Genesis implements node.Node
+func (n *Node) Genesis() (*tmctypes.ResultGenesis, error) {
+	return n.store.ReadGenesis()
+}
+
+// ConsensusState implements node.Node
+func (n *Node) ConsensusState() (*constypes.RoundStateSimple, error) {
+	return nil, fmt.Errorf("consensus state is not supported by the fixture node")
+}
+
+// LatestHeight implements node.Node
+func (n *Node) LatestHeight() (int64, error) {
+	heights, err := n.store.Heights()
+	if err != nil {
+		return -1, err
+	}
+
+	if len(heights) == 0 {
+		return -1, fmt.Errorf("no block fixture found")
+	}
+	return heights[len(heights)-1], nil
+}
+
+// Block implements node.Node
+func (n *Node) Block(height int64) (*tmctypes.ResultBlock, error) {
+	return n.store.ReadBlock(height)
+}
+
+// BlockResults implements node.Node
+func (n *Node) BlockResults(height int64) (*tmctypes.ResultBlockResults, error) {
+	return n.store.ReadBlockResults(height)
+}
+
+// Tx implements node.Node
+func (n *Node) Tx(hash string) (*types.Tx, error) {
+	heights, err := n.store.Heights()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, height := range heights {
+		txs, err := n.store.ReadTxs(height)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range txs {
+			if tx.TxHash == hash {
+				return tx, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("tx %s not found", hash)
+}
+
+// Txs implements node.Node
+func (n *Node) Txs(block *tmctypes.ResultBlock, _ *tmctypes.ResultBlockResults) ([]*types.Tx, error) {
+	txs, err := n.store.ReadTxs(block.Block.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(txs) != len(block.Block.Txs) {
+		return nil, fmt.Errorf("block %d contains %d txs but %d have been found",
+			block.Block.Height, len(block.Block.Txs), len(txs))
+	}
+	return txs, nil
+}
+
+// TxSearch implements node.Node
+func (n *Node) TxSearch(_ string, _ *int, _ *int, _ string) (*tmctypes.ResultTxSearch, error) {
+	return nil, fmt.Errorf("tx search is not supported by the fixture node")
+}
+
+// SubscribeEvents implements node.Node
+func (n *Node) SubscribeEvents(_, _ string) (<-chan tmctypes.ResultEvent, context.CancelFunc, error) {
+	return nil, nil, fmt.Errorf("events subscription is not supported by the fixture node")
+}
+
+// SubscribeNewBlocks implements node.Node
+func (n *Node) SubscribeNewBlocks(_ string) (<-chan tmctypes.ResultEvent, context.CancelFunc, error) {
+	return nil, nil, fmt.Errorf("events subscription is not supported by the fixture node")
+}
+
+// GetCodeInfo implements node.Node
+func (n *Node) GetCodeInfo(_ int64, codeID uint64) (*wasmtypes.QueryCodeResponse, error) {
+	return n.store.ReadCodeInfo(codeID)
+}
+
+// GetContractInfo implements node.Node
+func (n *Node) GetContractInfo(height int64, contractAddr string) (*wasmtypes.QueryContractInfoResponse, error) {
+	return n.store.ReadContractInfo(height, contractAddr)
+}
+
+// SmartContractState implements node.Node.
+// Since only the answered queries are recorded, a query having no stored response is reported as not answered.
+func (n *Node) SmartContractState(height int64, contractAddr string, query []byte) (*wasmtypes.QuerySmartContractStateResponse, error) {
+	if !n.store.HasSmartContractState(height, contractAddr, query) {
+		return nil, &node.ContractQueryError{ContractAddr: contractAddr, Reason: "no recorded response"}
+	}
+	return n.store.ReadSmartContractState(height, contractAddr, query)
+}
+
+// AllContractState implements node.Node
+func (n *Node) AllContractState(height int64, contractAddr string) (*wasmtypes.QueryAllContractStateResponse, error) {
+	return n.store.ReadAllContractState(height, contractAddr)
+}
+
+// Stop implements node.Node
+func (n *Node) Stop() {}
@@ -0,0 +1,169 @@
+package local
+
+import (
+	"context"
+	"fmt"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	constypes "github.com/tendermint/tendermint/consensus/types"
+	tmnode "github.com/tendermint/tendermint/node"
+	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
+	sm "github.com/tendermint/tendermint/state"
+	blockidxkv "github.com/tendermint/tendermint/state/indexer/block/kv"
+	blockidxnull "github.com/tendermint/tendermint/state/indexer/block/null"
+	"github.com/tendermint/tendermint/state/txindex/kv"
+	"github.com/tendermint/tendermint/state/txindex/null"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/nuclearblock/archgregator/node"
+)
+
+var (
+	_ node.Node = &BlockStoreNode{}
+)
+
+// BlockStoreNode represents a node.Node implementation that reads the blocks, their results and their
+// transactions directly from the block store, state and tx index databases of a node data directory,
+// without the need of running any node process.
+// All the databases are opened read-only, so the data directory can be a copy of a running node one,
+// but it cannot be used by a running node at the same time.
+// The blocks and their results are read concurrently ahead of the requested heights.
+type BlockStoreNode struct {
+	*Node
+
+	dbs        []dbm.DB
+	prefetcher *prefetcher
+}
+
+// NewBlockStoreNode returns a new BlockStoreNode instance reading the data directory contained inside the configured home
+func NewBlockStoreNode(config *BlockStoreDetails, txConfig client.TxConfig, codec codec.Codec) (*BlockStoreNode, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	tmCfg, err := parseConfig(config.Home)
+	if err != nil {
+		return nil, err
+	}
+	tmCfg.SetRoot(config.Home)
+
+	if dbm.BackendType(tmCfg.DBBackend) != dbm.GoLevelDBBackend {
+		return nil, fmt.Errorf("unsupported db backend %s, only %s can be read", tmCfg.DBBackend, dbm.GoLevelDBBackend)
+	}
+
+	// Keep track of all the opened databases so that they can be closed when stopping
+	var dbs []dbm.DB
+	dbProvider := func(ctx *tmnode.DBContext) (dbm.DB, error) {
+		db, err := readOnlyDBProvider(ctx)
+		if err == nil {
+			dbs = append(dbs, db)
+		}
+		return db, err
+	}
+
+	blockStore, stateDB, err := initDBs(tmCfg, dbProvider)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening block store: %s", err)
+	}
+
+	localNode := &Node{
+		ctx:      context.Background(),
+		codec:    codec,
+		txConfig: txConfig,
+		home:     config.Home,
+
+		tmCfg: tmCfg,
+
+		stateStore: sm.NewStore(stateDB),
+		blockStore: blockStore,
+	}
+
+	switch tmCfg.TxIndex.Indexer {
+	case "kv":
+		store, err := dbProvider(&tmnode.DBContext{ID: "tx_index", Config: tmCfg})
+		if err != nil {
+			return nil, fmt.Errorf("error while opening tx index: %s", err)
+		}
+
+		localNode.txIndexer = kv.NewTxIndex(store)
+		localNode.blockIndexer = blockidxkv.New(dbm.NewPrefixDB(store, []byte("block_events")))
+	default:
+		localNode.txIndexer = &null.TxIndex{}
+		localNode.blockIndexer = &blockidxnull.BlockerIndexer{}
+	}
+
+	prefetch := config.Prefetch
+	if prefetch == 0 {
+		prefetch = DefaultPrefetch
+	}
+
+	return &BlockStoreNode{
+		Node:       localNode,
+		dbs:        dbs,
+		prefetcher: newPrefetcher(prefetch, blockStore.Height, localNode.Block, localNode.BlockResults),
+	}, nil
+}
+
+// readOnlyDBProvider implements tmnode.DBProvider opening the requested LevelDB database read-only
+func readOnlyDBProvider(ctx *tmnode.DBContext) (dbm.DB, error) {
+	return dbm.NewGoLevelDBWithOpts(ctx.ID, ctx.Config.DBDir(), &opt.Options{ReadOnly: true})
+}
+
+// Genesis implements node.Node
+func (cp *BlockStoreNode) Genesis() (*tmctypes.ResultGenesis, error) {
+	genesisDoc, err := tmnode.DefaultGenesisDocProviderFunc(cp.tmCfg)()
+	if err != nil {
+		return nil, fmt.Errorf("error while reading genesis: %s", err)
+	}
+
+	return &tmctypes.ResultGenesis{Genesis: genesisDoc}, nil
+}
+
+// ConsensusState implements node.Node
+func (cp *BlockStoreNode) ConsensusState() (*constypes.RoundStateSimple, error) {
+	return nil, fmt.Errorf("consensus state is not supported by the block store node")
+}
+
+// Block implements node.Node
+func (cp *BlockStoreNode) Block(height int64) (*tmctypes.ResultBlock, error) {
+	return cp.prefetcher.Block(height)
+}
+
+// BlockResults implements node.Node
+func (cp *BlockStoreNode) BlockResults(height int64) (*tmctypes.ResultBlockResults, error) {
+	return cp.prefetcher.BlockResults(height)
+}
+
+// SubscribeEvents implements node.Node
+func (cp *BlockStoreNode) SubscribeEvents(_, _ string) (<-chan tmctypes.ResultEvent, context.CancelFunc, error) {
+	return nil, nil, fmt.Errorf("events subscription is not supported by the block store node")
+}
+
+// SubscribeNewBlocks implements node.Node
+func (cp *BlockStoreNode) SubscribeNewBlocks(_ string) (<-chan tmctypes.ResultEvent, context.CancelFunc, error) {
+	return nil, nil, fmt.Errorf("events subscription is not supported by the block store node")
+}
+
+// GetCodeInfo implements node.Node
+func (cp *BlockStoreNode) GetCodeInfo(_ int64, _ uint64) (*wasmtypes.QueryCodeResponse, error) {
+	return nil, fmt.Errorf("code info is not supported by the block store node")
+}
+
+// GetContractInfo implements node.Node
+func (cp *BlockStoreNode) GetContractInfo(_ int64, _ string) (*wasmtypes.QueryContractInfoResponse, error) {
+	return nil, fmt.Errorf("contract info is not supported by the block store node")
+}
+
+// Stop implements node.Node
+func (cp *BlockStoreNode) Stop() {
+	for _, db := range cp.dbs {
+		err := db.Close()
+		if err != nil {
+			panic(fmt.Errorf("error while closing database: %s", err))
+		}
+	}
+}
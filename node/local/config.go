@@ -34,3 +34,33 @@ func (d *Details) Validate() error {
 
 	return nil
 }
+
+// DefaultPrefetch is the number of heights read ahead by a block store node when not configured
+const DefaultPrefetch = 32
+
+// BlockStoreDetails represents the nodeconfig.Details implementation for a node reading
+// the block store, state and tx index databases of a node data directory.
+// Prefetch is the number of heights following each requested one that are read concurrently ahead of time.
+type BlockStoreDetails struct {
+	Home     string `yaml:"home"`
+	Prefetch int    `yaml:"prefetch,omitempty"`
+}
+
+func NewBlockStoreDetails(home string) *BlockStoreDetails {
+	return &BlockStoreDetails{
+		Home: home,
+	}
+}
+
+// Validate implements nodeconfig.Details
+func (d *BlockStoreDetails) Validate() error {
+	if strings.TrimSpace(d.Home) == "" {
+		return fmt.Errorf("home path cannot be empty")
+	}
+
+	if d.Prefetch < 0 {
+		return fmt.Errorf("invalid prefetch: %d", d.Prefetch)
+	}
+
+	return nil
+}
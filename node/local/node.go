@@ -7,10 +7,9 @@ import (
 	"os"
 	"sort"
 
-	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
-	"github.com/cosmos/cosmos-sdk/types/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/spf13/viper"
 	cfg "github.com/tendermint/tendermint/config"
 	cs "github.com/tendermint/tendermint/consensus"
@@ -61,6 +60,7 @@ type Node struct {
 	ctx      context.Context
 	codec    codec.Codec
 	txConfig client.TxConfig
+	home     string
 
 	// config
 	tmCfg      *cfg.Config
@@ -74,7 +74,9 @@ type Node struct {
 	txIndexer      txindex.TxIndexer
 	blockIndexer   indexer.BlockIndexer
 
-	wasmQuerier wasmtypes.QueryServer
+	// source reads the application store, and wasmKey is the key of the wasm module store inside it
+	source  *Source
+	wasmKey *sdk.KVStoreKey
 }
 
 // NewNode returns a new Node instance
@@ -157,10 +159,11 @@ func NewNode(config *Details, txConfig client.TxConfig, codec codec.Codec) (*Nod
 		cs.StateMetrics(csMetrics),
 	)
 
-	return &Node{
+	node := &Node{
 		ctx:      context.Background(),
 		codec:    codec,
 		txConfig: txConfig,
+		home:     config.Home,
 
 		tmCfg:      tmCfg,
 		genesisDoc: genDoc,
@@ -171,8 +174,14 @@ func NewNode(config *Details, txConfig client.TxConfig, codec codec.Codec) (*Nod
 		blockStore:     blockStore,
 		txIndexer:      txIndexer,
 		blockIndexer:   blockIndexer,
-		wasmQuerier:    nil,
-	}, nil
+	}
+
+	err = initWasmSource(config.Home, node)
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
 }
 
 func initDBs(config *cfg.Config, dbProvider tmnode.DBProvider) (blockStore *store.BlockStore, stateDB dbm.DB, err error) {
@@ -408,7 +417,7 @@ func (cp *Node) Tx(hash string) (*types.Tx, error) {
 	index := r.Index
 
 	resTx := &tmctypes.ResultTx{
-		Hash:     []byte(hash),
+		Hash:     hashBz,
 		Height:   height,
 		Index:    index,
 		TxResult: r.Result,
@@ -420,26 +429,7 @@ func (cp *Node) Tx(hash string) (*types.Tx, error) {
 		return nil, err
 	}
 
-	txResponse, err := makeTxResult(cp.txConfig, resTx, resBlock)
-	if err != nil {
-		return nil, err
-	}
-
-	protoTx, ok := txResponse.Tx.GetCachedValue().(*tx.Tx)
-	if !ok {
-		return nil, fmt.Errorf("expected %T, got %T", tx.Tx{}, txResponse.Tx.GetCachedValue())
-	}
-
-	// // Decode messages
-	// for _, msg := range protoTx.Body.Messages {
-	// 	var stdMsg sdk.Msg
-	// 	err = cp.codec.UnpackAny(msg, &stdMsg)
-	// 	if err != nil {
-	// 		return nil, fmt.Errorf("error while unpacking message: %s", err)
-	// 	}
-	// }
-
-	convTx, err := types.NewTx(txResponse, protoTx)
+	convTx, err := node.TxFromResult(cp.txConfig, resTx, resBlock.Block.Time)
 	if err != nil {
 		return nil, fmt.Errorf("error converting transaction: %s", err.Error())
 	}
@@ -448,18 +438,8 @@ func (cp *Node) Tx(hash string) (*types.Tx, error) {
 }
 
 // Txs implements node.Node
-func (cp *Node) Txs(block *tmctypes.ResultBlock) ([]*types.Tx, error) {
-	txResponses := make([]*types.Tx, len(block.Block.Txs))
-	for i, tmTx := range block.Block.Txs {
-		txResponse, err := cp.Tx(fmt.Sprintf("%X", tmTx.Hash()))
-		if err != nil {
-			return nil, err
-		}
-
-		txResponses[i] = txResponse
-	}
-
-	return txResponses, nil
+func (cp *Node) Txs(block *tmctypes.ResultBlock, results *tmctypes.ResultBlockResults) ([]*types.Tx, error) {
+	return node.TxsFromBlock(cp.txConfig, block, results)
 }
 
 // TxSearch implements node.Node
@@ -536,18 +516,10 @@ func (cp *Node) SubscribeNewBlocks(subscriber string) (<-chan tmctypes.ResultEve
 	return cp.SubscribeEvents(subscriber, "tm.event = 'NewBlock'")
 }
 
-// GetCodeInfo
-func (cp *Node) GetCodeInfo(height int64, codeId uint64) (*wasmtypes.QueryCodeResponse, error) {
-	//TO-DO ...
-	return nil, nil
-}
-
-// GetContractInfo
-func (cp *Node) GetContractInfo(height int64, contractAddr string) (*wasmtypes.QueryContractInfoResponse, error) {
-	//TO-DO ...
-	return nil, nil
-}
-
 // Stop implements node.Node
 func (cp *Node) Stop() {
+	err := cp.source.StoreDB.Close()
+	if err != nil {
+		panic(fmt.Errorf("error while closing application store: %s", err))
+	}
 }
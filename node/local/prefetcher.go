@@ -0,0 +1,143 @@
+package local
+
+import (
+	"sync"
+
+	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// prefetchedBlock contains a block and its results read ahead of being requested
+type prefetchedBlock struct {
+	done chan struct{}
+
+	block      *tmctypes.ResultBlock
+	blockErr   error
+	results    *tmctypes.ResultBlockResults
+	resultsErr error
+
+	blockServed   bool
+	resultsServed bool
+}
+
+// prefetcher reads the blocks and their results concurrently, starting from each requested height and going ahead
+// by the configured number of heights, so that the following requests are served from memory.
+// Each read block is kept until both the block and its results have been served, or until the requested heights
+// have gone past it by more than the prefetched heights, since skipped heights are never requested.
+type prefetcher struct {
+	ahead int64
+
+	// latestHeight returns the height of the most recent stored block
+	latestHeight func() int64
+	readBlock    func(height int64) (*tmctypes.ResultBlock, error)
+	readResults  func(height int64) (*tmctypes.ResultBlockResults, error)
+
+	// readers limits the number of concurrent reads
+	readers chan struct{}
+
+	mu     sync.Mutex
+	blocks map[int64]*prefetchedBlock
+}
+
+// newPrefetcher returns a new prefetcher reading ahead the given number of heights using the given functions
+func newPrefetcher(
+	ahead int,
+	latestHeight func() int64,
+	readBlock func(height int64) (*tmctypes.ResultBlock, error),
+	readResults func(height int64) (*tmctypes.ResultBlockResults, error),
+) *prefetcher {
+	return &prefetcher{
+		ahead:        int64(ahead),
+		latestHeight: latestHeight,
+		readBlock:    readBlock,
+		readResults:  readResults,
+		readers:      make(chan struct{}, ahead),
+		blocks:       map[int64]*prefetchedBlock{},
+	}
+}
+
+// Block returns the block having the given height, reading ahead the following ones
+func (p *prefetcher) Block(height int64) (*tmctypes.ResultBlock, error) {
+	prefetched := p.request(height)
+	<-prefetched.done
+
+	p.served(height, prefetched, func() { prefetched.blockServed = true })
+	return prefetched.block, prefetched.blockErr
+}
+
+// BlockResults returns the results of the block having the given height, reading ahead the following ones
+func (p *prefetcher) BlockResults(height int64) (*tmctypes.ResultBlockResults, error) {
+	prefetched := p.request(height)
+	<-prefetched.done
+
+	p.served(height, prefetched, func() { prefetched.resultsServed = true })
+	return prefetched.results, prefetched.resultsErr
+}
+
+// request returns the block having the given height, starting to read it along with the following ones if they
+// are not being read yet, and evicts the blocks that have been left behind
+func (p *prefetcher) request(height int64) *prefetchedBlock {
+	latest := p.latestHeight()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for stored, prefetched := range p.blocks {
+		if stored < height-p.ahead && isDone(prefetched) {
+			delete(p.blocks, stored)
+		}
+	}
+
+	requested := p.read(height)
+	for next := height + 1; next <= height+p.ahead && next <= latest; next++ {
+		p.read(next)
+	}
+	return requested
+}
+
+// read returns the block having the given height, starting to read it if it is not being read yet.
+// It must be called while holding the lock.
+func (p *prefetcher) read(height int64) *prefetchedBlock {
+	if prefetched, ok := p.blocks[height]; ok {
+		return prefetched
+	}
+
+	prefetched := &prefetchedBlock{done: make(chan struct{})}
+	p.blocks[height] = prefetched
+
+	go func() {
+		p.readers <- struct{}{}
+		defer func() { <-p.readers }()
+
+		prefetched.block, prefetched.blockErr = p.readBlock(height)
+		prefetched.results, prefetched.resultsErr = p.readResults(height)
+		close(prefetched.done)
+	}()
+
+	return prefetched
+}
+
+// served marks a part of the given block as served using mark, and forgets the block once it has been fully served
+// or if it could not be read, so that failed reads are retried
+func (p *prefetcher) served(height int64, prefetched *prefetchedBlock, mark func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	mark()
+
+	failed := prefetched.blockErr != nil || prefetched.resultsErr != nil
+	if failed || (prefetched.blockServed && prefetched.resultsServed) {
+		if p.blocks[height] == prefetched {
+			delete(p.blocks, height)
+		}
+	}
+}
+
+// isDone tells whether the given block has been read
+func isDone(prefetched *prefetchedBlock) bool {
+	select {
+	case <-prefetched.done:
+		return true
+	default:
+		return false
+	}
+}
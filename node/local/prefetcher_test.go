@@ -0,0 +1,119 @@
+package local
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// countingStore serves empty blocks up to a latest height, counting the reads of each height
+type countingStore struct {
+	mu     sync.Mutex
+	latest int64
+	reads  map[int64]int
+	fail   map[int64]bool
+}
+
+func (s *countingStore) latestHeight() int64 {
+	return s.latest
+}
+
+func (s *countingStore) block(height int64) (*tmctypes.ResultBlock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reads[height]++
+	if s.fail[height] {
+		return nil, fmt.Errorf("block %d not readable", height)
+	}
+	return &tmctypes.ResultBlock{}, nil
+}
+
+func (s *countingStore) results(height int64) (*tmctypes.ResultBlockResults, error) {
+	return &tmctypes.ResultBlockResults{Height: height}, nil
+}
+
+func (s *countingStore) readCount(height int64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reads[height]
+}
+
+func newTestPrefetcher(ahead int, latest int64) (*prefetcher, *countingStore) {
+	store := &countingStore{latest: latest, reads: map[int64]int{}, fail: map[int64]bool{}}
+	return newPrefetcher(ahead, store.latestHeight, store.block, store.results), store
+}
+
+func TestPrefetcherReadsAhead(t *testing.T) {
+	p, store := newTestPrefetcher(4, 6)
+
+	for height := int64(1); height <= 6; height++ {
+		_, err := p.Block(height)
+		require.NoError(t, err)
+
+		results, err := p.BlockResults(height)
+		require.NoError(t, err)
+		require.Equal(t, height, results.Height)
+	}
+
+	// Each height is read once and is forgotten once served, without reading past the latest height
+	for height := int64(1); height <= 6; height++ {
+		require.Equal(t, 1, store.readCount(height), "height %d", height)
+	}
+	require.Zero(t, store.readCount(7))
+	require.Empty(t, p.blocks)
+}
+
+func TestPrefetcherEvictsSkippedHeights(t *testing.T) {
+	p, _ := newTestPrefetcher(2, 100)
+
+	_, err := p.Block(1)
+	require.NoError(t, err)
+	_, err = p.BlockResults(1)
+	require.NoError(t, err)
+
+	// Heights 2 and 3 are never requested, and are dropped once the requests have gone past them
+	_, err = p.Block(10)
+	require.NoError(t, err)
+	_, err = p.BlockResults(10)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for _, prefetched := range p.blocks {
+			if !isDone(prefetched) {
+				return false
+			}
+		}
+		return true
+	}, time.Second, time.Millisecond)
+
+	_, err = p.Block(20)
+	require.NoError(t, err)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for height := range p.blocks {
+		require.GreaterOrEqual(t, height, int64(18))
+	}
+}
+
+func TestPrefetcherRetriesFailedReads(t *testing.T) {
+	p, store := newTestPrefetcher(2, 10)
+	store.fail[1] = true
+
+	_, err := p.Block(1)
+	require.Error(t, err)
+
+	store.mu.Lock()
+	store.fail[1] = false
+	store.mu.Unlock()
+
+	_, err = p.Block(1)
+	require.NoError(t, err)
+	require.Equal(t, 2, store.readCount(1))
+}
@@ -43,17 +43,29 @@ type Source struct {
 
 // NewSource returns a new Source instance
 func NewSource(home string, encodingConfig *params.EncodingConfig) (*Source, error) {
-	levelDB, err := sdk.NewLevelDB("application", path.Join(home, "data"))
+	tmCfg, err := parseConfig(home)
 	if err != nil {
 		return nil, err
 	}
-	
-	tmCfg, err := parseConfig(home)
+
+	blockStoreDB, err := tmnode.DefaultDBProvider(&tmnode.DBContext{ID: "blockstore", Config: tmCfg})
 	if err != nil {
 		return nil, err
 	}
 
-	blockStoreDB, err := tmnode.DefaultDBProvider(&tmnode.DBContext{ID: "blockstore", Config: tmCfg})
+	source, err := newSource(home, encodingConfig.Marshaler, tmstore.NewBlockStore(blockStoreDB))
+	if err != nil {
+		return nil, err
+	}
+	source.LegacyAmino = encodingConfig.Amino
+
+	return source, nil
+}
+
+// newSource returns a new Source instance reading the application data contained inside the given home,
+// and using the given block store that might already be used by someone else
+func newSource(home string, codec codec.Codec, blockStore *tmstore.BlockStore) (*Source, error) {
+	levelDB, err := sdk.NewLevelDB("application", path.Join(home, "data"))
 	if err != nil {
 		return nil, err
 	}
@@ -61,10 +73,9 @@ func NewSource(home string, encodingConfig *params.EncodingConfig) (*Source, err
 	return &Source{
 		StoreDB: levelDB,
 
-		Codec:       encodingConfig.Marshaler,
-		LegacyAmino: encodingConfig.Amino,
+		Codec: codec,
 
-		BlockStore: tmstore.NewBlockStore(blockStoreDB),
+		BlockStore: blockStore,
 		Logger:     log.NewTMLogger(log.NewSyncWriter(os.Stdout)).With("module", "explorer"),
 		Cms:        store.NewCommitMultiStore(levelDB),
 	}, nil
@@ -149,6 +160,12 @@ func (k Source) MountMemoryStores(app interface{}, fieldName string) error {
 	return nil
 }
 
+// MountKVStore allows to register a single KV store using the given KVStoreKey.
+// This is useful when only the data of some modules is needed, without having to build the whole app.
+func (k Source) MountKVStore(key *sdk.KVStoreKey) {
+	k.Cms.MountStoreWithDB(key, sdk.StoreTypeIAVL, nil)
+}
+
 // InitStores initializes the stores by mounting the various keys that have been specified.
 // This method MUST be called before using any method that relies on the local storage somehow.
 func (k Source) InitStores() error {
@@ -0,0 +1,154 @@
+package local
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/cosmos/cosmos-sdk/store/prefix"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+
+	"github.com/nuclearblock/archgregator/node"
+)
+
+// wasmCodeDir returns the directory inside which the wasm VM of the node stores the byte code of each code,
+// using the hex encoded code hash as the file name
+func wasmCodeDir(home string) string {
+	return path.Join(home, "wasm", "wasm", "state", "wasm")
+}
+
+// initWasmSource builds the Source used to read the wasm module data at any height from the application store
+func initWasmSource(home string, node *Node) error {
+	source, err := newSource(home, node.codec, node.blockStore)
+	if err != nil {
+		return fmt.Errorf("error while opening application store: %s", err)
+	}
+
+	wasmKey := sdk.NewKVStoreKey(wasmtypes.StoreKey)
+	source.MountKVStore(wasmKey)
+
+	err = source.InitStores()
+	if err != nil {
+		return fmt.Errorf("error while loading application store: %s", err)
+	}
+
+	node.source = source
+	node.wasmKey = wasmKey
+	return nil
+}
+
+// wasmStore returns the store of the wasm module at the given height
+func (cp *Node) wasmStore(height int64) (sdk.KVStore, error) {
+	ctx, err := cp.source.LoadHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("error while loading height %d: %s", height, err)
+	}
+
+	return ctx.KVStore(cp.wasmKey), nil
+}
+
+// GetCodeInfo implements node.Node
+func (cp *Node) GetCodeInfo(height int64, codeId uint64) (*wasmtypes.QueryCodeResponse, error) {
+	store, err := cp.wasmStore(height)
+	if err != nil {
+		return nil, err
+	}
+
+	bz := store.Get(wasmtypes.GetCodeKey(codeId))
+	if bz == nil {
+		return nil, fmt.Errorf("code %d not found at height %d", codeId, height)
+	}
+
+	var codeInfo wasmtypes.CodeInfo
+	err = cp.codec.Unmarshal(bz, &codeInfo)
+	if err != nil {
+		return nil, fmt.Errorf("error while unmarshalling code %d info: %s", codeId, err)
+	}
+
+	// The byte code is kept by the wasm VM outside the application store, and might have been pruned
+	data, err := ioutil.ReadFile(path.Join(wasmCodeDir(cp.home), hex.EncodeToString(codeInfo.CodeHash)))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error while reading code %d byte code: %s", codeId, err)
+	}
+
+	return &wasmtypes.QueryCodeResponse{
+		CodeInfoResponse: &wasmtypes.CodeInfoResponse{
+			CodeID:   codeId,
+			Creator:  codeInfo.Creator,
+			DataHash: codeInfo.CodeHash,
+		},
+		Data: data,
+	}, nil
+}
+
+// GetContractInfo implements node.Node
+func (cp *Node) GetContractInfo(height int64, contractAddr string) (*wasmtypes.QueryContractInfoResponse, error) {
+	// The address is decoded without checking its prefix, since each indexed chain can use a different one
+	_, address, err := bech32.DecodeAndConvert(contractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing contract address: %s", err)
+	}
+
+	store, err := cp.wasmStore(height)
+	if err != nil {
+		return nil, err
+	}
+
+	bz := store.Get(wasmtypes.GetContractAddressKey(address))
+	if bz == nil {
+		return nil, fmt.Errorf("contract %s not found at height %d", contractAddr, height)
+	}
+
+	var contractInfo wasmtypes.ContractInfo
+	err = cp.codec.Unmarshal(bz, &contractInfo)
+	if err != nil {
+		return nil, fmt.Errorf("error while unmarshalling contract %s info: %s", contractAddr, err)
+	}
+
+	return &wasmtypes.QueryContractInfoResponse{
+		Address:      contractAddr,
+		ContractInfo: contractInfo,
+	}, nil
+}
+
+// SmartContractState implements node.Node
+// Smart queries need to run the contract code inside the wasm VM, which is not available to the local node, so they
+// are never answered
+func (cp *Node) SmartContractState(_ int64, contractAddr string, _ []byte) (*wasmtypes.QuerySmartContractStateResponse, error) {
+	return nil, &node.ContractQueryError{
+		ContractAddr: contractAddr,
+		Reason:       "smart queries are not supported by the local node",
+	}
+}
+
+// AllContractState implements node.Node
+func (cp *Node) AllContractState(height int64, contractAddr string) (*wasmtypes.QueryAllContractStateResponse, error) {
+	// The address is decoded without checking its prefix, since each indexed chain can use a different one
+	_, address, err := bech32.DecodeAndConvert(contractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing contract address: %s", err)
+	}
+
+	store, err := cp.wasmStore(height)
+	if err != nil {
+		return nil, err
+	}
+
+	contractStore := prefix.NewStore(store, wasmtypes.GetContractStorePrefix(address))
+	iterator := contractStore.Iterator(nil, nil)
+	defer iterator.Close()
+
+	var models []wasmtypes.Model
+	for ; iterator.Valid(); iterator.Next() {
+		models = append(models, wasmtypes.Model{
+			Key:   iterator.Key(),
+			Value: iterator.Value(),
+		})
+	}
+
+	return &wasmtypes.QueryAllContractStateResponse{Models: models}, nil
+}
@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"fmt"
 
 	constypes "github.com/tendermint/tendermint/consensus/types"
 	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
@@ -33,10 +34,10 @@ type Node interface {
 	// decoding fails.
 	Tx(hash string) (*types.Tx, error)
 
-	// Txs queries for all the transactions in a block. Transactions are returned
-	// in the sdk.TxResponse format which internally contains an sdk.Tx. An error is
-	// returned if any query fails.
-	Txs(block *tmctypes.ResultBlock) ([]*types.Tx, error)
+	// Txs returns all the transactions in a block, using the given block results to build their responses.
+	// Transactions are returned in the sdk.TxResponse format which internally contains an sdk.Tx.
+	// An error is returned if any transaction cannot be decoded.
+	Txs(block *tmctypes.ResultBlock, results *tmctypes.ResultBlockResults) ([]*types.Tx, error)
 
 	// TxSearch defines a method to search for a paginated set of transactions by DeliverTx event search criteria.
 	TxSearch(query string, page *int, perPage *int, orderBy string) (*tmctypes.ResultTxSearch, error)
@@ -59,6 +60,34 @@ type Node interface {
 	// GetCodeInfo helps to get contract instance data
 	GetContractInfo(height int64, contractAddr string) (*wasmtypes.QueryContractInfoResponse, error)
 
+	// SmartContractState runs the given JSON query against the contract having the given address at the given height.
+	// A *ContractQueryError is returned if the query has been run but could not be answered, while any other error
+	// means that the query could not be run and might succeed if retried.
+	SmartContractState(height int64, contractAddr string, query []byte) (*wasmtypes.QuerySmartContractStateResponse, error)
+
+	// AllContractState returns all the raw key-value pairs stored by the contract having the given address at the given height
+	AllContractState(height int64, contractAddr string) (*wasmtypes.QueryAllContractStateResponse, error)
+
 	// Stop defers the node stop execution to the client.
 	Stop()
 }
+
+// ContractQueryError is returned by SmartContractState when a query has been handled but could not be answered,
+// and running it again would not change the outcome: e.g. the contract does not support the query, or the node
+// cannot run smart queries at all
+type ContractQueryError struct {
+	ContractAddr string
+	Reason       string
+}
+
+// Error implements error
+func (e *ContractQueryError) Error() string {
+	return fmt.Sprintf("contract %s could not answer the query: %s", e.ContractAddr, e.Reason)
+}
+
+// IsContractQueryError tells whether the given error has been returned because a query could not be answered,
+// rather than because it could not be run
+func IsContractQueryError(err error) bool {
+	_, ok := err.(*ContractQueryError)
+	return ok
+}
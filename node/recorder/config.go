@@ -0,0 +1,54 @@
+package recorder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nuclearblock/archgregator/node/remote"
+)
+
+// Details represents the nodeconfig.Details implementation for a recorder node
+type Details struct {
+	Path   string          `yaml:"path"`
+	Remote *remote.Details `yaml:"remote"`
+}
+
+func NewDetails(path string, remote *remote.Details) *Details {
+	return &Details{
+		Path:   path,
+		Remote: remote,
+	}
+}
+
+// Validate implements nodeconfig.Details
+func (d *Details) Validate() error {
+	if strings.TrimSpace(d.Path) == "" {
+		return fmt.Errorf("archive path cannot be empty")
+	}
+
+	if d.Remote == nil {
+		return fmt.Errorf("remote config cannot be null")
+	}
+
+	return d.Remote.Validate()
+}
+
+// ReplayDetails represents the nodeconfig.Details implementation for a replay node
+type ReplayDetails struct {
+	Path string `yaml:"path"`
+}
+
+func NewReplayDetails(path string) *ReplayDetails {
+	return &ReplayDetails{
+		Path: path,
+	}
+}
+
+// Validate implements nodeconfig.Details
+func (d *ReplayDetails) Validate() error {
+	if strings.TrimSpace(d.Path) == "" {
+		return fmt.Errorf("archive path cannot be empty")
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package recorder
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+
+	"github.com/nuclearblock/archgregator/node"
+	"github.com/nuclearblock/archgregator/node/fixture"
+	"github.com/nuclearblock/archgregator/node/remote"
+	"github.com/nuclearblock/archgregator/node/store"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+var (
+	_ node.Node = &Node{}
+)
+
+// Node represents a node.Node implementation that wraps a remote node, storing every genesis, block,
+// block results, transactions, code info and contract info response inside an archive directory.
+// The archive uses the store layout with gzipped files, and can be served back using a replay node.
+type Node struct {
+	node.Node
+
+	store *store.Store
+}
+
+// NewNode returns a new Node instance recording the responses of the configured remote node
+func NewNode(cfg *Details, txConfig client.TxConfig, codec codec.Codec) (*Node, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	remoteNode, err := remote.NewNode(cfg.Remote, txConfig, codec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Node{
+		Node:  remoteNode,
+		store: store.NewCompressedStore(cfg.Path, codec),
+	}, nil
+}
+
+// NewReplayNode returns a new node.Node instance serving the responses stored inside the configured archive
+func NewReplayNode(cfg *ReplayDetails, codec codec.Codec) (node.Node, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return fixture.NewStoreNode(store.NewCompressedStore(cfg.Path, codec)), nil
+}
+
+// Genesis implements node.Node
+func (n *Node) Genesis() (*tmctypes.ResultGenesis, error) {
+	genesis, err := n.Node.Genesis()
+	if err != nil {
+		return nil, err
+	}
+
+	err = n.store.WriteGenesis(genesis)
+	if err != nil {
+		return nil, fmt.Errorf("error while recording genesis: %s", err)
+	}
+
+	return genesis, nil
+}
+
+// Block implements node.Node
+func (n *Node) Block(height int64) (*tmctypes.ResultBlock, error) {
+	block, err := n.Node.Block(height)
+	if err != nil {
+		return nil, err
+	}
+
+	err = n.store.WriteBlock(block)
+	if err != nil {
+		return nil, fmt.Errorf("error while recording block %d: %s", height, err)
+	}
+
+	return block, nil
+}
+
+// BlockResults implements node.Node
+func (n *Node) BlockResults(height int64) (*tmctypes.ResultBlockResults, error) {
+	results, err := n.Node.BlockResults(height)
+	if err != nil {
+		return nil, err
+	}
+
+	err = n.store.WriteBlockResults(results)
+	if err != nil {
+		return nil, fmt.Errorf("error while recording block results %d: %s", height, err)
+	}
+
+	return results, nil
+}
+
+// Txs implements node.Node
+func (n *Node) Txs(block *tmctypes.ResultBlock, results *tmctypes.ResultBlockResults) ([]*types.Tx, error) {
+	txs, err := n.Node.Txs(block, results)
+	if err != nil {
+		return nil, err
+	}
+
+	err = n.store.WriteTxs(block.Block.Height, txs)
+	if err != nil {
+		return nil, fmt.Errorf("error while recording txs of block %d: %s", block.Block.Height, err)
+	}
+
+	return txs, nil
+}
+
+// GetCodeInfo implements node.Node
+func (n *Node) GetCodeInfo(height int64, codeID uint64) (*wasmtypes.QueryCodeResponse, error) {
+	res, err := n.Node.GetCodeInfo(height, codeID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = n.store.WriteCodeInfo(codeID, res)
+	if err != nil {
+		return nil, fmt.Errorf("error while recording code %d info: %s", codeID, err)
+	}
+
+	return res, nil
+}
+
+// GetContractInfo implements node.Node
+func (n *Node) GetContractInfo(height int64, contractAddr string) (*wasmtypes.QueryContractInfoResponse, error) {
+	res, err := n.Node.GetContractInfo(height, contractAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = n.store.WriteContractInfo(height, contractAddr, res)
+	if err != nil {
+		return nil, fmt.Errorf("error while recording contract %s info: %s", contractAddr, err)
+	}
+
+	return res, nil
+}
+
+// SmartContractState implements node.Node
+func (n *Node) SmartContractState(height int64, contractAddr string, query []byte) (*wasmtypes.QuerySmartContractStateResponse, error) {
+	res, err := n.Node.SmartContractState(height, contractAddr, query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = n.store.WriteSmartContractState(height, contractAddr, query, res)
+	if err != nil {
+		return nil, fmt.Errorf("error while recording contract %s smart state: %s", contractAddr, err)
+	}
+
+	return res, nil
+}
+
+// AllContractState implements node.Node
+func (n *Node) AllContractState(height int64, contractAddr string) (*wasmtypes.QueryAllContractStateResponse, error) {
+	res, err := n.Node.AllContractState(height, contractAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = n.store.WriteAllContractState(height, contractAddr, res)
+	if err != nil {
+		return nil, fmt.Errorf("error while recording contract %s raw state: %s", contractAddr, err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,56 @@
+package recorder
+
+import (
+	"testing"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nuclearblock/archgregator/node"
+	"github.com/nuclearblock/archgregator/node/store"
+)
+
+// adminNode is a node.Node whose contracts have a different admin at each height
+type adminNode struct {
+	node.Node
+}
+
+// GetContractInfo implements node.Node
+func (n *adminNode) GetContractInfo(height int64, contractAddr string) (*wasmtypes.QueryContractInfoResponse, error) {
+	admin := "archway1admin"
+	if height > 100 {
+		admin = "archway1newadmin"
+	}
+	return &wasmtypes.QueryContractInfoResponse{
+		Address:      contractAddr,
+		ContractInfo: wasmtypes.ContractInfo{CodeID: 1, Admin: admin},
+	}, nil
+}
+
+func TestReplayContractInfo(t *testing.T) {
+	cdc := codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+	path := t.TempDir()
+	recorder := &Node{Node: &adminNode{}, store: store.NewCompressedStore(path, cdc)}
+
+	_, err := recorder.GetContractInfo(100, "archway1contract")
+	require.NoError(t, err)
+	_, err = recorder.GetContractInfo(101, "archway1contract")
+	require.NoError(t, err)
+
+	replay, err := NewReplayNode(NewReplayDetails(path), cdc)
+	require.NoError(t, err)
+
+	// The info recorded at each height is served back, even if the contract has changed in the meantime
+	res, err := replay.GetContractInfo(100, "archway1contract")
+	require.NoError(t, err)
+	require.Equal(t, "archway1admin", res.Admin)
+
+	res, err = replay.GetContractInfo(101, "archway1contract")
+	require.NoError(t, err)
+	require.Equal(t, "archway1newadmin", res.Admin)
+
+	_, err = replay.GetContractInfo(102, "archway1contract")
+	require.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package remote
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/nuclearblock/archgregator/logging"
+	"github.com/nuclearblock/archgregator/node/store"
+)
+
+const (
+	cacheKindBlock        = "block"
+	cacheKindBlockResults = "block_results"
+	cacheKindCodeInfo     = "code_info"
+
+	cacheResultHit     = "hit"
+	cacheResultDiskHit = "disk_hit"
+	cacheResultMiss    = "miss"
+)
+
+// cache keeps the most recently used immutable responses in memory and, optionally, all of them on disk.
+// Concurrent requests of the same response are coalesced so that the node is queried only once.
+type cache struct {
+	responses *lru.Cache
+	store     *store.Store
+	group     singleflight.Group
+}
+
+// newCache returns a new cache instance built using the given configuration.
+// The disk cache uses the compressed store layout, so its directory can also be served by a replay node.
+func newCache(cfg *CacheConfig, codec codec.Codec) (*cache, error) {
+	responses, err := lru.New(cfg.Size)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating cache: %s", err)
+	}
+
+	var diskStore *store.Store
+	if cfg.Path != "" {
+		diskStore = store.NewCompressedStore(cfg.Path, codec)
+	}
+
+	return &cache{
+		responses: responses,
+		store:     diskStore,
+	}, nil
+}
+
+// diskAccessor allows to read and write a response on the disk cache
+type diskAccessor struct {
+	read  func(s *store.Store) (interface{}, error)
+	write func(s *store.Store, value interface{}) error
+}
+
+// get returns the response of the given kind identified by the given key, looking for it inside the memory
+// cache first and then inside the disk cache. If it cannot be found, it is loaded using the given function
+// and stored inside both caches.
+func (c *cache) get(kind, key string, disk diskAccessor, load func() (interface{}, error)) (interface{}, error) {
+	cacheKey := kind + "/" + key
+	if value, ok := c.responses.Get(cacheKey); ok {
+		logging.NodeCacheRequests.WithLabelValues(kind, cacheResultHit).Inc()
+		return value, nil
+	}
+
+	value, err, _ := c.group.Do(cacheKey, func() (interface{}, error) {
+		if c.store != nil {
+			value, err := disk.read(c.store)
+			if err == nil {
+				logging.NodeCacheRequests.WithLabelValues(kind, cacheResultDiskHit).Inc()
+				c.responses.Add(cacheKey, value)
+				return value, nil
+			}
+		}
+
+		logging.NodeCacheRequests.WithLabelValues(kind, cacheResultMiss).Inc()
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+
+		if c.store != nil {
+			err = disk.write(c.store, value)
+			if err != nil {
+				return nil, fmt.Errorf("error while caching %s %s: %s", kind, key, err)
+			}
+		}
+
+		c.responses.Add(cacheKey, value)
+		return value, nil
+	})
+	return value, err
+}
@@ -0,0 +1,116 @@
+package remote
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/stretchr/testify/require"
+	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	"github.com/nuclearblock/archgregator/node/store"
+)
+
+// newTestCache returns a new cache keeping the given number of responses in memory and, when path is set,
+// all of them on disk
+func newTestCache(t *testing.T, size int, path string) *cache {
+	c, err := newCache(NewCacheConfig(size, path), codec.NewProtoCodec(codectypes.NewInterfaceRegistry()))
+	require.NoError(t, err)
+	return c
+}
+
+// resultsDisk is the diskAccessor of the results of the block having the given height
+func resultsDisk(height int64) diskAccessor {
+	return diskAccessor{
+		read: func(s *store.Store) (interface{}, error) {
+			return s.ReadBlockResults(height)
+		},
+		write: func(s *store.Store, value interface{}) error {
+			return s.WriteBlockResults(value.(*tmctypes.ResultBlockResults))
+		},
+	}
+}
+
+// getResults returns the results of the block having the given height from the given cache,
+// counting the times they are loaded from the node
+func getResults(t *testing.T, c *cache, height int64, loads *int32) *tmctypes.ResultBlockResults {
+	value, err := c.get(cacheKindBlockResults, fmt.Sprintf("%d", height), resultsDisk(height), func() (interface{}, error) {
+		atomic.AddInt32(loads, 1)
+		return &tmctypes.ResultBlockResults{Height: height}, nil
+	})
+	require.NoError(t, err)
+	return value.(*tmctypes.ResultBlockResults)
+}
+
+func TestCacheHitAndMiss(t *testing.T) {
+	c := newTestCache(t, 10, "")
+
+	var loads int32
+	require.Equal(t, int64(10), getResults(t, c, 10, &loads).Height)
+	require.Equal(t, int64(10), getResults(t, c, 10, &loads).Height)
+	require.Equal(t, int32(1), loads)
+
+	require.Equal(t, int64(11), getResults(t, c, 11, &loads).Height)
+	require.Equal(t, int32(2), loads)
+}
+
+func TestCacheEviction(t *testing.T) {
+	c := newTestCache(t, 1, "")
+
+	// Only the most recently used response is kept, so the first one is loaded again
+	var loads int32
+	getResults(t, c, 10, &loads)
+	getResults(t, c, 11, &loads)
+	getResults(t, c, 10, &loads)
+	require.Equal(t, int32(3), loads)
+}
+
+func TestCacheDiskHit(t *testing.T) {
+	path := t.TempDir()
+
+	var loads int32
+	getResults(t, newTestCache(t, 10, path), 10, &loads)
+	require.Equal(t, int32(1), loads)
+
+	// A new cache using the same directory does not load the response again
+	require.Equal(t, int64(10), getResults(t, newTestCache(t, 10, path), 10, &loads).Height)
+	require.Equal(t, int32(1), loads)
+}
+
+func TestCacheCoalescesConcurrentRequests(t *testing.T) {
+	c := newTestCache(t, 10, "")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var loads int32
+	load := func() (interface{}, error) {
+		if atomic.AddInt32(&loads, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return &tmctypes.ResultBlockResults{Height: 10}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := c.get(cacheKindBlockResults, "10", resultsDisk(10), load)
+			require.NoError(t, err)
+			require.Equal(t, int64(10), value.(*tmctypes.ResultBlockResults).Height)
+		}()
+	}
+
+	// The requests arriving while the first one is being loaded wait for its response
+	<-started
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), loads)
+}
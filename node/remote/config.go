@@ -6,19 +6,21 @@ import (
 
 // Details represents a node details for a remote node
 type Details struct {
-	RPC  *RPCConfig  `yaml:"rpc"`
-	GRPC *GRPCConfig `yaml:"grpc"`
+	RPC   *RPCConfig   `yaml:"rpc"`
+	GRPC  *GRPCConfig  `yaml:"grpc"`
+	Cache *CacheConfig `yaml:"cache,omitempty"`
 }
 
-func NewDetails(rpc *RPCConfig, grpc *GRPCConfig) *Details {
+func NewDetails(rpc *RPCConfig, grpc *GRPCConfig, cache *CacheConfig) *Details {
 	return &Details{
-		RPC:  rpc,
-		GRPC: grpc,
+		RPC:   rpc,
+		GRPC:  grpc,
+		Cache: cache,
 	}
 }
 
 func DefaultDetails() *Details {
-	return NewDetails(DefaultRPCConfig(), DefaultGrpcConfig())
+	return NewDetails(DefaultRPCConfig(), DefaultGrpcConfig(), nil)
 }
 
 // Validate implements node.Details
@@ -31,45 +33,124 @@ func (d *Details) Validate() error {
 		return fmt.Errorf("grpc config cannot be null")
 	}
 
+	if d.RPC.RateLimit != nil {
+		if err := d.RPC.RateLimit.Validate(); err != nil {
+			return fmt.Errorf("invalid rpc rate limit: %s", err)
+		}
+	}
+
+	if d.GRPC.RateLimit != nil {
+		if err := d.GRPC.RateLimit.Validate(); err != nil {
+			return fmt.Errorf("invalid grpc rate limit: %s", err)
+		}
+	}
+
+	if d.Cache != nil && d.Cache.Size <= 0 {
+		return fmt.Errorf("cache size must be greater than 0")
+	}
+
 	return nil
 }
 
 // RPCConfig contains the configuration for the RPC endpoint
 type RPCConfig struct {
-	ClientName     string `yaml:"client_name"`
-	Address        string `yaml:"address"`
-	MaxConnections int    `yaml:"max_connections"`
+	ClientName     string           `yaml:"client_name"`
+	Address        string           `yaml:"address"`
+	MaxConnections int              `yaml:"max_connections"`
+	RateLimit      *RateLimitConfig `yaml:"rate_limit,omitempty"`
 }
 
 // NewRPCConfig allows to build a new RPCConfig instance
-func NewRPCConfig(clientName, address string, maxConnections int) *RPCConfig {
+func NewRPCConfig(clientName, address string, maxConnections int, rateLimit *RateLimitConfig) *RPCConfig {
 	return &RPCConfig{
 		ClientName:     clientName,
 		Address:        address,
 		MaxConnections: maxConnections,
+		RateLimit:      rateLimit,
 	}
 }
 
 // DefaultRPCConfig returns the default instance of RPCConfig
 func DefaultRPCConfig() *RPCConfig {
-	return NewRPCConfig("archgregator", "http://localhost:26657", 20)
+	return NewRPCConfig("archgregator", "http://localhost:26657", 20, nil)
 }
 
 // GRPCConfig contains the configuration for the RPC endpoint
 type GRPCConfig struct {
-	Address  string `yaml:"address"`
-	Insecure bool   `yaml:"insecure"`
+	Address   string           `yaml:"address"`
+	Insecure  bool             `yaml:"insecure"`
+	RateLimit *RateLimitConfig `yaml:"rate_limit,omitempty"`
 }
 
 // NewGrpcConfig allows to build a new GrpcConfig instance
-func NewGrpcConfig(address string, insecure bool) *GRPCConfig {
+func NewGrpcConfig(address string, insecure bool, rateLimit *RateLimitConfig) *GRPCConfig {
 	return &GRPCConfig{
-		Address:  address,
-		Insecure: insecure,
+		Address:   address,
+		Insecure:  insecure,
+		RateLimit: rateLimit,
 	}
 }
 
 // DefaultGrpcConfig returns the default instance of a GrpcConfig
 func DefaultGrpcConfig() *GRPCConfig {
-	return NewGrpcConfig("localhost:9090", true)
+	return NewGrpcConfig("localhost:9090", true, nil)
+}
+
+// RateLimitConfig contains the configuration used to limit the requests sent to an endpoint.
+// RequestsPerSecond and Burst configure a token bucket, while MaxInFlight caps the number of concurrent requests.
+// A zero value disables the corresponding limit.
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+	MaxInFlight       int     `yaml:"max_in_flight"`
+}
+
+// NewRateLimitConfig allows to build a new RateLimitConfig instance
+func NewRateLimitConfig(requestsPerSecond float64, burst, maxInFlight int) *RateLimitConfig {
+	return &RateLimitConfig{
+		RequestsPerSecond: requestsPerSecond,
+		Burst:             burst,
+		MaxInFlight:       maxInFlight,
+	}
+}
+
+// DefaultRateLimitConfig returns the default instance of a RateLimitConfig
+func DefaultRateLimitConfig() *RateLimitConfig {
+	return NewRateLimitConfig(10, 20, 10)
+}
+
+// Validate checks the given configuration, returning an error if it is not valid
+func (c *RateLimitConfig) Validate() error {
+	if c.RequestsPerSecond < 0 {
+		return fmt.Errorf("requests per second cannot be negative")
+	}
+
+	if c.Burst < 0 {
+		return fmt.Errorf("burst cannot be negative")
+	}
+
+	if c.MaxInFlight < 0 {
+		return fmt.Errorf("max in flight requests cannot be negative")
+	}
+
+	return nil
+}
+
+// CacheConfig contains the configuration for the cache of the immutable node responses
+type CacheConfig struct {
+	Size int    `yaml:"size"`
+	Path string `yaml:"path"`
+}
+
+// NewCacheConfig allows to build a new CacheConfig instance
+func NewCacheConfig(size int, path string) *CacheConfig {
+	return &CacheConfig{
+		Size: size,
+		Path: path,
+	}
+}
+
+// DefaultCacheConfig returns the default instance of a CacheConfig
+func DefaultCacheConfig() *CacheConfig {
+	return NewCacheConfig(1000, "")
 }
@@ -0,0 +1,241 @@
+package remote
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nuclearblock/archgregator/logging"
+)
+
+const (
+	// minBackoff and maxBackoff are the bounds of the pause applied to all the requests
+	// sent to an endpoint after it replies that it is receiving too many requests
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+
+	// minRateFraction is the lowest fraction of the configured rate limit the adaptive slowdown can reach
+	minRateFraction = 16
+
+	throttleReasonRateLimit   = "rate_limit"
+	throttleReasonMaxInFlight = "max_in_flight"
+	throttleReasonBackoff     = "backoff"
+	throttleReasonRemote      = "remote"
+)
+
+// limiter limits the requests sent to a single endpoint using a token bucket and a cap on the in-flight requests.
+// When the endpoint replies that it is receiving too many requests, all the following requests are paused using
+// an exponential backoff and the token bucket rate is halved, to be slowly restored as soon as requests succeed.
+// The backoff is halved by each request succeeding after the pause has elapsed, so that it keeps growing while the
+// endpoint alternates successes and rejections.
+type limiter struct {
+	endpoint string
+
+	tokens    *rate.Limiter
+	baseLimit rate.Limit
+	slots     chan struct{}
+
+	mtx         sync.Mutex
+	backoff     time.Duration
+	pausedUntil time.Time
+}
+
+// newLimiter returns a new limiter for the given endpoint. A nil configuration disables all the limits,
+// while still slowing down the requests when the endpoint is receiving too many of them.
+func newLimiter(endpoint string, cfg *RateLimitConfig) *limiter {
+	l := &limiter{endpoint: endpoint}
+	if cfg == nil {
+		return l
+	}
+
+	if cfg.RequestsPerSecond > 0 {
+		burst := cfg.Burst
+		if burst <= 0 {
+			burst = int(cfg.RequestsPerSecond) + 1
+		}
+		l.baseLimit = rate.Limit(cfg.RequestsPerSecond)
+		l.tokens = rate.NewLimiter(l.baseLimit, burst)
+	}
+
+	if cfg.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, cfg.MaxInFlight)
+	}
+
+	return l
+}
+
+// throttled tracks a request that has been throttled for the given reason
+func (l *limiter) throttled(reason string) {
+	logging.NodeThrottledRequests.WithLabelValues(l.endpoint, reason).Inc()
+}
+
+// sleep waits for the given duration, returning an error if the context is done before
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// acquire blocks until a new request can be sent to the endpoint.
+// It returns the function that must be called once the request has completed,
+// telling whether the endpoint replied that it is receiving too many requests.
+func (l *limiter) acquire(ctx context.Context) (func(tooManyRequests bool), error) {
+	l.mtx.Lock()
+	pause := time.Until(l.pausedUntil)
+	l.mtx.Unlock()
+
+	if pause > 0 {
+		l.throttled(throttleReasonBackoff)
+		if err := sleep(ctx, pause); err != nil {
+			return nil, err
+		}
+	}
+
+	if l.tokens != nil {
+		reservation := l.tokens.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			l.throttled(throttleReasonRateLimit)
+			if err := sleep(ctx, delay); err != nil {
+				reservation.Cancel()
+				return nil, err
+			}
+		}
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			l.throttled(throttleReasonMaxInFlight)
+			select {
+			case l.slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	var once sync.Once
+	return func(tooManyRequests bool) {
+		once.Do(func() { l.release(tooManyRequests) })
+	}, nil
+}
+
+// release frees the slot of a completed request, adapting the limits based on its outcome
+func (l *limiter) release(tooManyRequests bool) {
+	if l.slots != nil {
+		<-l.slots
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := time.Now()
+	if tooManyRequests {
+		l.throttled(throttleReasonRemote)
+
+		// The requests sent before the pause started are rejected for the same reason
+		if now.Before(l.pausedUntil) {
+			return
+		}
+
+		l.backoff *= 2
+		if l.backoff < minBackoff {
+			l.backoff = minBackoff
+		}
+		if l.backoff > maxBackoff {
+			l.backoff = maxBackoff
+		}
+		l.pausedUntil = now.Add(l.backoff)
+
+		if l.tokens != nil {
+			limit := l.tokens.Limit() / 2
+			if limit < l.baseLimit/minRateFraction {
+				limit = l.baseLimit / minRateFraction
+			}
+			l.tokens.SetLimit(limit)
+		}
+		return
+	}
+
+	if !now.Before(l.pausedUntil) {
+		l.backoff /= 2
+		if l.backoff < minBackoff {
+			l.backoff = 0
+		}
+	}
+
+	if l.tokens != nil && l.tokens.Limit() < l.baseLimit {
+		limit := l.tokens.Limit() * 1.1
+		if limit > l.baseLimit {
+			limit = l.baseLimit
+		}
+		l.tokens.SetLimit(limit)
+	}
+}
+
+// unaryInterceptor implements grpc.UnaryClientInterceptor limiting the gRPC requests
+func (l *limiter) unaryInterceptor(
+	ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+) error {
+	done, err := l.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = invoker(ctx, method, req, reply, cc, opts...)
+	done(status.Code(err) == codes.ResourceExhausted)
+	return err
+}
+
+// limitedTransport represents an http.RoundTripper limiting the RPC requests
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *limiter
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done, err := t.limiter.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		done(false)
+		return nil, err
+	}
+
+	// The request is in flight until its body has been read
+	res.Body = &releasingBody{
+		ReadCloser: res.Body,
+		release:    func() { done(res.StatusCode == http.StatusTooManyRequests) },
+	}
+	return res, nil
+}
+
+// releasingBody represents a response body that releases its request once closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close implements io.Closer
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,62 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// elapse ends the current pause of the given limiter, as if its backoff had elapsed
+func elapse(l *limiter) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.pausedUntil = time.Now().Add(-time.Millisecond)
+}
+
+func TestLimiterBackoffGrowsAcrossSuccesses(t *testing.T) {
+	l := newLimiter("test", nil)
+
+	l.release(true)
+	require.Equal(t, minBackoff, l.backoff)
+
+	// Requests completing during the pause neither reset nor grow the backoff
+	l.release(false)
+	l.release(true)
+	require.Equal(t, minBackoff, l.backoff)
+
+	elapse(l)
+	l.release(true)
+	require.Equal(t, 2*minBackoff, l.backoff)
+
+	elapse(l)
+	l.release(true)
+	require.Equal(t, 4*minBackoff, l.backoff)
+
+	// A single success after the pause only halves the backoff
+	elapse(l)
+	l.release(false)
+	require.Equal(t, 2*minBackoff, l.backoff)
+
+	elapse(l)
+	l.release(true)
+	require.Equal(t, 4*minBackoff, l.backoff)
+}
+
+func TestLimiterBackoffDecays(t *testing.T) {
+	l := newLimiter("test", nil)
+	for i := 0; i < 10; i++ {
+		elapse(l)
+		l.release(true)
+	}
+	require.Equal(t, maxBackoff, l.backoff)
+
+	// Each success halves the backoff, until it falls below the minimum one
+	elapse(l)
+	successes := 0
+	for l.backoff > 0 {
+		l.release(false)
+		successes++
+	}
+	require.Equal(t, 6, successes)
+}
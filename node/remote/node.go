@@ -10,13 +10,19 @@ import (
 
 	tmtypes "github.com/tendermint/tendermint/types"
 
+	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/codec"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/query"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	constypes "github.com/tendermint/tendermint/consensus/types"
 	tmjson "github.com/tendermint/tendermint/libs/json"
 
 	"github.com/nuclearblock/archgregator/node"
+	"github.com/nuclearblock/archgregator/node/store"
 
 	"github.com/cosmos/cosmos-sdk/types/tx"
 
@@ -39,14 +45,18 @@ var (
 type Node struct {
 	ctx             context.Context
 	codec           codec.Codec
+	txConfig        client.TxConfig
 	client          *httpclient.HTTP
 	txServiceClient tx.ServiceClient
 	grpcConnection  *grpc.ClientConn
 	wasmClient      wasmtypes.QueryClient
+
+	// cache, if not nil, contains the immutable responses already returned
+	cache *cache
 }
 
 // NewNode allows to build a new Node instance
-func NewNode(cfg *Details, codec codec.Codec) (*Node, error) {
+func NewNode(cfg *Details, txConfig client.TxConfig, codec codec.Codec) (*Node, error) {
 	httpClient, err := jsonrpcclient.DefaultHTTPClient(cfg.RPC.Address)
 	if err != nil {
 		return nil, err
@@ -58,6 +68,10 @@ func NewNode(cfg *Details, codec codec.Codec) (*Node, error) {
 		return nil, fmt.Errorf("invalid HTTP Transport: %T", httpTransport)
 	}
 	httpTransport.MaxConnsPerHost = cfg.RPC.MaxConnections
+	httpClient.Transport = &limitedTransport{
+		base:    httpTransport,
+		limiter: newLimiter("rpc", cfg.RPC.RateLimit),
+	}
 
 	rpcClient, err := httpclient.NewWithClient(cfg.RPC.Address, "/websocket", httpClient)
 	if err != nil {
@@ -76,14 +90,25 @@ func NewNode(cfg *Details, codec codec.Codec) (*Node, error) {
 
 	wasmQueryClient := wasmtypes.NewQueryClient(grpcConnection)
 
+	var responsesCache *cache
+	if cfg.Cache != nil {
+		responsesCache, err = newCache(cfg.Cache, codec)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &Node{
-		ctx:   context.Background(),
-		codec: codec,
+		ctx:      context.Background(),
+		codec:    codec,
+		txConfig: txConfig,
 
 		client:          rpcClient,
 		txServiceClient: tx.NewServiceClient(grpcConnection),
 		grpcConnection:  grpcConnection,
 		wasmClient:      wasmQueryClient,
+
+		cache: responsesCache,
 	}, nil
 }
 
@@ -164,12 +189,48 @@ func (cp *Node) LatestHeight() (int64, error) {
 
 // Block implements node.Node
 func (cp *Node) Block(height int64) (*tmctypes.ResultBlock, error) {
-	return cp.client.Block(cp.ctx, &height)
+	if cp.cache == nil {
+		return cp.client.Block(cp.ctx, &height)
+	}
+
+	disk := diskAccessor{
+		read: func(s *store.Store) (interface{}, error) {
+			return s.ReadBlock(height)
+		},
+		write: func(s *store.Store, value interface{}) error {
+			return s.WriteBlock(value.(*tmctypes.ResultBlock))
+		},
+	}
+	value, err := cp.cache.get(cacheKindBlock, fmt.Sprintf("%d", height), disk, func() (interface{}, error) {
+		return cp.client.Block(cp.ctx, &height)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*tmctypes.ResultBlock), nil
 }
 
 // BlockResults implements node.Node
 func (cp *Node) BlockResults(height int64) (*tmctypes.ResultBlockResults, error) {
-	return cp.client.BlockResults(cp.ctx, &height)
+	if cp.cache == nil {
+		return cp.client.BlockResults(cp.ctx, &height)
+	}
+
+	disk := diskAccessor{
+		read: func(s *store.Store) (interface{}, error) {
+			return s.ReadBlockResults(height)
+		},
+		write: func(s *store.Store, value interface{}) error {
+			return s.WriteBlockResults(value.(*tmctypes.ResultBlockResults))
+		},
+	}
+	value, err := cp.cache.get(cacheKindBlockResults, fmt.Sprintf("%d", height), disk, func() (interface{}, error) {
+		return cp.client.BlockResults(cp.ctx, &height)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*tmctypes.ResultBlockResults), nil
 }
 
 // Tx implements node.Node
@@ -188,18 +249,8 @@ func (cp *Node) Tx(hash string) (*types.Tx, error) {
 }
 
 // Txs implements node.Node
-func (cp *Node) Txs(block *tmctypes.ResultBlock) ([]*types.Tx, error) {
-	txResponses := make([]*types.Tx, len(block.Block.Txs))
-	for i, tmTx := range block.Block.Txs {
-		txResponse, err := cp.Tx(fmt.Sprintf("%X", tmTx.Hash()))
-		if err != nil {
-			return nil, err
-		}
-
-		txResponses[i] = txResponse
-	}
-
-	return txResponses, nil
+func (cp *Node) Txs(block *tmctypes.ResultBlock, results *tmctypes.ResultBlockResults) ([]*types.Tx, error) {
+	return node.TxsFromBlock(cp.txConfig, block, results)
 }
 
 // TxSearch implements node.Node
@@ -219,8 +270,33 @@ func (cp *Node) SubscribeNewBlocks(subscriber string) (<-chan tmctypes.ResultEve
 	return cp.SubscribeEvents(subscriber, "tm.event = 'NewBlock'")
 }
 
-// GetContractInfo implements wasmsource.Source
+// GetCodeInfo implements node.Node
+// Since the info of a code never changes once it has been stored, the disk cache keeps a single
+// response for each code regardless of the height.
 func (cp *Node) GetCodeInfo(height int64, codeId uint64) (*wasmtypes.QueryCodeResponse, error) {
+	if cp.cache == nil {
+		return cp.getCodeInfo(height, codeId)
+	}
+
+	disk := diskAccessor{
+		read: func(s *store.Store) (interface{}, error) {
+			return s.ReadCodeInfo(codeId)
+		},
+		write: func(s *store.Store, value interface{}) error {
+			return s.WriteCodeInfo(codeId, value.(*wasmtypes.QueryCodeResponse))
+		},
+	}
+	value, err := cp.cache.get(cacheKindCodeInfo, fmt.Sprintf("%d/%d", height, codeId), disk, func() (interface{}, error) {
+		return cp.getCodeInfo(height, codeId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*wasmtypes.QueryCodeResponse), nil
+}
+
+// getCodeInfo queries the info of the code having the given id at the given height
+func (cp *Node) getCodeInfo(height int64, codeId uint64) (*wasmtypes.QueryCodeResponse, error) {
 	response, err := cp.wasmClient.Code(
 		GetHeightRequestContext(cp.ctx, height),
 		&wasmtypes.QueryCodeRequest{
@@ -249,6 +325,73 @@ func (cp *Node) GetContractInfo(height int64, contractAddr string) (*wasmtypes.Q
 	return response, nil
 }
 
+// SmartContractState implements node.Node
+func (cp *Node) SmartContractState(height int64, contractAddr string, query []byte) (*wasmtypes.QuerySmartContractStateResponse, error) {
+	response, err := cp.wasmClient.SmartContractState(
+		GetHeightRequestContext(cp.ctx, height),
+		&wasmtypes.QuerySmartContractStateRequest{
+			Address:   contractAddr,
+			QueryData: query,
+		},
+	)
+	if isContractQueryError(err) {
+		return nil, &node.ContractQueryError{ContractAddr: contractAddr, Reason: status.Convert(err).Message()}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while getting contract smart state: %s", err)
+	}
+
+	return response, nil
+}
+
+// contractQueryFailures contains the errors returned by the wasm module when a contract has been run, but failed
+// to answer a smart query
+var contractQueryFailures = []*sdkerrors.Error{wasmtypes.ErrQueryFailed, sdkerrors.ErrOutOfGas}
+
+// isContractQueryError tells whether the given error has been returned because the queried contract failed to
+// answer, rather than because the query could not be run.
+// The errors of the wasm module are returned with an unknown code, and are only identified by their description.
+func isContractQueryError(err error) bool {
+	if err == nil || status.Code(err) != codes.Unknown {
+		return false
+	}
+
+	message := status.Convert(err).Message()
+	for _, failure := range contractQueryFailures {
+		if strings.Contains(message, failure.Error()) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllContractState implements node.Node
+// All the pages are requested, and returned within a single response without pagination
+func (cp *Node) AllContractState(height int64, contractAddr string) (*wasmtypes.QueryAllContractStateResponse, error) {
+	var models []wasmtypes.Model
+	var nextKey []byte
+	for {
+		response, err := cp.wasmClient.AllContractState(
+			GetHeightRequestContext(cp.ctx, height),
+			&wasmtypes.QueryAllContractStateRequest{
+				Address:    contractAddr,
+				Pagination: &query.PageRequest{Key: nextKey},
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error while getting contract raw state: %s", err)
+		}
+
+		models = append(models, response.Models...)
+		if response.Pagination == nil || len(response.Pagination.NextKey) == 0 {
+			break
+		}
+		nextKey = response.Pagination.NextKey
+	}
+
+	return &wasmtypes.QueryAllContractStateResponse{Models: models}, nil
+}
+
 // Stop implements node.Node
 func (cp *Node) Stop() {
 	err := cp.client.Stop()
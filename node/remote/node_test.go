@@ -0,0 +1,27 @@
+package remote
+
+import (
+	"fmt"
+	"testing"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsContractQueryError(t *testing.T) {
+	// The errors of the wasm module are received with an unknown code and their description inside the message
+	queryFailed := sdkerrors.Wrap(wasmtypes.ErrQueryFailed, "Error parsing into type cw20::msg::QueryMsg")
+	require.True(t, isContractQueryError(status.Error(codes.Unknown, queryFailed.Error())))
+	require.True(t, isContractQueryError(status.Error(codes.Unknown, sdkerrors.ErrOutOfGas.Error())))
+
+	// Pruned heights, unreachable nodes and rate limits might be retried
+	pruned := sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, "failed to load state at height 100; version does not exist")
+	require.False(t, isContractQueryError(status.Error(codes.Unknown, pruned.Error())))
+	require.False(t, isContractQueryError(status.Error(codes.Unavailable, "connection refused")))
+	require.False(t, isContractQueryError(status.Error(codes.ResourceExhausted, "too many requests")))
+	require.False(t, isContractQueryError(fmt.Errorf("context deadline exceeded")))
+	require.False(t, isContractQueryError(nil))
+}
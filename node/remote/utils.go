@@ -44,6 +44,8 @@ func CreateGrpcConnection(cfg *GRPCConfig) (*grpc.ClientConn, error) {
 		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
 	}
 
+	grpcOpts = append(grpcOpts, grpc.WithUnaryInterceptor(newLimiter("grpc", cfg.RateLimit).unaryInterceptor))
+
 	address := HTTPProtocols.ReplaceAllString(cfg.Address, "")
 	return grpc.Dial(address, grpcOpts...)
 }
@@ -0,0 +1,370 @@
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/gogo/protobuf/proto"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+
+	"github.com/nuclearblock/archgregator/types"
+)
+
+const (
+	genesisFile      = "genesis.json"
+	blocksDir        = "blocks"
+	blockFile        = "block.json"
+	blockResultsFile = "block_results.json"
+	txsFile          = "txs.json"
+	codesDir         = "codes"
+	contractsDir     = "contracts"
+	contractInfoFile = "info.json"
+	allStateFile     = "all_state.json"
+
+	// compressedExt is the extension appended to the name of each file of a compressed store
+	compressedExt = ".gz"
+)
+
+// Store reads and writes the responses of a node stored as files inside a directory, which has the following layout:
+//
+//	genesis.json                       ResultGenesis, encoded using the Tendermint JSON
+//	blocks/<height>/block.json         ResultBlock, encoded using the Tendermint JSON
+//	blocks/<height>/block_results.json ResultBlockResults, encoded using the Tendermint JSON
+//	blocks/<height>/txs.json           GetTxsEventResponse containing the block txs, encoded using the codec JSON
+//	codes/<code_id>.json               QueryCodeResponse, encoded using the codec JSON
+//	blocks/<height>/contracts/<address>/info.json
+//	                                   QueryContractInfoResponse, encoded using the codec JSON
+//	blocks/<height>/contracts/<address>/all_state.json
+//	                                   QueryAllContractStateResponse, encoded using the codec JSON
+//	blocks/<height>/contracts/<address>/smart_<query_hash>.json
+//	                                   QuerySmartContractStateResponse of the query having the given
+//	                                   hex encoded SHA-256 hash, encoded using the codec JSON
+//
+// When the store is compressed, each file is gzipped and its name has the additional .gz extension.
+type Store struct {
+	dir      string
+	codec    codec.Codec
+	compress bool
+}
+
+// NewStore returns a new Store instance reading and writing the files inside the given directory
+func NewStore(dir string, codec codec.Codec) *Store {
+	return &Store{
+		dir:   dir,
+		codec: codec,
+	}
+}
+
+// NewCompressedStore returns a new Store instance reading and writing gzipped files inside the given directory
+func NewCompressedStore(dir string, codec codec.Codec) *Store {
+	return &Store{
+		dir:      dir,
+		codec:    codec,
+		compress: true,
+	}
+}
+
+// path returns the full path of the file identified by the given elements inside the store directory
+func (s *Store) path(elem ...string) string {
+	path := filepath.Join(append([]string{s.dir}, elem...)...)
+	if s.compress {
+		path += compressedExt
+	}
+	return path
+}
+
+// heightPath returns the path of the file having the given name among the files of the given height
+func (s *Store) heightPath(height int64, name string) string {
+	return s.path(blocksDir, strconv.FormatInt(height, 10), name)
+}
+
+// readFile returns the contents of the file at the given path, decompressing them if needed
+func (s *Store) readFile(path string) ([]byte, error) {
+	bz, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("fixture %s not found", path)
+	}
+	if err != nil || !s.compress {
+		return bz, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(bz))
+	if err != nil {
+		return nil, fmt.Errorf("error while decompressing fixture %s: %s", path, err)
+	}
+	defer reader.Close()
+
+	bz, err = ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error while decompressing fixture %s: %s", path, err)
+	}
+	return bz, nil
+}
+
+// writeFile writes the given contents to the file at the given path, compressing them if needed and
+// creating its directory if it does not exist yet.
+// The contents are written to a temporary file that is then renamed, so that readers and concurrent writers
+// of the same file never see a partially written one.
+func (s *Store) writeFile(path string, bz []byte) error {
+	if s.compress {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		_, err := writer.Write(bz)
+		if err == nil {
+			err = writer.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("error while compressing fixture %s: %s", path, err)
+		}
+		bz = buf.Bytes()
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("error while creating fixture directory: %s", err)
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error while writing fixture %s: %s", path, err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(bz)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("error while writing fixture %s: %s", path, err)
+	}
+	return nil
+}
+
+// readTm reads the file at the given path decoding it using the Tendermint JSON into ptr
+func (s *Store) readTm(path string, ptr interface{}) error {
+	bz, err := s.readFile(path)
+	if err != nil {
+		return err
+	}
+
+	err = tmjson.Unmarshal(bz, ptr)
+	if err != nil {
+		return fmt.Errorf("error while decoding fixture %s: %s", path, err)
+	}
+	return nil
+}
+
+// writeTm writes the given value to the file at the given path using the Tendermint JSON
+func (s *Store) writeTm(path string, value interface{}) error {
+	bz, err := tmjson.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error while encoding fixture %s: %s", path, err)
+	}
+	return s.writeFile(path, bz)
+}
+
+// readProto reads the file at the given path decoding it using the codec JSON into msg
+func (s *Store) readProto(path string, msg proto.Message) error {
+	bz, err := s.readFile(path)
+	if err != nil {
+		return err
+	}
+
+	err = s.codec.UnmarshalJSON(bz, msg)
+	if err != nil {
+		return fmt.Errorf("error while decoding fixture %s: %s", path, err)
+	}
+	return nil
+}
+
+// writeProto writes the given message to the file at the given path using the codec JSON
+func (s *Store) writeProto(path string, msg proto.Message) error {
+	bz, err := s.codec.MarshalJSON(msg)
+	if err != nil {
+		return fmt.Errorf("error while encoding fixture %s: %s", path, err)
+	}
+	return s.writeFile(path, bz)
+}
+
+// Heights returns all the heights for which a block has been stored, in ascending order
+func (s *Store) Heights() ([]int64, error) {
+	entries, err := ioutil.ReadDir(filepath.Join(s.dir, blocksDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while reading fixture blocks: %s", err)
+	}
+
+	var heights []int64
+	for _, entry := range entries {
+		height, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		heights = append(heights, height)
+	}
+
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights, nil
+}
+
+// ReadGenesis returns the stored genesis
+func (s *Store) ReadGenesis() (*tmctypes.ResultGenesis, error) {
+	var genesis tmctypes.ResultGenesis
+	err := s.readTm(s.path(genesisFile), &genesis)
+	return &genesis, err
+}
+
+// WriteGenesis stores the given genesis
+func (s *Store) WriteGenesis(genesis *tmctypes.ResultGenesis) error {
+	return s.writeTm(s.path(genesisFile), genesis)
+}
+
+// ReadBlock returns the stored block having the given height
+func (s *Store) ReadBlock(height int64) (*tmctypes.ResultBlock, error) {
+	var block tmctypes.ResultBlock
+	err := s.readTm(s.heightPath(height, blockFile), &block)
+	return &block, err
+}
+
+// WriteBlock stores the given block
+func (s *Store) WriteBlock(block *tmctypes.ResultBlock) error {
+	return s.writeTm(s.heightPath(block.Block.Height, blockFile), block)
+}
+
+// ReadBlockResults returns the stored results of the block having the given height
+func (s *Store) ReadBlockResults(height int64) (*tmctypes.ResultBlockResults, error) {
+	var results tmctypes.ResultBlockResults
+	err := s.readTm(s.heightPath(height, blockResultsFile), &results)
+	return &results, err
+}
+
+// WriteBlockResults stores the given block results
+func (s *Store) WriteBlockResults(results *tmctypes.ResultBlockResults) error {
+	return s.writeTm(s.heightPath(results.Height, blockResultsFile), results)
+}
+
+// ReadTxs returns the stored transactions of the block having the given height
+func (s *Store) ReadTxs(height int64) ([]*types.Tx, error) {
+	var res tx.GetTxsEventResponse
+	err := s.readProto(s.heightPath(height, txsFile), &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Txs) != len(res.TxResponses) {
+		return nil, fmt.Errorf("invalid txs fixture at height %d: %d txs and %d responses",
+			height, len(res.Txs), len(res.TxResponses))
+	}
+
+	txs := make([]*types.Tx, len(res.Txs))
+	for i := range res.Txs {
+		txs[i], err = types.NewTx(res.TxResponses[i], res.Txs[i])
+		if err != nil {
+			return nil, fmt.Errorf("error converting transaction: %s", err)
+		}
+	}
+	return txs, nil
+}
+
+// WriteTxs stores the given transactions of the block having the given height
+func (s *Store) WriteTxs(height int64, txs []*types.Tx) error {
+	var res tx.GetTxsEventResponse
+	for _, t := range txs {
+		res.Txs = append(res.Txs, t.Tx)
+		res.TxResponses = append(res.TxResponses, t.TxResponse)
+	}
+	return s.writeProto(s.heightPath(height, txsFile), &res)
+}
+
+// ReadCodeInfo returns the stored info of the code having the given id
+func (s *Store) ReadCodeInfo(codeID uint64) (*wasmtypes.QueryCodeResponse, error) {
+	var res wasmtypes.QueryCodeResponse
+	err := s.readProto(s.path(codesDir, fmt.Sprintf("%d.json", codeID)), &res)
+	return &res, err
+}
+
+// WriteCodeInfo stores the given info of the code having the given id
+func (s *Store) WriteCodeInfo(codeID uint64, res *wasmtypes.QueryCodeResponse) error {
+	return s.writeProto(s.path(codesDir, fmt.Sprintf("%d.json", codeID)), res)
+}
+
+// ReadContractInfo returns the stored info of the contract having the given address at the given height
+func (s *Store) ReadContractInfo(height int64, address string) (*wasmtypes.QueryContractInfoResponse, error) {
+	var res wasmtypes.QueryContractInfoResponse
+	err := s.readProto(s.contractStatePath(height, address, contractInfoFile), &res)
+	return &res, err
+}
+
+// WriteContractInfo stores the given info of the contract having the given address at the given height.
+// The info is stored for each height since the admin of a contract can change.
+func (s *Store) WriteContractInfo(height int64, address string, res *wasmtypes.QueryContractInfoResponse) error {
+	return s.writeProto(s.contractStatePath(height, address, contractInfoFile), res)
+}
+
+// contractStatePath returns the path of the file having the given name among the state files of the contract
+// having the given address at the given height
+func (s *Store) contractStatePath(height int64, address string, name string) string {
+	return s.path(blocksDir, strconv.FormatInt(height, 10), contractsDir, address, name)
+}
+
+// smartStateFile returns the name of the file containing the response to the given smart query
+func smartStateFile(query []byte) string {
+	hash := sha256.Sum256(query)
+	return fmt.Sprintf("smart_%s.json", hex.EncodeToString(hash[:]))
+}
+
+// HasSmartContractState tells whether a response to the given smart query of the contract having the given address
+// at the given height has been stored
+func (s *Store) HasSmartContractState(height int64, address string, query []byte) bool {
+	_, err := os.Stat(s.contractStatePath(height, address, smartStateFile(query)))
+	return err == nil
+}
+
+// ReadSmartContractState returns the stored response to the given smart query of the contract having the given
+// address at the given height
+func (s *Store) ReadSmartContractState(height int64, address string, query []byte) (*wasmtypes.QuerySmartContractStateResponse, error) {
+	var res wasmtypes.QuerySmartContractStateResponse
+	err := s.readProto(s.contractStatePath(height, address, smartStateFile(query)), &res)
+	return &res, err
+}
+
+// WriteSmartContractState stores the given response to the given smart query of the contract having the given
+// address at the given height
+func (s *Store) WriteSmartContractState(
+	height int64, address string, query []byte, res *wasmtypes.QuerySmartContractStateResponse,
+) error {
+	return s.writeProto(s.contractStatePath(height, address, smartStateFile(query)), res)
+}
+
+// ReadAllContractState returns the stored raw state of the contract having the given address at the given height
+func (s *Store) ReadAllContractState(height int64, address string) (*wasmtypes.QueryAllContractStateResponse, error) {
+	var res wasmtypes.QueryAllContractStateResponse
+	err := s.readProto(s.contractStatePath(height, address, allStateFile), &res)
+	return &res, err
+}
+
+// WriteAllContractState stores the given raw state of the contract having the given address at the given height
+func (s *Store) WriteAllContractState(height int64, address string, res *wasmtypes.QueryAllContractStateResponse) error {
+	return s.writeProto(s.contractStatePath(height, address, allStateFile), res)
+}
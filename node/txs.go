@@ -0,0 +1,74 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// intoAny is implemented by the transactions returned by the TxConfig decoder
+type intoAny interface {
+	AsAny() *codectypes.Any
+}
+
+// TxFromResult decodes the transaction contained inside the given result, returning the same
+// response that would be returned by the tx service.
+// The timestamp of the response is set to the given block time.
+func TxFromResult(txConfig client.TxConfig, resTx *tmctypes.ResultTx, blockTime time.Time) (*types.Tx, error) {
+	txb, err := txConfig.TxDecoder()(resTx.Tx)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding transaction: %s", err)
+	}
+
+	p, ok := txb.(intoAny)
+	if !ok {
+		return nil, fmt.Errorf("expecting a type implementing intoAny, got: %T", txb)
+	}
+
+	any := p.AsAny()
+	txResponse := sdk.NewResponseResultTx(resTx, any, blockTime.Format(time.RFC3339))
+
+	protoTx, ok := any.GetCachedValue().(*tx.Tx)
+	if !ok {
+		return nil, fmt.Errorf("expected %T, got %T", tx.Tx{}, any.GetCachedValue())
+	}
+
+	return types.NewTx(txResponse, protoTx)
+}
+
+// TxsFromBlock decodes all the transactions contained inside the given block combining them with
+// the given block results, so that no additional query is needed to get them
+func TxsFromBlock(
+	txConfig client.TxConfig, block *tmctypes.ResultBlock, results *tmctypes.ResultBlockResults,
+) ([]*types.Tx, error) {
+	if len(block.Block.Txs) != len(results.TxsResults) {
+		return nil, fmt.Errorf("block %d contains %d txs but %d results have been found",
+			block.Block.Height, len(block.Block.Txs), len(results.TxsResults))
+	}
+
+	txs := make([]*types.Tx, len(block.Block.Txs))
+	for i, tmTx := range block.Block.Txs {
+		resTx := &tmctypes.ResultTx{
+			Hash:     tmTx.Hash(),
+			Height:   block.Block.Height,
+			Index:    uint32(i),
+			TxResult: *results.TxsResults[i],
+			Tx:       tmTx,
+		}
+
+		convTx, err := TxFromResult(txConfig, resTx, block.Block.Time)
+		if err != nil {
+			return nil, fmt.Errorf("error converting transaction %X: %s", tmTx.Hash(), err)
+		}
+		txs[i] = convTx
+	}
+
+	return txs, nil
+}
@@ -0,0 +1,83 @@
+package node_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	"github.com/nuclearblock/archgregator/node"
+	"github.com/nuclearblock/archgregator/node/store"
+	"github.com/nuclearblock/archgregator/types/config"
+)
+
+// recordedStore returns the store containing the blocks recorded inside the parser test data
+func recordedStore() *store.Store {
+	encodingConfig := config.MakeEncodingConfig()
+	return store.NewStore("../parser/testdata", encodingConfig.Marshaler)
+}
+
+// recordedBlock returns the block recorded inside the parser test data along with its results
+func recordedBlock(t *testing.T) (*tmctypes.ResultBlock, *tmctypes.ResultBlockResults) {
+	recorded := recordedStore()
+
+	block, err := recorded.ReadBlock(100)
+	require.NoError(t, err)
+
+	results, err := recorded.ReadBlockResults(100)
+	require.NoError(t, err)
+
+	return block, results
+}
+
+func TestTxsFromBlock(t *testing.T) {
+	block, results := recordedBlock(t)
+	results.TxsResults[0].Events = []abci.Event{{
+		Type: "wasm",
+		Attributes: []abci.EventAttribute{
+			{Key: []byte("_contract_address"), Value: []byte("archway1contract")},
+		},
+	}}
+
+	encodingConfig := config.MakeEncodingConfig()
+	txs, err := node.TxsFromBlock(encodingConfig.TxConfig, block, results)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+
+	// The decoded tx matches the one returned by the tx service when the block was recorded
+	recorded, err := recordedStore().ReadTxs(100)
+	require.NoError(t, err)
+	require.Len(t, recorded, 1)
+
+	tx := txs[0]
+	require.Equal(t, recorded[0].TxHash, tx.TxHash)
+	require.Equal(t, int64(100), tx.Height)
+	require.Equal(t, int64(400000), tx.GasWanted)
+	require.Equal(t, int64(312345), tx.GasUsed)
+	require.Equal(t, "2022-04-12T10:30:00Z", tx.Timestamp)
+	require.Len(t, tx.Body.Messages, len(recorded[0].Body.Messages))
+
+	// The events of the block results are kept
+	require.Len(t, tx.Events, 1)
+	require.Equal(t, "wasm", tx.Events[0].Type)
+	require.Equal(t, "archway1contract", string(tx.Events[0].Attributes[0].Value))
+}
+
+func TestTxsFromBlockMissingResults(t *testing.T) {
+	block, results := recordedBlock(t)
+	results.TxsResults = nil
+
+	encodingConfig := config.MakeEncodingConfig()
+	_, err := node.TxsFromBlock(encodingConfig.TxConfig, block, results)
+	require.EqualError(t, err, "block 100 contains 1 txs but 0 results have been found")
+}
+
+func TestTxFromResultInvalidTx(t *testing.T) {
+	encodingConfig := config.MakeEncodingConfig()
+	block, _ := recordedBlock(t)
+
+	_, err := node.TxFromResult(encodingConfig.TxConfig, &tmctypes.ResultTx{Tx: []byte("not a tx")}, block.Block.Time)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error while decoding transaction")
+}
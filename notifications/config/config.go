@@ -0,0 +1,91 @@
+package config
+
+import (
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// RuleTypeContractInstantiated is triggered when a contract is instantiated from a watched code
+	RuleTypeContractInstantiated = "contract_instantiated"
+
+	// RuleTypeRewardAddressChanged is triggered when the gastracker metadata of a watched contract
+	// changes its reward address
+	RuleTypeRewardAddressChanged = "reward_address_changed"
+
+	// RuleTypeRewardsThreshold is triggered when the rewards of a watched contract for a single block
+	// are greater than or equal to a given amount
+	RuleTypeRewardsThreshold = "rewards_threshold"
+)
+
+// Config contains the configuration of the webhook notifications
+type Config struct {
+	// Secret is used to sign the body of each webhook request using HMAC-SHA256
+	Secret        string        `yaml:"secret"`
+	Timeout       time.Duration `yaml:"timeout"`
+	MaxRetries    int           `yaml:"max_retries"`
+	RetryInterval time.Duration `yaml:"retry_interval"`
+	Workers       int           `yaml:"workers"`
+	BufferSize    int           `yaml:"buffer_size"`
+	Rules         []RuleConfig  `yaml:"rules"`
+}
+
+// NewNotificationsConfig allows to build a new Config instance
+func NewNotificationsConfig(
+	secret string, timeout time.Duration, maxRetries int, retryInterval time.Duration,
+	workers int, bufferSize int, rules []RuleConfig,
+) *Config {
+	return &Config{
+		Secret:        secret,
+		Timeout:       timeout,
+		MaxRetries:    maxRetries,
+		RetryInterval: retryInterval,
+		Workers:       workers,
+		BufferSize:    bufferSize,
+		Rules:         rules,
+	}
+}
+
+// DefaultNotificationsConfig returns the default instance of Config
+func DefaultNotificationsConfig() *Config {
+	return NewNotificationsConfig("", 10*time.Second, 5, 5*time.Second, 4, 1024, nil)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, using the default values for all the fields that are not set
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	type rawConfig Config
+	cfg := rawConfig(*DefaultNotificationsConfig())
+	err := value.Decode(&cfg)
+	if err != nil {
+		return err
+	}
+
+	*c = Config(cfg)
+	return nil
+}
+
+// RuleConfig contains the configuration of a single notification rule.
+// Empty lists match everything.
+type RuleConfig struct {
+	Name      string   `yaml:"name"`
+	Type      string   `yaml:"type"`
+	Webhook   string   `yaml:"webhook"`
+	CodeIDs   []uint64 `yaml:"code_ids,omitempty"`
+	Contracts []string `yaml:"contracts,omitempty"`
+	MinAmount float64  `yaml:"min_amount,omitempty"`
+}
+
+// NewRuleConfig allows to build a new RuleConfig instance
+func NewRuleConfig(
+	name, ruleType, webhook string, codeIDs []uint64, contracts []string, minAmount float64,
+) RuleConfig {
+	return RuleConfig{
+		Name:      name,
+		Type:      ruleType,
+		Webhook:   webhook,
+		CodeIDs:   codeIDs,
+		Contracts: contracts,
+		MinAmount: minAmount,
+	}
+}
@@ -0,0 +1,348 @@
+package notifications
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nuclearblock/archgregator/broker"
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/logging"
+	notificationsconfig "github.com/nuclearblock/archgregator/notifications/config"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+const (
+	// DeliveryHeader contains the id of the delivery, which is the same across retries
+	DeliveryHeader = "X-Archgregator-Delivery"
+
+	// TimestampHeader contains the UNIX timestamp at which the request has been signed
+	TimestampHeader = "X-Archgregator-Timestamp"
+
+	// SignatureHeader contains the hex encoded HMAC-SHA256 of "<timestamp>.<body>", prefixed with "sha256="
+	SignatureHeader = "X-Archgregator-Signature"
+)
+
+// delivery represents a notification that should be sent to a webhook
+type delivery struct {
+	id      string
+	rule    string
+	webhook string
+	chainID string
+	height  int64
+	payload []byte
+}
+
+// Engine evaluates the configured rules against the records published to a broker,
+// and delivers the resulting notifications to the rules webhooks
+type Engine struct {
+	cfg    notificationsconfig.Config
+	rules  []*rule
+	broker *broker.Broker
+	db     database.Database
+	reader database.Reader
+	client *http.Client
+
+	sub        *broker.Subscription
+	deliveries chan delivery
+	done       chan struct{}
+	wg         sync.WaitGroup
+
+	logger logging.Logger
+}
+
+// NewEngine returns a new Engine instance that evaluates the events published to the given broker.
+// The deliveries are logged inside db, while reader is used by the rules that need to read the stored data,
+// and can be nil if no such rule is configured.
+func NewEngine(
+	cfg *notificationsconfig.Config, broker *broker.Broker, db database.Database, reader database.Reader, logger logging.Logger,
+) (*Engine, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("notifications config cannot be null")
+	}
+
+	rules := make([]*rule, len(cfg.Rules))
+	for i, ruleCfg := range cfg.Rules {
+		r, err := newRule(ruleCfg, reader)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification rule: %s", err)
+		}
+		rules[i] = r
+	}
+
+	engineCfg := withDefaults(*cfg)
+	return &Engine{
+		cfg:        engineCfg,
+		rules:      rules,
+		broker:     broker,
+		db:         db,
+		reader:     reader,
+		client:     &http.Client{Timeout: engineCfg.Timeout},
+		deliveries: make(chan delivery, engineCfg.BufferSize),
+		done:       make(chan struct{}),
+		logger:     logger,
+	}, nil
+}
+
+// withDefaults returns the given config replacing all the unset values with the default ones
+func withDefaults(cfg notificationsconfig.Config) notificationsconfig.Config {
+	defaultCfg := notificationsconfig.DefaultNotificationsConfig()
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultCfg.Timeout
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = defaultCfg.MaxRetries
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = defaultCfg.RetryInterval
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultCfg.Workers
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultCfg.BufferSize
+	}
+	return cfg
+}
+
+// Start starts evaluating the published events and delivering the notifications in background
+func (e *Engine) Start() {
+	filter := broker.NewFilter(nil, []string{
+		broker.EventTypeWasmContract,
+		broker.EventTypeContractMetadata,
+		broker.EventTypeContractRewardCalculation,
+	}, nil)
+	e.sub = e.broker.Subscribe(filter, e.cfg.BufferSize)
+
+	e.wg.Add(1)
+	go e.evaluateEvents()
+
+	for i := 0; i < e.cfg.Workers; i++ {
+		e.wg.Add(1)
+		go e.deliverNotifications()
+	}
+}
+
+// Stop stops the engine, abandoning the deliveries that are still pending
+func (e *Engine) Stop() {
+	close(e.done)
+	e.sub.Close()
+	e.wg.Wait()
+}
+
+// evaluateEvents evaluates all the rules against each published event,
+// and enqueues the delivery of the resulting notifications.
+// The evaluation never waits for the delivery workers, so that the subscription keeps being drained and the broker
+// does not drop any event: the deliveries that cannot be handed to the workers are kept inside a queue holding up
+// to BufferSize of them, which drops the oldest delivery once full.
+func (e *Engine) evaluateEvents() {
+	defer e.wg.Done()
+
+	var queue []delivery
+	events := e.sub.Events()
+	for {
+		// Only try to hand a delivery to the workers when there is one queued
+		var deliveries chan delivery
+		var next delivery
+		if len(queue) > 0 {
+			deliveries, next = e.deliveries, queue[0]
+		}
+
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			for _, d := range e.evaluate(event) {
+				queue = e.enqueue(queue, d)
+			}
+
+		case deliveries <- next:
+			queue = queue[1:]
+
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// evaluate evaluates all the rules against the given event, returning the deliveries of the resulting notifications
+func (e *Engine) evaluate(event broker.Event) []delivery {
+	var deliveries []delivery
+	for _, r := range e.rules {
+		notification, err := r.evaluate(event, database.ReaderForChain(e.reader, event.ChainID))
+		if err != nil {
+			e.logger.Error("error while evaluating notification rule", "rule", r.cfg.Name, "height", event.Height, "err", err)
+			continue
+		}
+
+		if notification == nil {
+			continue
+		}
+
+		d, err := newDelivery(r, notification)
+		if err != nil {
+			e.logger.Error("error while building notification", "rule", r.cfg.Name, "height", event.Height, "err", err)
+			continue
+		}
+
+		deliveries = append(deliveries, d)
+	}
+	return deliveries
+}
+
+// enqueue appends the given delivery to the given queue, dropping the oldest queued delivery if the queue is full
+func (e *Engine) enqueue(queue []delivery, d delivery) []delivery {
+	if len(queue) >= e.cfg.BufferSize {
+		dropped := queue[0]
+		e.logger.Error("notification queue is full, dropping delivery",
+			"rule", dropped.rule, "delivery", dropped.id, "height", dropped.height)
+		queue = queue[1:]
+	}
+	return append(queue, d)
+}
+
+// newDelivery builds the delivery of the given notification to the webhook of the given rule
+func newDelivery(r *rule, notification *Notification) (delivery, error) {
+	id, err := newDeliveryID()
+	if err != nil {
+		return delivery{}, err
+	}
+	notification.DeliveryID = id
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return delivery{}, fmt.Errorf("error while serializing notification: %s", err)
+	}
+
+	return delivery{
+		id:      id,
+		rule:    r.cfg.Name,
+		webhook: r.cfg.Webhook,
+		chainID: notification.ChainID,
+		height:  notification.Height,
+		payload: payload,
+	}, nil
+}
+
+// newDeliveryID returns a new random delivery id
+func newDeliveryID() (string, error) {
+	bz := make([]byte, 16)
+	_, err := rand.Read(bz)
+	if err != nil {
+		return "", fmt.Errorf("error while generating delivery id: %s", err)
+	}
+	return hex.EncodeToString(bz), nil
+}
+
+// deliverNotifications sends the enqueued deliveries until the engine is stopped
+func (e *Engine) deliverNotifications() {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case d := <-e.deliveries:
+			e.deliver(d)
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// deliver sends the given delivery, retrying with an exponential backoff up to the configured number of times.
+// Each attempt is logged inside the database.
+func (e *Engine) deliver(d delivery) {
+	for attempt := 1; attempt <= e.cfg.MaxRetries+1; attempt++ {
+		statusCode, err := e.send(d)
+
+		deliveryErr := ""
+		if err != nil {
+			deliveryErr = err.Error()
+		}
+
+		logErr := e.db.ForChain(d.chainID).SaveNotificationDelivery(types.NewNotificationDelivery(
+			d.id, d.rule, d.webhook, d.payload, d.height, attempt, statusCode, deliveryErr, err == nil, time.Now().UTC(),
+		))
+		if logErr != nil {
+			e.logger.Error("error while logging notification delivery", "delivery", d.id, "err", logErr)
+		}
+
+		if err == nil {
+			e.logger.Debug("notification delivered", "rule", d.rule, "delivery", d.id, "attempt", attempt)
+			return
+		}
+
+		if !shouldRetry(statusCode) {
+			break
+		}
+
+		e.logger.Debug("notification delivery failed", "rule", d.rule, "delivery", d.id, "attempt", attempt, "err", err)
+
+		backoff := e.cfg.RetryInterval * time.Duration(1<<uint(attempt-1))
+		select {
+		case <-time.After(backoff):
+		case <-e.done:
+			return
+		}
+	}
+
+	e.logger.Error("error while delivering notification", "rule", d.rule, "delivery", d.id, "webhook", d.webhook)
+}
+
+// shouldRetry tells whether a delivery that failed with the given status code should be retried.
+// A zero status code means that no response has been received.
+func shouldRetry(statusCode int) bool {
+	return statusCode == 0 ||
+		statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode >= http.StatusInternalServerError
+}
+
+// send performs a single delivery attempt, returning the status code of the response if any
+func (e *Engine) send(d delivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, d.webhook, bytes.NewReader(d.payload))
+	if err != nil {
+		return 0, fmt.Errorf("error while creating webhook request: %s", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, d.id)
+	req.Header.Set(TimestampHeader, timestamp)
+	if e.cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(e.cfg.Secret, timestamp, d.payload))
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error while sending webhook request: %s", err)
+	}
+	defer res.Body.Close()
+
+	// Drain the body so that the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("webhook replied with status %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of the given timestamp and body using the given secret
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,209 @@
+package notifications
+
+import (
+	"fmt"
+
+	"github.com/nuclearblock/archgregator/broker"
+	"github.com/nuclearblock/archgregator/database"
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+	notificationsconfig "github.com/nuclearblock/archgregator/notifications/config"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// Notification represents the payload sent to the webhook of a rule
+type Notification struct {
+	DeliveryID      string      `json:"delivery_id"`
+	ChainID         string      `json:"chain_id,omitempty"`
+	Rule            string      `json:"rule"`
+	Type            string      `json:"type"`
+	Height          int64       `json:"height"`
+	ContractAddress string      `json:"contract_address"`
+	Details         interface{} `json:"details,omitempty"`
+	Record          interface{} `json:"record"`
+}
+
+// rewardAddressChangedDetails contains the details of a RuleTypeRewardAddressChanged notification
+type rewardAddressChangedDetails struct {
+	PreviousRewardAddress string `json:"previous_reward_address"`
+	RewardAddress         string `json:"reward_address"`
+}
+
+// rewardsThresholdDetails contains the details of a RuleTypeRewardsThreshold notification
+type rewardsThresholdDetails struct {
+	TotalRewards float64 `json:"total_rewards"`
+	MinAmount    float64 `json:"min_amount"`
+}
+
+// rule represents a single notification rule ready to be evaluated
+type rule struct {
+	cfg       notificationsconfig.RuleConfig
+	codeIDs   map[uint64]bool
+	contracts map[string]bool
+
+	// rewardAddresses contains the latest reward address seen for each contract, keyed by chain id and address.
+	// It is only accessed by the engine evaluation goroutine.
+	rewardAddresses map[string]seenRewardAddress
+}
+
+// seenRewardAddress represents the reward address set by a contract metadata at a given height
+type seenRewardAddress struct {
+	address string
+	height  int64
+}
+
+// newRule builds a new rule from the given configuration, returning an error if it is not valid
+func newRule(cfg notificationsconfig.RuleConfig, reader database.Reader) (*rule, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("rule name cannot be empty")
+	}
+
+	if cfg.Webhook == "" {
+		return nil, fmt.Errorf("webhook of rule %s cannot be empty", cfg.Name)
+	}
+
+	switch cfg.Type {
+	case notificationsconfig.RuleTypeContractInstantiated:
+	case notificationsconfig.RuleTypeRewardAddressChanged:
+		if reader == nil {
+			return nil, fmt.Errorf("rule %s requires a database that supports reading the stored data", cfg.Name)
+		}
+	case notificationsconfig.RuleTypeRewardsThreshold:
+		if cfg.MinAmount <= 0 {
+			return nil, fmt.Errorf("min amount of rule %s must be greater than zero", cfg.Name)
+		}
+	default:
+		return nil, fmt.Errorf("invalid type %s of rule %s", cfg.Type, cfg.Name)
+	}
+
+	r := &rule{
+		cfg:             cfg,
+		codeIDs:         map[uint64]bool{},
+		contracts:       map[string]bool{},
+		rewardAddresses: map[string]seenRewardAddress{},
+	}
+	for _, codeID := range cfg.CodeIDs {
+		r.codeIDs[codeID] = true
+	}
+	for _, contract := range cfg.Contracts {
+		r.contracts[contract] = true
+	}
+
+	return r, nil
+}
+
+// watchesCode tells whether the rule is interested in the code having the given id
+func (r *rule) watchesCode(codeID uint64) bool {
+	return len(r.codeIDs) == 0 || r.codeIDs[codeID]
+}
+
+// watchesContract tells whether the rule is interested in the contract having the given address
+func (r *rule) watchesContract(address string) bool {
+	return len(r.contracts) == 0 || r.contracts[address]
+}
+
+// evaluate returns the notification that should be sent for the given event,
+// or nil if the event does not trigger the rule
+func (r *rule) evaluate(event broker.Event, reader database.Reader) (*Notification, error) {
+	switch r.cfg.Type {
+	case notificationsconfig.RuleTypeContractInstantiated:
+		contract, ok := event.Data.(types.WasmContract)
+		if !ok || !r.watchesCode(contract.CodeID) {
+			return nil, nil
+		}
+		return r.notification(event, nil), nil
+
+	case notificationsconfig.RuleTypeRewardAddressChanged:
+		metadata, ok := event.Data.(types.GasTrackerContractMetadata)
+		if !ok || !r.watchesContract(metadata.ContractAddress) {
+			return nil, nil
+		}
+
+		previous, err := r.previousRewardAddress(reader, event.ChainID, metadata)
+		if err != nil {
+			return nil, err
+		}
+
+		if previous == "" || previous == metadata.Metadata.RewardAddress {
+			return nil, nil
+		}
+
+		return r.notification(event, rewardAddressChangedDetails{
+			PreviousRewardAddress: previous,
+			RewardAddress:         metadata.Metadata.RewardAddress,
+		}), nil
+
+	case notificationsconfig.RuleTypeRewardsThreshold:
+		reward, ok := event.Data.(types.ContractRewardCalculation)
+		if !ok || !r.watchesContract(reward.ContractAddress) {
+			return nil, nil
+		}
+
+		total, err := reward.ContractRewards.Amount.Add(reward.InflationRewards.Amount).Float64()
+		if err != nil {
+			return nil, fmt.Errorf("error while converting rewards amount: %s", err)
+		}
+
+		if total < r.cfg.MinAmount {
+			return nil, nil
+		}
+
+		return r.notification(event, rewardsThresholdDetails{
+			TotalRewards: total,
+			MinAmount:    r.cfg.MinAmount,
+		}), nil
+	}
+
+	return nil, nil
+}
+
+// notification builds the notification of this rule for the given event
+func (r *rule) notification(event broker.Event, details interface{}) *Notification {
+	return &Notification{
+		ChainID:         event.ChainID,
+		Rule:            r.cfg.Name,
+		Type:            r.cfg.Type,
+		Height:          event.Height,
+		ContractAddress: event.ContractAddress,
+		Details:         details,
+		Record:          event.Data,
+	}
+}
+
+// previousRewardAddress returns the reward address that was set for the contract
+// before the given metadata, or an empty string if no metadata was set before.
+// Since the blocks can be written out of order by different workers, the stored metadata might not contain the
+// one preceding the given metadata yet: the latest metadata seen by this rule is then used when it is more recent
+// than the stored one. A change is still missed if the preceding metadata is neither stored nor seen yet,
+// which can only happen while some earlier heights are still being parsed.
+func (r *rule) previousRewardAddress(
+	reader database.Reader, chainID string, metadata types.GasTrackerContractMetadata,
+) (string, error) {
+	key := chainID + "/" + metadata.ContractAddress
+	seen, found := r.rewardAddresses[key]
+	if !found || seen.height < metadata.Height {
+		r.rewardAddresses[key] = seenRewardAddress{address: metadata.Metadata.RewardAddress, height: metadata.Height}
+	}
+
+	if metadata.Height <= 1 {
+		return "", nil
+	}
+
+	filter := dbtypes.ContractMetadataFilter{ContractAddress: metadata.ContractAddress}
+	filter.ToHeight = metadata.Height - 1
+
+	rows, err := reader.GetContractMetadata(filter, dbtypes.NewPagination(1, 0))
+	if err != nil {
+		return "", fmt.Errorf("error while getting previous contract metadata: %s", err)
+	}
+
+	var previous seenRewardAddress
+	if len(rows) > 0 {
+		previous = seenRewardAddress{address: rows[0].RewardAddress, height: rows[0].Height}
+	}
+
+	if found && seen.height < metadata.Height && seen.height > previous.height {
+		previous = seen
+	}
+
+	return previous.address, nil
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/nuclearblock/archgregator/database"
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// CheckChainInfo makes sure that the node of the given context serves the same chain the database has been built from,
+// comparing the chain id and the genesis hash of the node with the stored ones.
+// If the database does not contain the identity of the chain yet, the one of the node is stored instead.
+// When the chain id has been read from the node, the database must not contain the data of any other chain, since
+// pointing it to another chain by mistake would otherwise store that chain alongside the existing one.
+func CheckChainInfo(ctx *Context) error {
+	genesis, err := ctx.Node.Genesis()
+	if err != nil {
+		return fmt.Errorf("error while getting genesis from node: %s", err)
+	}
+
+	nodeInfo, err := types.NewChainInfoFromGenesis(genesis.Genesis)
+	if err != nil {
+		return err
+	}
+
+	if nodeInfo.ChainID != ctx.ChainID {
+		return fmt.Errorf("the node serves chain %s, while chain %s is configured", nodeInfo.ChainID, ctx.ChainID)
+	}
+
+	if ctx.ChainIDFromNode {
+		err = checkSingleChain(ctx.Database.ForChain(""), nodeInfo.ChainID)
+		if err != nil {
+			return err
+		}
+	}
+
+	storedInfo, err := ctx.Database.GetChainInfo()
+	if err != nil {
+		return err
+	}
+
+	if storedInfo == nil {
+		ctx.Logger.Info("storing chain identity", "chain_id", nodeInfo.ChainID, "genesis_hash", nodeInfo.GenesisHash)
+		return ctx.Database.SaveChainInfo(nodeInfo)
+	}
+
+	if !storedInfo.Equal(nodeInfo) {
+		return fmt.Errorf("the node serves chain %s with genesis hash %s, while the database contains chain %s with genesis hash %s",
+			nodeInfo.ChainID, nodeInfo.GenesisHash, storedInfo.ChainID, storedInfo.GenesisHash)
+	}
+
+	return nil
+}
+
+// checkSingleChain makes sure that the given database, which must not be scoped to any chain, does not contain the
+// identity nor the blocks of any chain other than the one having the given id
+func checkSingleChain(db database.Database, chainID string) error {
+	storedInfo, err := db.GetChainInfo()
+	if err != nil {
+		return err
+	}
+
+	if storedInfo != nil && storedInfo.ChainID != chainID {
+		return fmt.Errorf("the node serves chain %s, while the database contains chain %s: set the chain_id of "+
+			"the indexed chain to store it alongside the existing one", chainID, storedInfo.ChainID)
+	}
+
+	reader, ok := db.(database.Reader)
+	if !ok {
+		return nil
+	}
+
+	// The rows stored before the chain ids were introduced have no chain id, and might belong to any chain
+	blocks, err := reader.GetBlocks(dbtypes.BlocksFilter{}, dbtypes.NewPagination(1, 0))
+	if err != nil {
+		return fmt.Errorf("error while getting the stored blocks: %s", err)
+	}
+	if len(blocks) > 0 && blocks[0].ChainID != "" && blocks[0].ChainID != chainID {
+		return fmt.Errorf("the node serves chain %s, while the database contains the blocks of chain %s: set the "+
+			"chain_id of the indexed chain to store it alongside the existing one", chainID, blocks[0].ChainID)
+	}
+
+	return nil
+}
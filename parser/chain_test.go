@@ -0,0 +1,69 @@
+package parser_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/nuclearblock/archgregator/database/memory"
+	"github.com/nuclearblock/archgregator/logging"
+	"github.com/nuclearblock/archgregator/node"
+	"github.com/nuclearblock/archgregator/parser"
+	parserconfig "github.com/nuclearblock/archgregator/parser/config"
+	"github.com/nuclearblock/archgregator/types"
+	"github.com/nuclearblock/archgregator/types/config"
+)
+
+// genesisNode is a node.Node serving the genesis of the chain having the given id
+type genesisNode struct {
+	node.Node
+	chainID string
+}
+
+// Genesis implements node.Node
+func (n genesisNode) Genesis() (*tmctypes.ResultGenesis, error) {
+	return &tmctypes.ResultGenesis{Genesis: &tmtypes.GenesisDoc{
+		ChainID:     n.chainID,
+		GenesisTime: time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC),
+	}}, nil
+}
+
+// chainContext returns the context parsing the chain served by the given node, whose id has been configured or
+// has been read from the node
+func chainContext(db *memory.Database, proxy genesisNode, fromNode bool) *parser.Context {
+	encodingConfig := config.MakeEncodingConfig()
+	ctx := parser.NewContext(proxy.chainID, parserconfig.Config{}, &encodingConfig, proxy, db, logging.DefaultLogger())
+	ctx.ChainIDFromNode = fromNode
+	return ctx
+}
+
+func TestCheckChainInfo(t *testing.T) {
+	db := memory.NewDatabase()
+	stored := genesisNode{chainID: "archway-1"}
+	require.NoError(t, parser.CheckChainInfo(chainContext(db, stored, true)))
+
+	// The same chain is accepted again
+	require.NoError(t, parser.CheckChainInfo(chainContext(db, stored, true)))
+
+	// Without a configured chain id, a node serving another chain is refused instead of being stored alongside
+	other := genesisNode{chainID: "archway-2"}
+	require.Error(t, parser.CheckChainInfo(chainContext(db, other, true)))
+
+	// A configured chain id allows to store another chain within the same database
+	require.NoError(t, parser.CheckChainInfo(chainContext(db, other, false)))
+}
+
+func TestCheckChainInfoStoredBlocks(t *testing.T) {
+	// The blocks stored before the identity of the chains was recorded belong to their own chain as well
+	db := memory.NewDatabase()
+	scoped := db.ForChain("archway-1")
+	block := types.NewBlock(10, "HASH", 0, 0, time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, scoped.SaveBlock(block))
+	require.NoError(t, scoped.CommitBlock(block))
+
+	require.Error(t, parser.CheckChainInfo(chainContext(db, genesisNode{chainID: "archway-2"}, true)))
+	require.NoError(t, parser.CheckChainInfo(chainContext(db, genesisNode{chainID: "archway-1"}, true)))
+}
@@ -2,30 +2,55 @@ package parser
 
 import (
 	"github.com/archway-network/archway/app/params"
+	"github.com/nuclearblock/archgregator/broker"
 	"github.com/nuclearblock/archgregator/database"
 	"github.com/nuclearblock/archgregator/logging"
 	"github.com/nuclearblock/archgregator/node"
+	parserconfig "github.com/nuclearblock/archgregator/parser/config"
 )
 
 // Context represents the context that is shared among different workers
 type Context struct {
+	// ChainID is the id of the parsed chain, which all the data is stored for
+	ChainID string
+	Config  parserconfig.Config
+
+	// ChainIDFromNode tells whether the chain id has not been configured, and has been read from the node instead
+	ChainIDFromNode bool
+
 	EncodingConfig *params.EncodingConfig
 	Node           node.Node
 	Database       database.Database
 	Logger         logging.Logger
+
+	// Broker, if set, receives all the records committed by the workers
+	Broker *broker.Broker
+
+	// WriteFailed, if set, is called with each block that a batching database failed to write when the workers
+	// have no queue to process it again. It must neither block nor use the database.
+	WriteFailed func(height int64, err error)
+
+	// cw20Tokens contains the CW20 tokens known by all the workers
+	cw20Tokens *cw20TokenCache
 }
 
 // NewContext builds a new Context instance
+// The given database is scoped to the chain having the given id.
 func NewContext(
+	chainID string,
+	cfg parserconfig.Config,
 	encodingConfig *params.EncodingConfig,
 	proxy node.Node,
 	db database.Database,
 	logger logging.Logger,
 ) *Context {
 	return &Context{
+		ChainID:        chainID,
+		Config:         cfg,
 		EncodingConfig: encodingConfig,
 		Node:           proxy,
-		Database:       db,
+		Database:       db.ForChain(chainID),
 		Logger:         logger,
+		cw20Tokens:     newCw20TokenCache(),
 	}
 }
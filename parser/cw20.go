@@ -0,0 +1,372 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/node"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// cw20TokenInfoQuery is the query returning the info of a CW20 token
+var cw20TokenInfoQuery = []byte(`{"token_info":{}}`)
+
+// cw20Actions contains the attributes that the wasm event of each CW20 action must have, other than the amount
+var cw20Actions = map[string][]string{
+	types.Cw20ActionTransfer:     {"from", "to"},
+	types.Cw20ActionSend:         {"from", "to"},
+	types.Cw20ActionMint:         {"to"},
+	types.Cw20ActionBurn:         {"from"},
+	types.Cw20ActionTransferFrom: {"from", "to", "by"},
+	types.Cw20ActionSendFrom:     {"from", "to", "by"},
+	types.Cw20ActionBurnFrom:     {"from", "by"},
+}
+
+// cw20InitMsg contains the fields of a CW20 instantiate message
+type cw20InitMsg struct {
+	Name            string        `json:"name"`
+	Symbol          string        `json:"symbol"`
+	Decimals        *uint32       `json:"decimals"`
+	InitialBalances []cw20Balance `json:"initial_balances"`
+	Mint            *struct {
+		Minter string `json:"minter"`
+	} `json:"mint"`
+}
+
+// isToken tells whether the message has the shape of a CW20 instantiate message
+func (msg cw20InitMsg) isToken() bool {
+	return msg.Name != "" && msg.Symbol != "" && msg.Decimals != nil
+}
+
+// cw20Balance represents an initial balance of a CW20 instantiate message
+type cw20Balance struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+}
+
+// cw20TokenInfo represents the response to the token_info query
+type cw20TokenInfo struct {
+	Name        string  `json:"name"`
+	Symbol      string  `json:"symbol"`
+	Decimals    *uint32 `json:"decimals"`
+	TotalSupply string  `json:"total_supply"`
+}
+
+// cw20QueryError is returned when the node could not be queried to tell whether a contract is a CW20 token.
+// The block must then be processed again, since skipping the contract might skip the transfers of a token.
+type cw20QueryError struct {
+	contractAddr string
+	err          error
+}
+
+// Error implements error
+func (e *cw20QueryError) Error() string {
+	return fmt.Sprintf("error while querying cw20 token info of %s: %s", e.contractAddr, e.err)
+}
+
+// cw20TokenCache tells whether each contract that has already been checked is a CW20 token, so that neither the
+// database nor the node are queried for every wasm event
+type cw20TokenCache struct {
+	mu     sync.RWMutex
+	tokens map[string]bool
+}
+
+// newCw20TokenCache returns a new empty cw20TokenCache instance
+func newCw20TokenCache() *cw20TokenCache {
+	return &cw20TokenCache{tokens: map[string]bool{}}
+}
+
+// get tells whether the contract having the given address is a CW20 token, and whether it has been checked at all
+func (c *cw20TokenCache) get(address string) (token bool, checked bool) {
+	if c == nil {
+		return false, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	token, checked = c.tokens[address]
+	return token, checked
+}
+
+// set records whether the contract having the given address is a CW20 token
+func (c *cw20TokenCache) set(address string, token bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens[address] = token
+}
+
+// isCw20Token tells whether the contract having the given address is a CW20 token.
+// Since the heights might be parsed in any order, a contract that has not been stored as a token might have been
+// instantiated at a height that has not been parsed yet. Such a contract is queried for its token info at the height
+// of the given tx, and stored as a token if it answers, so that none of its transfers is skipped.
+func isCw20Token(address string, tx *types.Tx, node node.Node, db database.Database, cache *cw20TokenCache) (bool, error) {
+	if token, checked := cache.get(address); checked {
+		return token, nil
+	}
+
+	found, err := db.HasCw20Token(address)
+	if err != nil {
+		return false, fmt.Errorf("error while checking cw20 token: %s", err)
+	}
+	if found {
+		cache.set(address, true)
+		return true, nil
+	}
+
+	info, ok, err := queryCw20TokenInfo(tx.Height, address, node)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		// The answer is remembered, so that the node is queried only once for each contract
+		cache.set(address, false)
+		return false, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, tx.Timestamp)
+	if err != nil {
+		return false, fmt.Errorf("error while parsing time: %s", err)
+	}
+
+	// The instantiation of the token is unknown, so it is stored as detected by the given tx and without a minter.
+	// When its instantiation is parsed later on, the token is not stored again but its initial balances are.
+	err = db.SaveCw20Token(types.NewCw20Token(
+		address, info.Name, info.Symbol, *info.Decimals, "", tx.TxHash, timestamp, tx.Height,
+	))
+	if err != nil {
+		return false, err
+	}
+
+	cache.set(address, true)
+	return true, nil
+}
+
+// queryCw20TokenInfo runs the token_info query against the contract having the given address at the given height,
+// returning the token info and whether the contract answered it as a CW20 token does.
+// A *cw20QueryError is returned if the query could not be run, in which case the contract might still be a token.
+func queryCw20TokenInfo(height int64, address string, proxy node.Node) (*cw20TokenInfo, bool, error) {
+	res, err := proxy.SmartContractState(height, address, cw20TokenInfoQuery)
+	if node.IsContractQueryError(err) {
+		// Contracts that do not answer the query are not tokens
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, &cw20QueryError{contractAddr: address, err: err}
+	}
+
+	var info cw20TokenInfo
+	err = json.Unmarshal(res.Data, &info)
+	if err != nil || info.Name == "" || info.Symbol == "" || info.Decimals == nil || info.TotalSupply == "" {
+		return nil, false, nil
+	}
+	return &info, true, nil
+}
+
+// HandleCw20Msg stores the CW20 token instantiated by the message having the given index, if any, along with the
+// CW20 transfers contained inside its wasm event
+func HandleCw20Msg(
+	index int, tx *types.Tx, msg sdk.Msg, node node.Node, db database.Database, cache *cw20TokenCache,
+) error {
+	var eventIndex int
+	if instantiateMsg, ok := msg.(*wasmtypes.MsgInstantiateContract); ok {
+		saved, err := handleCw20Instantiate(index, tx, instantiateMsg, node, db, cache)
+		if err != nil {
+			return err
+		}
+
+		// The initial balances of the token use the first event indexes
+		eventIndex = saved
+	}
+
+	return handleCw20Events(index, eventIndex, tx, node, db, cache)
+}
+
+// handleCw20Instantiate detects whether the contract instantiated by the given message is a CW20 token, either from
+// the shape of its instantiate message or by querying its token info, and stores it along with its initial balances.
+// It returns the number of stored initial balances.
+func handleCw20Instantiate(
+	index int, tx *types.Tx, msg *wasmtypes.MsgInstantiateContract, node node.Node, db database.Database, cache *cw20TokenCache,
+) (int, error) {
+	event, err := tx.FindEventByType(index, wasmtypes.EventTypeInstantiate)
+	if err != nil {
+		return 0, fmt.Errorf("error while searching for EventTypeInstantiate: %s", err)
+	}
+
+	contractAddress, err := tx.FindAttributeByKey(event, wasmtypes.AttributeKeyContractAddr)
+	if err != nil {
+		return 0, fmt.Errorf("error while searching for AttributeKeyContractAddr: %s", err)
+	}
+
+	// A contract instantiated with a message having the shape of a CW20 one is trusted to be a token without
+	// querying the node, so that tokens are detected by nodes not supporting smart queries as well.
+	// Any other contract is only a token if it answers the token_info query.
+	var initMsg cw20InitMsg
+	_ = json.Unmarshal(msg.Msg, &initMsg)
+
+	if !initMsg.isToken() {
+		info, ok, err := queryCw20TokenInfo(tx.Height, contractAddress, node)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return 0, nil
+		}
+
+		initMsg.Name, initMsg.Symbol, initMsg.Decimals = info.Name, info.Symbol, info.Decimals
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, tx.Timestamp)
+	if err != nil {
+		return 0, fmt.Errorf("error while parsing time: %s", err)
+	}
+
+	var minter string
+	if initMsg.Mint != nil {
+		minter = initMsg.Mint.Minter
+	}
+
+	err = db.SaveCw20Token(types.NewCw20Token(
+		contractAddress, initMsg.Name, initMsg.Symbol, *initMsg.Decimals, minter, tx.TxHash, timestamp, tx.Height,
+	))
+	if err != nil {
+		return 0, err
+	}
+	cache.set(contractAddress, true)
+
+	// The initial balances do not emit any event, so they are stored as transfers having no sender
+	for i, balance := range initMsg.InitialBalances {
+		amount, ok := sdk.NewIntFromString(balance.Amount)
+		if !ok {
+			return 0, fmt.Errorf("invalid cw20 initial balance amount: %s", balance.Amount)
+		}
+
+		err = db.SaveCw20Transfer(types.NewCw20Transfer(
+			contractAddress, types.Cw20ActionInstantiate, "", balance.Address, "", amount,
+			tx.TxHash, index, i, timestamp, tx.Height,
+		))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(initMsg.InitialBalances), nil
+}
+
+// handleCw20Events stores the CW20 transfers contained inside the wasm event of the message having the given index,
+// numbering them from the given event index.
+// Since the attributes of all the contracts called by the message are merged into a single event, the event is
+// split at each contract address, and only the parts emitted by CW20 tokens are parsed.
+func handleCw20Events(
+	index int, eventIndex int, tx *types.Tx, node node.Node, db database.Database, cache *cw20TokenCache,
+) error {
+	event, err := tx.FindEventByType(index, wasmtypes.WasmModuleEventType)
+	if err != nil {
+		// Messages not calling any contract have no wasm event
+		return nil
+	}
+
+	var timestamp time.Time
+	for _, attributes := range splitWasmEvent(event) {
+		contractAddress := attributes[wasmtypes.AttributeKeyContractAddr]
+		if _, ok := cw20Actions[attributes["action"]]; !ok {
+			continue
+		}
+
+		found, err := isCw20Token(contractAddress, tx, node, db, cache)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+
+		transfer, err := parseCw20Transfer(attributes)
+		if err != nil {
+			return fmt.Errorf("error while parsing cw20 %s of %s: %s", attributes["action"], contractAddress, err)
+		}
+
+		if timestamp.IsZero() {
+			timestamp, err = time.Parse(time.RFC3339, tx.Timestamp)
+			if err != nil {
+				return fmt.Errorf("error while parsing time: %s", err)
+			}
+		}
+
+		transfer.TxHash, transfer.MsgIndex, transfer.EventIndex = tx.TxHash, index, eventIndex
+		transfer.ExecutedAt, transfer.Height = timestamp, tx.Height
+
+		err = db.SaveCw20Transfer(transfer)
+		if err != nil {
+			return err
+		}
+		eventIndex++
+	}
+
+	return nil
+}
+
+// splitWasmEvent splits the given wasm event into the attributes emitted by each contract call, each part starting
+// with the address of the called contract
+func splitWasmEvent(event sdk.StringEvent) []map[string]string {
+	var parts []map[string]string
+	for _, attr := range event.Attributes {
+		if attr.Key == wasmtypes.AttributeKeyContractAddr {
+			parts = append(parts, map[string]string{})
+		}
+		if len(parts) == 0 {
+			continue
+		}
+
+		// Keep the first value, so that a contract cannot override the attributes it has already emitted
+		part := parts[len(parts)-1]
+		if _, ok := part[attr.Key]; !ok {
+			part[attr.Key] = attr.Value
+		}
+	}
+	return parts
+}
+
+// parseCw20Transfer builds the transfer described by the given wasm event attributes, which must contain a
+// supported CW20 action
+func parseCw20Transfer(attributes map[string]string) (types.Cw20Transfer, error) {
+	action := attributes["action"]
+	for _, key := range cw20Actions[action] {
+		if attributes[key] == "" {
+			return types.Cw20Transfer{}, fmt.Errorf("missing %s attribute", key)
+		}
+	}
+
+	amount, ok := sdk.NewIntFromString(attributes["amount"])
+	if !ok {
+		return types.Cw20Transfer{}, fmt.Errorf("invalid amount: %s", attributes["amount"])
+	}
+
+	var from, to string
+	switch action {
+	case types.Cw20ActionMint:
+		to = attributes["to"]
+	case types.Cw20ActionBurn, types.Cw20ActionBurnFrom:
+		from = attributes["from"]
+	default:
+		from, to = attributes["from"], attributes["to"]
+	}
+
+	return types.Cw20Transfer{
+		ContractAddress: attributes[wasmtypes.AttributeKeyContractAddr],
+		Action:          action,
+		From:            from,
+		To:              to,
+		By:              attributes["by"],
+		Amount:          amount,
+	}, nil
+}
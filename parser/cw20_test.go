@@ -0,0 +1,110 @@
+package parser_test
+
+import (
+	"fmt"
+	"testing"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nuclearblock/archgregator/database/memory"
+	"github.com/nuclearblock/archgregator/node"
+	"github.com/nuclearblock/archgregator/node/fixture"
+	"github.com/nuclearblock/archgregator/node/store"
+	"github.com/nuclearblock/archgregator/parser"
+	"github.com/nuclearblock/archgregator/types"
+	"github.com/nuclearblock/archgregator/types/config"
+)
+
+// executeMsgIndex is the index of the message transferring the CW20 token inside the recorded tx
+const executeMsgIndex = 2
+
+// recordedTransfer returns the recorded tx along with its message transferring the CW20 token
+func recordedTransfer(t *testing.T) (*types.Tx, sdk.Msg) {
+	encodingConfig := config.MakeEncodingConfig()
+	txs, err := store.NewStore("testdata", encodingConfig.Marshaler).ReadTxs(100)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+
+	var msg sdk.Msg
+	require.NoError(t, encodingConfig.Marshaler.UnpackAny(txs[0].Body.Messages[executeMsgIndex], &msg))
+	require.IsType(t, &wasmtypes.MsgExecuteContract{}, msg)
+	return txs[0], msg
+}
+
+// tokenInfoNode returns a node answering the token_info query of the recorded token
+func tokenInfoNode(t *testing.T) node.Node {
+	encodingConfig := config.MakeEncodingConfig()
+	recorded := store.NewStore(t.TempDir(), encodingConfig.Marshaler)
+	require.NoError(t, recorded.WriteSmartContractState(100, contract, []byte(`{"token_info":{}}`),
+		&wasmtypes.QuerySmartContractStateResponse{
+			Data: []byte(`{"name":"Fixture","symbol":"FIX","decimals":6,"total_supply":"1000000"}`),
+		},
+	))
+	return fixture.NewStoreNode(recorded)
+}
+
+// unreachableNode is a node.Node failing to run the given number of smart queries before answering them
+type unreachableNode struct {
+	node.Node
+	failures int
+}
+
+// SmartContractState implements node.Node
+func (n *unreachableNode) SmartContractState(height int64, contractAddr string, query []byte) (*wasmtypes.QuerySmartContractStateResponse, error) {
+	if n.failures > 0 {
+		n.failures--
+		return nil, fmt.Errorf("error while getting contract smart state: connection refused")
+	}
+	return n.Node.SmartContractState(height, contractAddr, query)
+}
+
+func TestHandleCw20MsgUnknownToken(t *testing.T) {
+	tx, msg := recordedTransfer(t)
+
+	// The instantiation of the token has not been parsed, but the token answers the token_info query
+	db := memory.NewDatabase().ForChain(chainID).(*memory.Database)
+	require.NoError(t, parser.HandleCw20Msg(executeMsgIndex, tx, msg, tokenInfoNode(t), db, nil))
+
+	found, err := db.HasCw20Token(contract)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, sdk.NewInt(2500), db.Cw20Balance(contract, recipient))
+}
+
+func TestHandleCw20MsgNotToken(t *testing.T) {
+	tx, msg := recordedTransfer(t)
+
+	// A contract not answering the token_info query is not a token, even if its events look like CW20 ones
+	encodingConfig := config.MakeEncodingConfig()
+	proxy := fixture.NewStoreNode(store.NewStore(t.TempDir(), encodingConfig.Marshaler))
+
+	db := memory.NewDatabase().ForChain(chainID).(*memory.Database)
+	require.NoError(t, parser.HandleCw20Msg(executeMsgIndex, tx, msg, proxy, db, nil))
+
+	found, err := db.HasCw20Token(contract)
+	require.NoError(t, err)
+	require.False(t, found)
+	require.True(t, db.Cw20Balance(contract, recipient).IsZero())
+}
+
+func TestHandleCw20MsgUnreachableNode(t *testing.T) {
+	tx, msg := recordedTransfer(t)
+	proxy := &unreachableNode{Node: tokenInfoNode(t), failures: 1}
+	db := memory.NewDatabase().ForChain(chainID).(*memory.Database)
+
+	// The contract is not assumed not to be a token when the node cannot be queried, so that the block is retried
+	require.Error(t, parser.HandleCw20Msg(executeMsgIndex, tx, msg, proxy, db, nil))
+
+	found, err := db.HasCw20Token(contract)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, parser.HandleCw20Msg(executeMsgIndex, tx, msg, proxy, db, nil))
+
+	found, err = db.HasCw20Token(contract)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, sdk.NewInt(2500), db.Cw20Balance(contract, recipient))
+}
@@ -10,8 +10,9 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/codec"
 
+	"github.com/nuclearblock/archgregator/broker"
 	"github.com/nuclearblock/archgregator/database"
-	"github.com/nuclearblock/archgregator/types/config"
+	parserconfig "github.com/nuclearblock/archgregator/parser/config"
 
 	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
 	gastrackertypes "github.com/archway-network/archway/x/gastracker/types"
@@ -27,23 +28,40 @@ import (
 // Worker defines a job consumer that is responsible for getting and
 // aggregating block and associated data and exporting it to a database.
 type Worker struct {
-	index  int
-	queue  types.HeightQueue
-	codec  codec.Codec
-	node   node.Node
-	db     database.Database
-	logger logging.Logger
+	index   int
+	chainID string
+	cfg     parserconfig.Config
+	queue   types.HeightQueue
+	codec   codec.Codec
+	node    node.Node
+	db      database.Database
+	logger  logging.Logger
+
+	cw20Tokens  *cw20TokenCache
+	writeFailed func(height int64, err error)
 }
 
 // NewWorker allows to create a new Worker implementation.
 func NewWorker(ctx *Context, queue types.HeightQueue, index int) Worker {
+	// Each worker gets its own database instance since batching databases track the records of the block being processed
+	db := ctx.Database.ForChain(ctx.ChainID)
+	if ctx.Broker != nil {
+		// Each worker gets its own publisher since it tracks the records of the block being processed
+		db = broker.NewDatabase(db, ctx.Broker).ForChain(ctx.ChainID)
+	}
+
 	return Worker{
-		index:  index,
-		codec:  ctx.EncodingConfig.Marshaler,
-		node:   ctx.Node,
-		queue:  queue,
-		db:     ctx.Database,
-		logger: ctx.Logger,
+		index:   index,
+		chainID: ctx.ChainID,
+		cfg:     ctx.Config,
+		codec:   ctx.EncodingConfig.Marshaler,
+		node:    ctx.Node,
+		queue:   queue,
+		db:      db,
+		logger:  ctx.Logger,
+
+		cw20Tokens:  ctx.cw20Tokens,
+		writeFailed: ctx.WriteFailed,
 	}
 }
 
@@ -62,7 +80,7 @@ func (w Worker) Start() {
 			}()
 		}
 
-		logging.WorkerHeight.WithLabelValues(fmt.Sprintf("%d", w.index)).Set(float64(i))
+		logging.WorkerHeight.WithLabelValues(w.chainID, fmt.Sprintf("%d", w.index)).Set(float64(i))
 	}
 }
 
@@ -88,8 +106,7 @@ func (w Worker) ProcessIfNotExists(height int64) error {
 func (w Worker) Process(height int64) error {
 	// process genesis if needed
 	if height == 0 {
-		cfg := config.Cfg.Parser
-		genesisDoc, genesisState, err := utils.GetGenesisDocAndState(cfg.GenesisFilePath, w.node)
+		genesisDoc, genesisState, err := utils.GetGenesisDocAndState(w.cfg.GenesisFilePath, w.node)
 		if err != nil {
 			return fmt.Errorf("failed to get genesis: %s", err)
 		}
@@ -108,7 +125,7 @@ func (w Worker) Process(height int64) error {
 		return fmt.Errorf("failed to get block results from node: %s", err)
 	}
 
-	txs, err := w.node.Txs(block)
+	txs, err := w.node.Txs(block, events)
 	if err != nil {
 		return fmt.Errorf("failed to get transactions for block: %s", err)
 	}
@@ -128,7 +145,8 @@ func (w Worker) HandleGenesis(genesisDoc *tmtypes.GenesisDoc, appState map[strin
 // is returned if the write fails.
 func (w Worker) ExportBlock(b *tmctypes.ResultBlock, r *tmctypes.ResultBlockResults, txs []*types.Tx) error {
 	// Save block to database
-	err := w.db.SaveBlock(types.NewBlockFromTmBlock(b, sumGasTxs(txs)))
+	block := types.NewBlockFromTmBlock(b, sumGasTxs(txs))
+	err := w.db.SaveBlock(block)
 	if err != nil {
 		return fmt.Errorf("failed to save block: %s", err)
 	}
@@ -148,9 +166,42 @@ func (w Worker) ExportBlock(b *tmctypes.ResultBlock, r *tmctypes.ResultBlockResu
 		return fmt.Errorf("failed to process transactions: %s", err)
 	}
 
+	if batcher, ok := w.db.(database.Batcher); ok {
+		err = batcher.CommitBlockAsync(block, w.blockWritten(block.Height))
+	} else {
+		err = w.db.CommitBlock(block)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to commit block: %s", err)
+	}
+
 	return nil
 }
 
+// blockWritten returns the function called once the block having the given height has been written by a
+// batching database, which re-enqueues the block if it could not be written, or reports it to the context
+// when there is no queue
+func (w Worker) blockWritten(height int64) func(err error) {
+	return func(err error) {
+		if err == nil {
+			return
+		}
+
+		if w.queue == nil {
+			w.logger.Error("error while writing block", "height", height, "err", err)
+			if w.writeFailed != nil {
+				w.writeFailed(height, err)
+			}
+			return
+		}
+
+		go func() {
+			w.logger.Error("re-enqueueing failed block", "height", height, "err", err)
+			w.queue <- height
+		}()
+	}
+}
+
 // ProcessEvents accepts a set of events of current BeginBlock
 // Events will be processed to catch gastracker rewards
 func (w Worker) ProcessEvents(r *tmctypes.ResultBlockResults, ts time.Time) error {
@@ -211,6 +262,16 @@ func (w Worker) ProcessTransactions(txs []*types.Tx) error {
 					w.logger.MsgError(tx, cosmosMsg, err)
 				}
 			}
+
+			// CW20 tokens and transfers. The block is processed again if the node could not tell whether a contract
+			// is a token, so that none of its transfers is skipped
+			err = HandleCw20Msg(i, tx, stdMsg, w.node, w.db, w.cw20Tokens)
+			if _, ok := err.(*cw20QueryError); ok {
+				return err
+			}
+			if err != nil {
+				w.logger.MsgError(tx, stdMsg, err)
+			}
 		}
 	}
 	return nil
@@ -0,0 +1,228 @@
+package parser_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nuclearblock/archgregator/database"
+	"github.com/nuclearblock/archgregator/database/memory"
+	dbtypes "github.com/nuclearblock/archgregator/database/types"
+	"github.com/nuclearblock/archgregator/logging"
+	"github.com/nuclearblock/archgregator/node/fixture"
+	"github.com/nuclearblock/archgregator/node/store"
+	"github.com/nuclearblock/archgregator/parser"
+	parserconfig "github.com/nuclearblock/archgregator/parser/config"
+	"github.com/nuclearblock/archgregator/types"
+	"github.com/nuclearblock/archgregator/types/config"
+)
+
+// The testdata directory contains two recorded blocks of a single tx chain:
+// block 100 contains a tx storing a code, instantiating a CW20 token from it, transferring some of the token and
+// setting the token gastracker metadata, while block 101 contains the gastracker rewards of block 100.
+const (
+	chainID = "archway-fixture-1"
+
+	creator   = "archway1h34lmpywh4upnjdg90cjf4j70aee6z8q8hxt6t"
+	holder    = "archway1deznsmdwkzuxmgdvf3sfqqw3a9eljqc53n5st9"
+	recipient = "archway1vewsdxxmeraett7ztsaym88jsrv85kzmjj2k6l"
+	developer = "archway13raq6avls3d50szyctx5fc5sst8kl6nx0ldw5w"
+	rewards   = "archway1245yut9zht8q4hz39sd0lzqtzkuw5us50e94ya"
+	contract  = "archway1ejpjr43ht3y56pplm5pxpusmcrk9rkkvna4tklusnnwdxpqm0zlsk6elme"
+)
+
+var (
+	blockTime = time.Date(2022, 4, 12, 10, 30, 0, 0, time.UTC)
+	allRows   = dbtypes.NewPagination(100, 0)
+)
+
+// parseFixtures processes the recorded blocks into a new memory database, returning it along with the fixture node
+func parseFixtures(t *testing.T) (*memory.Database, *fixture.Node) {
+	encodingConfig := config.MakeEncodingConfig()
+	node := fixture.NewStoreNode(store.NewStore("testdata", encodingConfig.Marshaler))
+	db := memory.NewDatabase().ForChain(chainID).(*memory.Database)
+
+	ctx := parser.NewContext(chainID, parserconfig.Config{}, &encodingConfig, node, db, logging.DefaultLogger())
+	worker := parser.NewWorker(ctx, nil, 0)
+	for _, height := range []int64{100, 101} {
+		require.NoError(t, worker.Process(height))
+	}
+
+	return db, node
+}
+
+func TestWorkerProcess(t *testing.T) {
+	db, node := parseFixtures(t)
+	require.Equal(t, []int64{100, 101}, db.CommittedHeights())
+
+	t.Run("block", func(t *testing.T) {
+		recorded, err := node.Block(100)
+		require.NoError(t, err)
+
+		block, err := db.GetBlock(100)
+		require.NoError(t, err)
+		require.NotNil(t, block)
+		require.Equal(t, dbtypes.BlockRow{
+			ChainID:   chainID,
+			Height:    100,
+			Hash:      recorded.Block.Hash().String(),
+			TxNum:     1,
+			TotalGas:  312345,
+			Timestamp: blockTime,
+		}, *block)
+
+		empty, err := db.GetBlock(101)
+		require.NoError(t, err)
+		require.NotNil(t, empty)
+		require.Equal(t, 0, empty.TxNum)
+		require.Zero(t, empty.TotalGas)
+	})
+
+	t.Run("codes", func(t *testing.T) {
+		codeHash := sha256.Sum256([]byte("\x00asm\x01\x00\x00\x00"))
+
+		codes, err := db.GetWasmCodes(dbtypes.WasmCodesFilter{}, allRows)
+		require.NoError(t, err)
+		require.Len(t, codes, 1)
+		require.Equal(t, int64(1), codes[0].CodeID)
+		require.Equal(t, creator, codes[0].Creator)
+		require.Equal(t, 8, codes[0].Size)
+		require.Equal(t, strings.ToUpper(hex.EncodeToString(codeHash[:])), codes[0].CodeHash)
+		require.Equal(t, blockTime, codes[0].SavedAt)
+		require.Equal(t, int64(100), codes[0].Height)
+	})
+
+	t.Run("contracts", func(t *testing.T) {
+		contracts, err := db.GetWasmContracts(dbtypes.WasmContractsFilter{}, allRows)
+		require.NoError(t, err)
+		require.Len(t, contracts, 1)
+
+		row := contracts[0]
+		require.Equal(t, contract, row.ContractAddress)
+		require.Equal(t, int64(1), row.CodeID)
+		require.Equal(t, creator, row.Creator)
+		require.Equal(t, creator, row.Admin)
+		require.Equal(t, "fixture token", row.Label)
+		funds := dbtypes.NewDbCoins(sdk.NewCoins(sdk.NewInt64Coin("utorii", 100)))
+		require.True(t, row.Funds.Equal(&funds))
+		require.Equal(t, blockTime, row.InstantiatedAt)
+		require.Equal(t, int64(100), row.Height)
+
+		var msg map[string]interface{}
+		require.NoError(t, json.Unmarshal(row.RawContractMessage, &msg))
+		require.Equal(t, "FIX", msg["symbol"])
+	})
+
+	t.Run("executions", func(t *testing.T) {
+		executions, err := db.GetWasmExecuteContracts(dbtypes.WasmExecuteContractsFilter{}, allRows)
+		require.NoError(t, err)
+		require.Len(t, executions, 1)
+
+		row := executions[0]
+		require.Equal(t, holder, row.Sender)
+		require.Equal(t, contract, row.ContractAddress)
+		require.JSONEq(t, `{"transfer":{"recipient":"`+recipient+`","amount":"2500"}}`, string(row.RawContractMessage))
+		require.Equal(t, int64(312345), row.GasUsed)
+		require.Equal(t, "utorii", row.FeesDenom)
+		require.Equal(t, float64(5000), row.FeesAmount)
+		require.Equal(t, blockTime, row.ExecutedAt)
+		require.Equal(t, int64(100), row.Height)
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		metadata, err := db.GetContractMetadata(dbtypes.ContractMetadataFilter{ContractAddress: contract}, allRows)
+		require.NoError(t, err)
+		require.Len(t, metadata, 1)
+
+		row := metadata[0]
+		require.Equal(t, rewards, row.RewardAddress)
+		require.Equal(t, developer, row.DeveloperAddress)
+		require.True(t, row.CollectPremium)
+		require.False(t, row.GasRebateToUser)
+		require.Equal(t, int64(20), row.PremiumPercentageCharged)
+		require.Equal(t, int64(100), row.Height)
+	})
+
+	t.Run("rewards", func(t *testing.T) {
+		// The rewards emitted by block 101 belong to block 100
+		rows, err := db.GetContractRewards(dbtypes.ContractRewardsFilter{ContractAddress: contract}, allRows)
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+
+		row := rows[0]
+		require.Equal(t, rewards, row.RewardAddress)
+		require.Equal(t, developer, row.DeveloperAddress)
+		require.Equal(t, "312345", row.GasConsumed)
+		require.Equal(t, "utorii", row.ContractRewardsDenom)
+		require.Equal(t, 12.5, row.ContractRewardsAmount)
+		require.Equal(t, 3.25, row.InflationRewardsAmount)
+		require.Equal(t, float64(15), row.DistributedRewardsAmount)
+		require.Equal(t, 0.75, row.LeftoverRewardsAmount)
+		require.Equal(t, blockTime.Add(6*time.Second), row.RewardDate)
+		require.Equal(t, int64(100), row.Height)
+	})
+
+	t.Run("cw20", func(t *testing.T) {
+		found, err := db.HasCw20Token(contract)
+		require.NoError(t, err)
+		require.True(t, found)
+
+		require.Equal(t, sdk.NewInt(997500), db.Cw20Balance(contract, holder))
+		require.Equal(t, sdk.NewInt(2500), db.Cw20Balance(contract, recipient))
+	})
+}
+
+func TestWorkerProcessIfNotExists(t *testing.T) {
+	db, node := parseFixtures(t)
+
+	// Processing a stored block again must not duplicate any of its records
+	encodingConfig := config.MakeEncodingConfig()
+	ctx := parser.NewContext(chainID, parserconfig.Config{}, &encodingConfig, node, db, logging.DefaultLogger())
+	require.NoError(t, parser.NewWorker(ctx, nil, 0).ProcessIfNotExists(100))
+
+	require.Equal(t, []int64{100, 101}, db.CommittedHeights())
+
+	executions, err := db.GetWasmExecuteContracts(dbtypes.WasmExecuteContractsFilter{}, allRows)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+}
+
+// failingBatcher is a batching database failing to write every committed block
+type failingBatcher struct {
+	*memory.Database
+}
+
+// ForChain implements database.Database
+func (db failingBatcher) ForChain(_ string) database.Database {
+	return db
+}
+
+// CommitBlockAsync implements database.Batcher
+func (db failingBatcher) CommitBlockAsync(_ *types.Block, done func(err error)) error {
+	done(fmt.Errorf("error while committing batch transaction"))
+	return nil
+}
+
+func TestWorkerWriteFailed(t *testing.T) {
+	encodingConfig := config.MakeEncodingConfig()
+	node := fixture.NewStoreNode(store.NewStore("testdata", encodingConfig.Marshaler))
+	db := failingBatcher{memory.NewDatabase().ForChain(chainID).(*memory.Database)}
+
+	// Without a queue, the blocks that failed to be written are reported to the context
+	var failed []int64
+	ctx := parser.NewContext(chainID, parserconfig.Config{}, &encodingConfig, node, db, logging.DefaultLogger())
+	ctx.WriteFailed = func(height int64, err error) {
+		require.Error(t, err)
+		failed = append(failed, height)
+	}
+
+	require.NoError(t, parser.NewWorker(ctx, nil, 0).Process(100))
+	require.Equal(t, []int64{100}, failed)
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config contains the configuration of the contract state snapshots
+type Config struct {
+	Interval  time.Duration    `yaml:"interval"`
+	Contracts []ContractConfig `yaml:"contracts"`
+}
+
+// NewSnapshotsConfig allows to build a new Config instance
+func NewSnapshotsConfig(interval time.Duration, contracts []ContractConfig) *Config {
+	return &Config{
+		Interval:  interval,
+		Contracts: contracts,
+	}
+}
+
+// DefaultSnapshotsConfig returns the default instance of Config
+func DefaultSnapshotsConfig() *Config {
+	return NewSnapshotsConfig(time.Hour, nil)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, using the default values for all the fields that are not set
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	type rawConfig Config
+	cfg := rawConfig(*DefaultSnapshotsConfig())
+	err := value.Decode(&cfg)
+	if err != nil {
+		return err
+	}
+
+	*c = Config(cfg)
+	return nil
+}
+
+// ContractConfig contains the configuration of a single watched contract.
+// Each query is a JSON smart query message, while Raw tells whether all the raw key-value pairs of the contract
+// should be stored too. An empty ChainID matches every indexed chain.
+type ContractConfig struct {
+	Address string   `yaml:"address"`
+	ChainID string   `yaml:"chain_id,omitempty"`
+	Queries []string `yaml:"queries,omitempty"`
+	Raw     bool     `yaml:"raw,omitempty"`
+}
+
+// NewContractConfig allows to build a new ContractConfig instance
+func NewContractConfig(address, chainID string, queries []string, raw bool) ContractConfig {
+	return ContractConfig{
+		Address: address,
+		ChainID: chainID,
+		Queries: queries,
+		Raw:     raw,
+	}
+}
@@ -0,0 +1,189 @@
+package snapshots
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+
+	"github.com/nuclearblock/archgregator/logging"
+	"github.com/nuclearblock/archgregator/parser"
+	snapshotsconfig "github.com/nuclearblock/archgregator/snapshots/config"
+	"github.com/nuclearblock/archgregator/types"
+)
+
+// Snapshotter periodically queries the state of the watched contracts of each indexed chain,
+// and stores it inside the database
+type Snapshotter struct {
+	cfg      snapshotsconfig.Config
+	contexts []*parser.Context
+
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	logger logging.Logger
+}
+
+// NewSnapshotter returns a new Snapshotter instance taking the snapshots of the chains having the given contexts
+func NewSnapshotter(cfg *snapshotsconfig.Config, contexts []*parser.Context, logger logging.Logger) (*Snapshotter, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("snapshots config cannot be null")
+	}
+
+	snapshotterCfg := *cfg
+	if snapshotterCfg.Interval <= 0 {
+		snapshotterCfg.Interval = snapshotsconfig.DefaultSnapshotsConfig().Interval
+	}
+
+	for _, contract := range snapshotterCfg.Contracts {
+		err := validateContract(contract, contexts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot contract %s: %s", contract.Address, err)
+		}
+	}
+
+	return &Snapshotter{
+		cfg:      snapshotterCfg,
+		contexts: contexts,
+		done:     make(chan struct{}),
+		logger:   logger,
+	}, nil
+}
+
+// validateContract checks that the given contract configuration is valid, and that its chain is indexed
+func validateContract(contract snapshotsconfig.ContractConfig, contexts []*parser.Context) error {
+	if contract.Address == "" {
+		return fmt.Errorf("address cannot be empty")
+	}
+
+	if len(contract.Queries) == 0 && !contract.Raw {
+		return fmt.Errorf("either a query or the raw state must be set")
+	}
+
+	for _, query := range contract.Queries {
+		if !json.Valid([]byte(query)) {
+			return fmt.Errorf("query %s is not a valid JSON", query)
+		}
+	}
+
+	if contract.ChainID == "" {
+		return nil
+	}
+
+	for _, ctx := range contexts {
+		if ctx.ChainID == contract.ChainID {
+			return nil
+		}
+	}
+	return fmt.Errorf("chain %s is not indexed", contract.ChainID)
+}
+
+// Start starts taking the snapshots in background, the first time right away
+func (s *Snapshotter) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			for _, ctx := range s.contexts {
+				s.snapshotChain(ctx)
+			}
+
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops taking the snapshots, waiting for the ones being taken
+func (s *Snapshotter) Stop() {
+	close(s.done)
+	s.wg.Wait()
+}
+
+// snapshotChain takes the snapshots of all the watched contracts of the chain having the given context,
+// at the latest height of the chain.
+// The errors are only logged, so that a failing contract does not prevent the others from being stored
+func (s *Snapshotter) snapshotChain(ctx *parser.Context) {
+	height, err := ctx.Node.LatestHeight()
+	if err != nil {
+		s.logger.Error("error while getting latest height for snapshots", "chain_id", ctx.ChainID, "err", err)
+		return
+	}
+
+	db := ctx.Database.ForChain(ctx.ChainID)
+	for _, contract := range s.cfg.Contracts {
+		if contract.ChainID != "" && contract.ChainID != ctx.ChainID {
+			continue
+		}
+
+		for _, query := range contract.Queries {
+			snapshot, err := smartSnapshot(ctx, contract.Address, []byte(query), height)
+			if err == nil {
+				err = db.SaveContractStateSnapshot(snapshot)
+			}
+			if err != nil {
+				s.logger.Error("error while taking contract smart snapshot", "chain_id", ctx.ChainID,
+					"contract", contract.Address, "query", query, "height", height, "err", err)
+			}
+		}
+
+		if contract.Raw {
+			snapshot, err := rawSnapshot(ctx, contract.Address, height)
+			if err == nil {
+				err = db.SaveContractStateSnapshot(snapshot)
+			}
+			if err != nil {
+				s.logger.Error("error while taking contract raw snapshot", "chain_id", ctx.ChainID,
+					"contract", contract.Address, "height", height, "err", err)
+			}
+		}
+	}
+}
+
+// smartSnapshot returns the snapshot containing the response of the given contract to the given query
+func smartSnapshot(ctx *parser.Context, address string, query []byte, height int64) (types.ContractStateSnapshot, error) {
+	res, err := ctx.Node.SmartContractState(height, address, query)
+	if err != nil {
+		return types.ContractStateSnapshot{}, err
+	}
+
+	if !json.Valid(res.Data) {
+		return types.ContractStateSnapshot{}, fmt.Errorf("the response is not a valid JSON")
+	}
+
+	return types.NewContractStateSnapshot(
+		address, types.SnapshotTypeSmart, query, res.Data, height, time.Now().UTC(),
+	), nil
+}
+
+// rawSnapshot returns the snapshot containing all the raw key-value pairs of the given contract,
+// with hex encoded keys and base64 encoded values
+func rawSnapshot(ctx *parser.Context, address string, height int64) (types.ContractStateSnapshot, error) {
+	res, err := ctx.Node.AllContractState(height, address)
+	if err != nil {
+		return types.ContractStateSnapshot{}, err
+	}
+
+	models := res.Models
+	if models == nil {
+		models = []wasmtypes.Model{}
+	}
+
+	bz, err := json.Marshal(models)
+	if err != nil {
+		return types.ContractStateSnapshot{}, fmt.Errorf("error while serializing raw state: %s", err)
+	}
+
+	return types.NewContractStateSnapshot(
+		address, types.SnapshotTypeRaw, nil, bz, height, time.Now().UTC(),
+	), nil
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// ChainInfo contains the identity of the chain the stored data belongs to
+type ChainInfo struct {
+	ChainID     string
+	GenesisHash string
+}
+
+// NewChainInfo allows to build a new ChainInfo instance
+func NewChainInfo(chainID, genesisHash string) ChainInfo {
+	return ChainInfo{
+		ChainID:     chainID,
+		GenesisHash: genesisHash,
+	}
+}
+
+// NewChainInfoFromGenesis builds a new ChainInfo instance identifying the chain started from the given genesis.
+// The genesis hash is the hex encoded SHA-256 of the genesis encoded using the Tendermint JSON.
+func NewChainInfoFromGenesis(genesis *tmtypes.GenesisDoc) (ChainInfo, error) {
+	bz, err := tmjson.Marshal(genesis)
+	if err != nil {
+		return ChainInfo{}, fmt.Errorf("error while encoding genesis: %s", err)
+	}
+
+	hash := sha256.Sum256(bz)
+	return NewChainInfo(genesis.ChainID, hex.EncodeToString(hash[:])), nil
+}
+
+// Equal tells whether the given info identify the same chain
+func (info ChainInfo) Equal(other ChainInfo) bool {
+	return info.ChainID == other.ChainID && info.GenesisHash == other.GenesisHash
+}
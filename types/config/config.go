@@ -1,10 +1,15 @@
 package config
 
 import (
+	"fmt"
+
+	apiconfig "github.com/nuclearblock/archgregator/api/config"
 	databaseconfig "github.com/nuclearblock/archgregator/database/config"
 	loggingconfig "github.com/nuclearblock/archgregator/logging/config"
 	nodeconfig "github.com/nuclearblock/archgregator/node/config"
+	notificationsconfig "github.com/nuclearblock/archgregator/notifications/config"
 	parserconfig "github.com/nuclearblock/archgregator/parser/config"
+	snapshotsconfig "github.com/nuclearblock/archgregator/snapshots/config"
 )
 
 var (
@@ -21,6 +26,17 @@ type Config struct {
 	Parser   parserconfig.Config   `yaml:"parsing"`
 	Database databaseconfig.Config `yaml:"database"`
 	Logging  loggingconfig.Config  `yaml:"logging"`
+	API      apiconfig.Config      `yaml:"api"`
+
+	// Chains contains the chains to be indexed into the same database.
+	// When empty, only the chain described by Chain, Node and Parser is indexed
+	Chains []IndexedChainConfig `yaml:"chains,omitempty"`
+
+	// Notifications are disabled when not set
+	Notifications *notificationsconfig.Config `yaml:"notifications,omitempty"`
+
+	// Snapshots are disabled when not set
+	Snapshots *snapshotsconfig.Config `yaml:"snapshots,omitempty"`
 }
 
 // NewConfig builds a new Config instance
@@ -30,13 +46,19 @@ func NewConfig(
 	dbConfig databaseconfig.Config,
 	parserConfig parserconfig.Config,
 	loggingConfig loggingconfig.Config,
+	apiConfig apiconfig.Config,
+	notificationsConfig *notificationsconfig.Config,
+	snapshotsConfig *snapshotsconfig.Config,
 ) Config {
 	return Config{
-		Node:     nodeCfg,
-		Chain:    chainCfg,
-		Database: dbConfig,
-		Parser:   parserConfig,
-		Logging:  loggingConfig,
+		Node:          nodeCfg,
+		Chain:         chainCfg,
+		Database:      dbConfig,
+		Parser:        parserConfig,
+		Logging:       loggingConfig,
+		API:           apiConfig,
+		Notifications: notificationsConfig,
+		Snapshots:     snapshotsConfig,
 	}
 }
 
@@ -47,6 +69,9 @@ func DefaultConfig() Config {
 		databaseconfig.DefaultDatabaseConfig(),
 		parserconfig.DefaultParsingConfig(),
 		loggingconfig.DefaultLoggingConfig(),
+		apiconfig.DefaultAPIConfig(),
+		nil,
+		nil,
 	)
 }
 
@@ -54,18 +79,68 @@ func (c Config) GetBytes() ([]byte, error) {
 	return c.bytes, nil
 }
 
+// GetChains returns the configuration of all the chains that should be indexed
+func (c Config) GetChains() []IndexedChainConfig {
+	if len(c.Chains) == 0 {
+		return []IndexedChainConfig{NewIndexedChainConfig(c.Chain, c.Node, c.Parser)}
+	}
+	return c.Chains
+}
+
+// Validate checks that the configuration of the indexed chains is valid
+func (c Config) Validate() error {
+	if len(c.Chains) == 0 {
+		return nil
+	}
+
+	chainIDs := map[string]bool{}
+	for _, chain := range c.Chains {
+		if chain.ChainID == "" {
+			return fmt.Errorf("the chain_id of each chain must be set when indexing multiple chains")
+		}
+
+		if chainIDs[chain.ChainID] {
+			return fmt.Errorf("chain %s is configured more than once", chain.ChainID)
+		}
+		chainIDs[chain.ChainID] = true
+	}
+
+	return nil
+}
+
 type ChainConfig struct {
+	// ChainID is the id of the chain, used to tell its data apart from the ones of the other chains.
+	// When indexing a single chain it can be left empty, and the chain id of the node is used instead
+	ChainID      string `yaml:"chain_id,omitempty"`
 	Bech32Prefix string `yaml:"bech32_prefix"`
 }
 
 // NewChainConfig returns a new ChainConfig instance
-func NewChainConfig(bech32Prefix string) ChainConfig {
+func NewChainConfig(chainID string, bech32Prefix string) ChainConfig {
 	return ChainConfig{
+		ChainID:      chainID,
 		Bech32Prefix: bech32Prefix,
 	}
 }
 
 // DefaultChainConfig returns the default instance of ChainConfig
 func DefaultChainConfig() ChainConfig {
-	return NewChainConfig("archway")
+	return NewChainConfig("", "archway")
+}
+
+// IndexedChainConfig contains the configuration of a single chain that is indexed
+type IndexedChainConfig struct {
+	ChainConfig `yaml:",inline"`
+
+	Node   nodeconfig.Config   `yaml:"node"`
+	Parser parserconfig.Config `yaml:"parsing"`
+}
+
+// NewIndexedChainConfig returns a new IndexedChainConfig instance
+func NewIndexedChainConfig(chainCfg ChainConfig, nodeCfg nodeconfig.Config, parserCfg parserconfig.Config) IndexedChainConfig {
+	return IndexedChainConfig{
+		ChainConfig: chainCfg,
+		Node:        nodeCfg,
+		Parser:      parserCfg,
+	}
 }
@@ -12,11 +12,11 @@ import (
 
 // Block contains the data of a single chain block
 type Block struct {
-	Height    int64
-	Hash      string
-	TxNum     int
-	TotalGas  uint64
-	Timestamp time.Time
+	Height    int64     `json:"height"`
+	Hash      string    `json:"hash"`
+	TxNum     int       `json:"num_txs"`
+	TotalGas  uint64    `json:"total_gas"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // NewBlock allows to build a new Block instance
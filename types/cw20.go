@@ -0,0 +1,103 @@
+package types
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// Cw20ActionInstantiate identifies the transfers assigning the initial balances of a token
+	Cw20ActionInstantiate = "instantiate"
+
+	Cw20ActionTransfer     = "transfer"
+	Cw20ActionSend         = "send"
+	Cw20ActionMint         = "mint"
+	Cw20ActionBurn         = "burn"
+	Cw20ActionTransferFrom = "transfer_from"
+	Cw20ActionSendFrom     = "send_from"
+	Cw20ActionBurnFrom     = "burn_from"
+)
+
+// Cw20Token represents a contract that has been detected to be a CW20 token
+type Cw20Token struct {
+	ContractAddress string    `json:"contract_address"`
+	Name            string    `json:"name"`
+	Symbol          string    `json:"symbol"`
+	Decimals        uint32    `json:"decimals"`
+	Minter          string    `json:"minter"`
+	TxHash          string    `json:"tx_hash"`
+	SavedAt         time.Time `json:"saved_at"`
+	Height          int64     `json:"height"`
+}
+
+// NewCw20Token allows to build a new Cw20Token instance
+func NewCw20Token(
+	contractAddress string,
+	name string,
+	symbol string,
+	decimals uint32,
+	minter string,
+	txHash string,
+	savedAt time.Time,
+	height int64,
+) Cw20Token {
+	return Cw20Token{
+		ContractAddress: contractAddress,
+		Name:            name,
+		Symbol:          symbol,
+		Decimals:        decimals,
+		Minter:          minter,
+		TxHash:          txHash,
+		SavedAt:         savedAt,
+		Height:          height,
+	}
+}
+
+// Cw20Transfer represents a movement of CW20 tokens.
+// From is empty when the tokens are minted, and To is empty when they are burnt, while By contains the
+// spender of the actions using an allowance.
+// Each transfer is identified by the index of the message of its tx, and by its own index among the
+// transfers of that message
+type Cw20Transfer struct {
+	ContractAddress string    `json:"contract_address"`
+	Action          string    `json:"action"`
+	From            string    `json:"from"`
+	To              string    `json:"to"`
+	By              string    `json:"by"`
+	Amount          sdk.Int   `json:"amount"`
+	TxHash          string    `json:"tx_hash"`
+	MsgIndex        int       `json:"msg_index"`
+	EventIndex      int       `json:"event_index"`
+	ExecutedAt      time.Time `json:"executed_at"`
+	Height          int64     `json:"height"`
+}
+
+// NewCw20Transfer allows to build a new Cw20Transfer instance
+func NewCw20Transfer(
+	contractAddress string,
+	action string,
+	from string,
+	to string,
+	by string,
+	amount sdk.Int,
+	txHash string,
+	msgIndex int,
+	eventIndex int,
+	executedAt time.Time,
+	height int64,
+) Cw20Transfer {
+	return Cw20Transfer{
+		ContractAddress: contractAddress,
+		Action:          action,
+		From:            from,
+		To:              to,
+		By:              by,
+		Amount:          amount,
+		TxHash:          txHash,
+		MsgIndex:        msgIndex,
+		EventIndex:      eventIndex,
+		ExecutedAt:      executedAt,
+		Height:          height,
+	}
+}
@@ -9,12 +9,12 @@ import (
 
 // GasTrackerContractMetadata represents the Gastracker contract Metadata
 type GasTrackerContractMetadata struct {
-	Sender          string
-	ContractAddress string
-	Metadata        gastrackertypes.ContractInstanceMetadata
-	TxHash          string
-	SavedAt         time.Time
-	Height          int64
+	Sender          string                                   `json:"sender"`
+	ContractAddress string                                   `json:"contract_address"`
+	Metadata        gastrackertypes.ContractInstanceMetadata `json:"metadata"`
+	TxHash          string                                   `json:"tx_hash"`
+	SavedAt         time.Time                                `json:"saved_at"`
+	Height          int64                                    `json:"height"`
 }
 
 // NewGasTrackerContractMetadata allows to easily create a new GasTrackerContractMetadata
@@ -36,20 +36,20 @@ func NewGasTrackerContractMetadata(
 
 // ContractRewardCalculation represents the Gastracker reward calculation data
 type ContractRewardCalculation struct {
-	ContractAddress  string
-	RewardAddress    string
-	DeveloperAddress string
+	ContractAddress  string `json:"contract_address"`
+	RewardAddress    string `json:"reward_address"`
+	DeveloperAddress string `json:"developer_address"`
 
-	GasConsumed      uint64
-	ContractRewards  sdk.DecCoin
-	InflationRewards sdk.DecCoin
+	GasConsumed      uint64      `json:"gas_consumed"`
+	ContractRewards  sdk.DecCoin `json:"contract_rewards"`
+	InflationRewards sdk.DecCoin `json:"inflation_rewards"`
 
-	GasRebateToUser          bool
-	CollectPremium           bool
-	PremiumPercentageCharged uint64
+	GasRebateToUser          bool   `json:"gas_rebate_to_user"`
+	CollectPremium           bool   `json:"collect_premium"`
+	PremiumPercentageCharged uint64 `json:"premium_percentage_charged"`
 
-	RewardDate time.Time
-	Height     int64
+	RewardDate time.Time `json:"reward_date"`
+	Height     int64     `json:"height"`
 }
 
 // NewContractRewardCalculation allows to easily create a new ContractRewardCalculation
@@ -83,10 +83,10 @@ func NewContractRewardCalculation(
 
 // ContractRewardDistribution represents the Gastracker reward distribution data
 type ContractRewardDistribution struct {
-	RewardAddress      string
-	DistributedRewards sdk.Coin
-	LeftoverRewards    sdk.DecCoin
-	Height             int64
+	RewardAddress      string      `json:"reward_address"`
+	DistributedRewards sdk.Coin    `json:"distributed_rewards"`
+	LeftoverRewards    sdk.DecCoin `json:"leftover_rewards"`
+	Height             int64       `json:"height"`
 }
 
 // NewContractRewardDistribution allows to easily create a new ContractRewardDistribution
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"time"
+)
+
+// NotificationDelivery represents a single attempt of delivering a webhook notification
+type NotificationDelivery struct {
+	DeliveryID  string
+	Rule        string
+	Webhook     string
+	Payload     []byte
+	Height      int64
+	Attempt     int
+	StatusCode  int
+	Error       string
+	Success     bool
+	AttemptedAt time.Time
+}
+
+// NewNotificationDelivery allows to build a new NotificationDelivery instance
+func NewNotificationDelivery(
+	deliveryID string,
+	rule string,
+	webhook string,
+	payload []byte,
+	height int64,
+	attempt int,
+	statusCode int,
+	deliveryErr string,
+	success bool,
+	attemptedAt time.Time,
+) NotificationDelivery {
+	return NotificationDelivery{
+		DeliveryID:  deliveryID,
+		Rule:        rule,
+		Webhook:     webhook,
+		Payload:     payload,
+		Height:      height,
+		Attempt:     attempt,
+		StatusCode:  statusCode,
+		Error:       deliveryErr,
+		Success:     success,
+		AttemptedAt: attemptedAt,
+	}
+}
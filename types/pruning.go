@@ -0,0 +1,31 @@
+package types
+
+import (
+	"time"
+)
+
+// Pruning contains the details of the rows removed from a table of a chain since they exceeded its retention.
+// All the rows having a height lower or equal to CutoffHeight, or older than CutoffTime, have been removed.
+// Each cutoff is not set when the retention does not limit it
+type Pruning struct {
+	ChainID      string
+	Table        string
+	Rows         int64
+	CutoffHeight int64
+	CutoffTime   time.Time
+	PrunedAt     time.Time
+}
+
+// NewPruning allows to build a new Pruning instance
+func NewPruning(
+	chainID string, table string, rows int64, cutoffHeight int64, cutoffTime time.Time, prunedAt time.Time,
+) Pruning {
+	return Pruning{
+		ChainID:      chainID,
+		Table:        table,
+		Rows:         rows,
+		CutoffHeight: cutoffHeight,
+		CutoffTime:   cutoffTime,
+		PrunedAt:     prunedAt,
+	}
+}
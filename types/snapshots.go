@@ -0,0 +1,39 @@
+package types
+
+import (
+	"time"
+)
+
+const (
+	// SnapshotTypeSmart identifies the snapshots containing the response to a smart query
+	SnapshotTypeSmart = "smart"
+
+	// SnapshotTypeRaw identifies the snapshots containing all the raw key-value pairs of a contract
+	SnapshotTypeRaw = "raw"
+)
+
+// ContractStateSnapshot contains the state of a contract at a given height.
+// Query contains the JSON query message of the smart snapshots, and is empty for the raw ones,
+// while Result contains the JSON response of the contract or its raw key-value pairs
+type ContractStateSnapshot struct {
+	ContractAddress string
+	Type            string
+	Query           []byte
+	Result          []byte
+	Height          int64
+	SnapshotAt      time.Time
+}
+
+// NewContractStateSnapshot allows to build a new ContractStateSnapshot instance
+func NewContractStateSnapshot(
+	contractAddress string, snapshotType string, query []byte, result []byte, height int64, snapshotAt time.Time,
+) ContractStateSnapshot {
+	return ContractStateSnapshot{
+		ContractAddress: contractAddress,
+		Type:            snapshotType,
+		Query:           query,
+		Result:          result,
+		Height:          height,
+		SnapshotAt:      snapshotAt,
+	}
+}
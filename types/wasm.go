@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"time"
 
 	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
@@ -9,13 +10,13 @@ import (
 
 // WasmCode represents the CosmWasm code in x/wasm module
 type WasmCode struct {
-	Creator  string
-	CodeHash string
-	CodeID   uint64
-	Size     int
-	TxHash   string
-	SavedAt  time.Time
-	Height   int64
+	Creator  string    `json:"creator"`
+	CodeHash string    `json:"code_hash"`
+	CodeID   uint64    `json:"code_id"`
+	Size     int       `json:"size"`
+	TxHash   string    `json:"tx_hash"`
+	SavedAt  time.Time `json:"saved_at"`
+	Height   int64     `json:"height"`
 }
 
 // NewWasmCode allows to build a new x/wasm code instance from wasmtypes.MsgStoreCode
@@ -27,7 +28,7 @@ func NewWasmCode(
 	txHash string,
 	savedAt time.Time,
 	txHeight int64,
-	) WasmCode {
+) WasmCode {
 	return WasmCode{
 		Creator:  creator,
 		CodeHash: codeHash,
@@ -41,17 +42,17 @@ func NewWasmCode(
 
 // WasmContract represents the CosmWasm contract in x/wasm module
 type WasmContract struct {
-	Sender          string
-	Creator         string
-	Admin           string
-	CodeID          uint64
-	Label           string
-	RawContractMsg  []byte
-	Funds           sdk.Coins
-	ContractAddress string
-	TxHash          string
-	InstantiatedAt  time.Time
-	Height          int64
+	Sender          string          `json:"sender"`
+	Creator         string          `json:"creator"`
+	Admin           string          `json:"admin"`
+	CodeID          uint64          `json:"code_id"`
+	Label           string          `json:"label"`
+	RawContractMsg  json.RawMessage `json:"raw_contract_message"`
+	Funds           sdk.Coins       `json:"funds"`
+	ContractAddress string          `json:"contract_address"`
+	TxHash          string          `json:"tx_hash"`
+	InstantiatedAt  time.Time       `json:"instantiated_at"`
+	Height          int64           `json:"height"`
 }
 
 // NewWasmCode allows to build a new x/wasm contract instance from wasmtypes.MsgStoreCode
@@ -82,15 +83,15 @@ func NewWasmContract(
 
 // WasmExecuteContract represents the CosmWasm execute contract in x/wasm module
 type WasmExecuteContract struct {
-	Sender          string
-	ContractAddress string
-	RawContractMsg  []byte
-	Funds           sdk.Coins
-	GasUsed         int64
-	Fees            sdk.Coins
-	TxHash          string
-	ExecutedAt      time.Time
-	Height          int64
+	Sender          string          `json:"sender"`
+	ContractAddress string          `json:"contract_address"`
+	RawContractMsg  json.RawMessage `json:"raw_contract_message"`
+	Funds           sdk.Coins       `json:"funds"`
+	GasUsed         int64           `json:"gas_used"`
+	Fees            sdk.Coins       `json:"fees"`
+	TxHash          string          `json:"tx_hash"`
+	ExecutedAt      time.Time       `json:"executed_at"`
+	Height          int64           `json:"height"`
 }
 
 // NewWasmExecuteContract allows to build a new x/wasm execute contract instance